@@ -0,0 +1,487 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/auth"
+	"github.com/Vikuuu/synlabs-assignment/internal/config"
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+	"github.com/Vikuuu/synlabs-assignment/internal/oidc"
+)
+
+type command func(ctx context.Context, cfg config.Config, args []string, out io.Writer) error
+
+// commands are the subcommands of the binary. Anything else starts the
+// server.
+var commands = map[string]command{
+	"migrate": runMigrate,
+	"user":    runUser,
+	"job":     runJob,
+	"resume":  runResume,
+	"export":  runExport,
+	"seed":    runSeed,
+}
+
+const userUsage = `usage: synlabs-assignment user <command>
+
+commands:
+  create -email e -name n -password p [-type applicant|admin] [-headline h] [-address a]
+  promote <email>         make the user an admin
+  demote <email>          make the user an applicant
+  reset-password [-password p] <email>
+                          set a new password, generating one when -password is empty
+`
+
+const jobUsage = `usage: synlabs-assignment job <command>
+
+commands:
+  list                    list all jobs
+  close <job_id>          stop accepting applications for a job
+`
+
+const resumeUsage = `usage: synlabs-assignment resume reparse <applicant_id>
+`
+
+// openAdmin connects to the database for an admin command.
+func openAdmin(cfg config.Config) (*apiConfig, func(), error) {
+	if err := cfg.DBURL.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("db_url: %w", err)
+	}
+	pool, err := openPool(context.Background(), cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newAPIConfig(cfg, pool), pool.Close, nil
+}
+
+// setUserType promotes or demotes a user. Admins never had an applicant
+// profile, so one is created the first time they are demoted.
+func (cfg *apiConfig) setUserType(ctx context.Context, email string, userType database.UserType) (database.SetUserTypeRow, error) {
+	var dat database.SetUserTypeRow
+	err := cfg.db.WithTx(ctx, func(q database.Querier) error {
+		var err error
+		dat, err = q.SetUserType(ctx, database.SetUserTypeParams{Email: email, UserType: userType})
+		if err != nil {
+			return err
+		}
+		if userType != database.UserTypeApplicant {
+			return nil
+		}
+		hasProfile, err := q.HasProfile(ctx, dat.ID)
+		if err != nil {
+			return fmt.Errorf("checking applicant profile: %w", err)
+		}
+		if hasProfile {
+			return nil
+		}
+		return addApplicantProfile(ctx, q, dat.ID)
+	})
+	return dat, err
+}
+
+// checkPayload runs a payload's validation rules and reports violations
+// as a single line.
+func checkPayload(p payload) error {
+	v := &validator{}
+	p.validate(v)
+	if len(v.errs) == 0 {
+		return nil
+	}
+	msgs := []string{}
+	for _, fe := range v.errs {
+		msgs = append(msgs, fe.Field+": "+fe.Message)
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
+
+func runUser(ctx context.Context, cfg config.Config, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(out, userUsage)
+		return errors.New("missing user command")
+	}
+	fs := flag.NewFlagSet("user "+args[0], flag.ContinueOnError)
+	fs.SetOutput(out)
+
+	switch args[0] {
+	case "create":
+		p := signupPayload{}
+		fs.StringVar(&p.Email, "email", "", "email address")
+		fs.StringVar(&p.Name, "name", "", "full name")
+		fs.StringVar(&p.Password, "password", "", "password")
+		fs.StringVar((*string)(&p.UserType), "type", "applicant", "applicant or admin")
+		fs.StringVar(&p.ProfileHeadline, "headline", "", "profile headline")
+		fs.StringVar(&p.Address, "address", "", "postal address")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if err := checkPayload(&p); err != nil {
+			return err
+		}
+
+		api, closeDB, err := openAdmin(cfg)
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		hash, err := auth.HashPassword(p.Password)
+		if err != nil {
+			return err
+		}
+		dat, err := api.createUser(ctx, database.CreateUserParams{
+			Name:            p.Name,
+			Email:           p.Email,
+			Address:         p.Address,
+			UserType:        p.UserType,
+			PasswordHash:    hash,
+			ProfileHeadline: p.ProfileHeadline,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "created %s %d <%s>\n", dat.UserType, dat.ID, dat.Email)
+		return nil
+
+	case "promote", "demote":
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return fmt.Errorf("user %s needs exactly one email", args[0])
+		}
+		userType := database.UserTypeAdmin
+		if args[0] == "demote" {
+			userType = database.UserTypeApplicant
+		}
+
+		api, closeDB, err := openAdmin(cfg)
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		dat, err := api.setUserType(ctx, normalizeEmail(fs.Arg(0)), userType)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("no user with email %s", fs.Arg(0))
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "user %d is now %s\n", dat.ID, dat.UserType)
+		return nil
+
+	case "reset-password":
+		password := fs.String("password", "", "new password, generated when empty")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return errors.New("user reset-password needs exactly one email")
+		}
+
+		generated := *password == ""
+		if generated {
+			var err error
+			if *password, err = oidc.RandomString(); err != nil {
+				return err
+			}
+		} else {
+			v := &validator{}
+			v.password("password", *password)
+			if len(v.errs) > 0 {
+				return errors.New(v.errs[0].Message)
+			}
+		}
+
+		api, closeDB, err := openAdmin(cfg)
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		hash, err := auth.HashPassword(*password)
+		if err != nil {
+			return err
+		}
+		n, err := api.db.UpdatePasswordHash(ctx, database.UpdatePasswordHashParams{
+			Email:        normalizeEmail(fs.Arg(0)),
+			PasswordHash: hash,
+		})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return fmt.Errorf("no user with email %s", fs.Arg(0))
+		}
+		if generated {
+			fmt.Fprintf(out, "new password: %s\n", *password)
+		} else {
+			fmt.Fprintln(out, "password updated")
+		}
+		return nil
+
+	default:
+		fmt.Fprint(out, userUsage)
+		return fmt.Errorf("unknown user command %q", args[0])
+	}
+}
+
+func runJob(ctx context.Context, cfg config.Config, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(out, jobUsage)
+		return errors.New("missing job command")
+	}
+
+	switch args[0] {
+	case "list":
+		api, closeDB, err := openAdmin(cfg)
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		jobs, err := api.db.ListJobs(ctx)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tTITLE\tCOMPANY\tPOSTED\tAPPLICATIONS\tSTATUS")
+		for _, j := range jobs {
+			status := "open"
+			if j.ClosedAt.Valid {
+				status = "closed " + j.ClosedAt.Time.Format(time.DateOnly)
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%d\t%s\n",
+				j.ID, j.Title, j.CompanyName, j.PostedOn.Format(time.DateOnly),
+				j.TotalApplications.Int32, status)
+		}
+		return tw.Flush()
+
+	case "close":
+		if len(args) != 2 {
+			return errors.New("job close needs exactly one job ID")
+		}
+		jobID, err := strconv.Atoi(args[1])
+		if err != nil {
+			return errors.New("job ID must be an integer")
+		}
+
+		api, closeDB, err := openAdmin(cfg)
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		if _, err := api.closeJob(ctx, int32(jobID), nil); err != nil {
+			apiErr := &apiError{}
+			if errors.As(err, &apiErr) {
+				return fmt.Errorf("job %d: %s", jobID, apiErr.Msg)
+			}
+			return err
+		}
+		fmt.Fprintf(out, "job %d closed\n", jobID)
+		return nil
+
+	default:
+		fmt.Fprint(out, jobUsage)
+		return fmt.Errorf("unknown job command %q", args[0])
+	}
+}
+
+// runResume re-sends an applicant's stored resume to the parser, e.g.
+// after the parser improved or a previous call failed.
+func runResume(ctx context.Context, cfg config.Config, args []string, out io.Writer) error {
+	if len(args) != 2 || args[0] != "reparse" {
+		fmt.Fprint(out, resumeUsage)
+		return errors.New("expected: resume reparse <applicant_id>")
+	}
+	applicantID, err := strconv.Atoi(args[1])
+	if err != nil {
+		return errors.New("applicant ID must be an integer")
+	}
+	if cfg.ResumeParser.APIKey == "" {
+		return errors.New("resume_parser.api_key is required")
+	}
+
+	api, closeDB, err := openAdmin(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	file, err := api.db.GetResumeFileAddress(ctx, int32(applicantID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("applicant %d has no profile", applicantID)
+	}
+	if err != nil {
+		return err
+	}
+	if !file.Valid || file.String == "" {
+		return fmt.Errorf("applicant %d has not uploaded a resume", applicantID)
+	}
+
+	dat, err := api.parseResume(ctx, int32(applicantID), file.String)
+	if err != nil {
+		apiErr := &apiError{}
+		if errors.As(err, &apiErr) && apiErr.Err != nil {
+			return fmt.Errorf("%s: %w", apiErr.Msg, apiErr.Err)
+		}
+		return err
+	}
+	fmt.Fprintf(out, "reparsed %s: skills %q, education %q\n", file.String, dat.Skills.String, dat.Education.String)
+	return nil
+}
+
+type export struct {
+	ExportedAt   time.Time           `json:"exported_at"`
+	Users        []exportUser        `json:"users"`
+	Profiles     []exportProfile     `json:"profiles"`
+	Jobs         []exportJob         `json:"jobs"`
+	Applications []exportApplication `json:"applications"`
+}
+
+type exportUser struct {
+	ID              int32             `json:"id"`
+	Name            string            `json:"name"`
+	Email           string            `json:"email"`
+	Address         string            `json:"address"`
+	UserType        database.UserType `json:"user_type"`
+	ProfileHeadline string            `json:"profile_headline"`
+}
+
+type exportProfile struct {
+	Applicant int32  `json:"applicant"`
+	Resume    string `json:"resume_file_address"`
+	Skills    string `json:"skills"`
+	Education string `json:"education"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	Phone     string `json:"phone"`
+}
+
+type exportJob struct {
+	ID                int32      `json:"id"`
+	Title             string     `json:"title"`
+	Description       string     `json:"description"`
+	PostedOn          time.Time  `json:"posted_on"`
+	TotalApplications int32      `json:"total_applications"`
+	CompanyName       string     `json:"company_name"`
+	PostedBy          int32      `json:"posted_by"`
+	ClosedAt          *time.Time `json:"closed_at,omitempty"`
+}
+
+type exportApplication struct {
+	ID          int32                      `json:"id"`
+	ApplicantID int32                      `json:"applicant_id"`
+	JobID       int32                      `json:"job_id"`
+	Status      database.ApplicationStatus `json:"status"`
+	AppliedAt   time.Time                  `json:"applied_at"`
+}
+
+// runExport writes every user, profile, job and application as one JSON
+// document. Password hashes are never exported.
+func runExport(ctx context.Context, cfg config.Config, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(out)
+	file := fs.String("o", "", "write to this file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	api, closeDB, err := openAdmin(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	doc := export{
+		ExportedAt:   time.Now().UTC(),
+		Users:        []exportUser{},
+		Profiles:     []exportProfile{},
+		Jobs:         []exportJob{},
+		Applications: []exportApplication{},
+	}
+
+	users, err := api.db.ExportUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("exporting users: %w", err)
+	}
+	for _, u := range users {
+		doc.Users = append(doc.Users, exportUser(u))
+	}
+
+	profiles, err := api.db.ExportProfiles(ctx)
+	if err != nil {
+		return fmt.Errorf("exporting profiles: %w", err)
+	}
+	for _, p := range profiles {
+		doc.Profiles = append(doc.Profiles, exportProfile{
+			Applicant: p.Applicant,
+			Resume:    p.ResumeFileAddress.String,
+			Skills:    p.Skills.String,
+			Education: p.Education.String,
+			Name:      p.Name.String,
+			Email:     p.Email.String,
+			Phone:     p.Phone.String,
+		})
+	}
+
+	jobs, err := api.db.ExportJobs(ctx)
+	if err != nil {
+		return fmt.Errorf("exporting jobs: %w", err)
+	}
+	for _, j := range jobs {
+		ej := exportJob{
+			ID:                j.ID,
+			Title:             j.Title,
+			Description:       j.Description,
+			PostedOn:          j.PostedOn,
+			TotalApplications: j.TotalApplications.Int32,
+			CompanyName:       j.CompanyName,
+			PostedBy:          j.PostedBy,
+		}
+		if j.ClosedAt.Valid {
+			ej.ClosedAt = &j.ClosedAt.Time
+		}
+		doc.Jobs = append(doc.Jobs, ej)
+	}
+
+	apps, err := api.db.ExportApplications(ctx)
+	if err != nil {
+		return fmt.Errorf("exporting applications: %w", err)
+	}
+	for _, a := range apps {
+		doc.Applications = append(doc.Applications, exportApplication{
+			ID:          a.ID,
+			ApplicantID: a.ApplicantID.Int32,
+			JobID:       a.JobID.Int32,
+			Status:      a.Status,
+			AppliedAt:   a.AppliedAt,
+		})
+	}
+
+	w := out
+	if *file != "" {
+		f, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/config"
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+)
+
+// These cases must fail before a database connection is needed.
+func TestAdminCommandsRejectBadInput(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"user"}, "missing user command"},
+		{[]string{"user", "create", "-email", "not-an-email", "-name", "A", "-password", "abcdefg1"}, "email:"},
+		{[]string{"user", "create", "-email", "a@example.com", "-name", "A", "-password", "short"}, "password:"},
+		{[]string{"user", "promote"}, "exactly one email"},
+		{[]string{"user", "reset-password", "-password", "nodigits", "a@example.com"}, "letter and one digit"},
+		{[]string{"user", "frobnicate"}, "unknown user command"},
+		{[]string{"job", "close", "seven"}, "must be an integer"},
+		{[]string{"resume", "reparse"}, "resume reparse <applicant_id>"},
+		{[]string{"job", "list"}, "db_url: is required"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			out := &bytes.Buffer{}
+			err := commands[tt.args[0]](context.Background(), config.Default(), tt.args[1:], out)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDemotedAdminGetsApplicantProfile(t *testing.T) {
+	parser := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"Dee","email":"dee@example.com","phone":"555","skills":["Go"]}`)
+	}))
+	t.Cleanup(parser.Close)
+	api := newTestAPI(t, func(cfg *apiConfig) {
+		cfg.resumeParser = config.ResumeParser{URL: parser.URL, APIKey: "parser-key", Timeout: 5 * time.Second}
+	})
+	admin := api.signup("admin@example.com", "admin")
+	api.signup("dee@example.com", "admin")
+	ctx := context.Background()
+
+	dat, err := api.cfg.setUserType(ctx, "dee@example.com", database.UserTypeApplicant)
+	if err != nil || dat.UserType != database.UserTypeApplicant {
+		t.Fatalf("demoted = %+v, err = %v", dat, err)
+	}
+	// Promoting and demoting again keeps the profile.
+	if _, err := api.cfg.setUserType(ctx, "dee@example.com", database.UserTypeAdmin); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.cfg.setUserType(ctx, "dee@example.com", database.UserTypeApplicant); err != nil {
+		t.Fatal(err)
+	}
+
+	login := loginResponse{}
+	api.expect(api.do("POST", "/v1/login", `{"email":"dee@example.com","password":"secret123"}`, ""), http.StatusOK, &login)
+	resume := filepath.Join(t.TempDir(), "resume.pdf")
+	os.WriteFile(resume, []byte("%PDF-1.4"), 0o600)
+	api.expect(api.do("POST", "/v1/uploadResume", fmt.Sprintf(`{"file_address":%q}`, resume), login.AccessToken), http.StatusOK, nil)
+
+	profile := applicantResponse{}
+	api.expect(api.do("GET", fmt.Sprintf("/v1/admin/applicant/%d", dat.ID), "", admin), http.StatusOK, &profile)
+	if profile.Resume != resume || profile.Skills != "Go" {
+		t.Errorf("profile = %+v", profile)
+	}
+}
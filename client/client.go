@@ -0,0 +1,771 @@
+// Package client is a typed Go client for version 1 of the Synlabs jobs
+// API. Operational routes such as the health probes and /metrics are not
+// covered.
+//
+// The API has no refresh tokens. A client that logged in with Login
+// keeps the credentials and logs in again when its access token is about
+// to expire or is rejected.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRetries    = 3
+	defaultMinBackoff = 200 * time.Millisecond
+	maxBackoff        = 5 * time.Second
+	// refreshMargin is how long before expiry a token is replaced.
+	refreshMargin = 30 * time.Second
+)
+
+type Client struct {
+	baseURL    string
+	http       *http.Client
+	retries    int
+	minBackoff time.Duration
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+	creds   *LoginRequest
+	// refreshing serializes logins so concurrent requests rejected
+	// with the same token refresh it once.
+	refreshing sync.Mutex
+}
+
+type Option func(*Client)
+
+// WithHTTPClient sets the client used for requests. It defaults to one
+// with a 30 second timeout.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// WithRetries sets how many times a request failing with a 5xx status
+// or a transport error is retried, waiting an exponentially growing,
+// jittered delay starting at minBackoff in between. Zero disables
+// retries.
+func WithRetries(retries int, minBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.minBackoff = minBackoff
+	}
+}
+
+// WithToken sets an access token obtained elsewhere, for example through
+// single sign-on. Such a token is not refreshed.
+func WithToken(token string) Option {
+	return func(c *Client) { c.setToken(token) }
+}
+
+// WithCredentials makes the client log in on its first authenticated
+// request and whenever the token needs refreshing.
+func WithCredentials(email, password string) Option {
+	return func(c *Client) { c.creds = &LoginRequest{Email: email, Password: password} }
+}
+
+// New returns a client for the API served at baseURL, for example
+// "https://jobs.example.com/v1".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("base URL %q must be http or https", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		http:       &http.Client{Timeout: 30 * time.Second},
+		retries:    defaultRetries,
+		minBackoff: defaultMinBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Token returns the current access token, or "" before logging in.
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+func (c *Client) setToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+	c.expires = tokenExpiry(token)
+}
+
+// tokenExpiry reads the exp claim of a JWT without verifying it, which
+// is the server's job. It returns the zero time when there is none.
+func tokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+	claims := struct {
+		Exp int64 `json:"exp"`
+	}{}
+	if json.Unmarshal(data, &claims) != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
+
+// OIDCLoginURL is where a browser starts single sign-on. The identity
+// provider sends it back to the API, which answers with a LoginResponse.
+func (c *Client) OIDCLoginURL() string {
+	return c.baseURL + "/login/oidc"
+}
+
+func (c *Client) Signup(ctx context.Context, req SignupRequest) (*User, error) {
+	out := &User{}
+	if err := c.do(ctx, call{method: http.MethodPost, path: "/signup", body: req, out: out}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Login exchanges email and password for an access token, which the
+// client uses from then on. The credentials are kept to refresh it.
+func (c *Client) Login(ctx context.Context, email, password string) (*LoginResponse, error) {
+	creds := LoginRequest{Email: email, Password: password}
+	out, err := c.login(ctx, creds)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.creds = &creds
+	c.mu.Unlock()
+	return out, nil
+}
+
+func (c *Client) login(ctx context.Context, creds LoginRequest) (*LoginResponse, error) {
+	out := &LoginResponse{}
+	err := c.do(ctx, call{method: http.MethodPost, path: "/login", body: creds, out: out, idempotent: true})
+	if err != nil {
+		return nil, err
+	}
+	c.setToken(out.AccessToken)
+	return out, nil
+}
+
+// UploadResume parses the resume at fileAddress, a path on the server,
+// into the caller's profile.
+func (c *Client) UploadResume(ctx context.Context, fileAddress string) (*Resume, error) {
+	out := &Resume{}
+	if err := c.do(ctx, call{
+		method:     http.MethodPost,
+		path:       "/uploadResume",
+		body:       UploadResumeRequest{FileAddress: fileAddress},
+		out:        out,
+		auth:       true,
+		idempotent: true,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Jobs lists the open jobs.
+func (c *Client) Jobs(ctx context.Context) ([]JobListing, error) {
+	out := []JobListing{}
+	if err := c.do(ctx, call{method: http.MethodGet, path: "/jobs", out: &out, auth: true, idempotent: true}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Apply applies the caller to a job.
+func (c *Client) Apply(ctx context.Context, jobID int32) error {
+	return c.do(ctx, call{
+		method: http.MethodGet,
+		path:   "/jobs/apply",
+		query:  url.Values{"job_id": {strconv.Itoa(int(jobID))}},
+		out:    &ApplyResponse{},
+		auth:   true,
+	})
+}
+
+func (c *Client) CreateJob(ctx context.Context, req CreateJobRequest) (*CreateJobResponse, error) {
+	out := &CreateJobResponse{}
+	if err := c.do(ctx, call{method: http.MethodPost, path: "/admin/job", body: req, out: out, auth: true}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) Job(ctx context.Context, jobID int32) (*Job, error) {
+	out := &Job{}
+	if err := c.do(ctx, call{
+		method:     http.MethodGet,
+		path:       fmt.Sprintf("/admin/job/%d", jobID),
+		out:        out,
+		auth:       true,
+		idempotent: true,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) Applicants(ctx context.Context) ([]ApplicantSummary, error) {
+	out := []ApplicantSummary{}
+	if err := c.do(ctx, call{method: http.MethodGet, path: "/admin/applicants", out: &out, auth: true, idempotent: true}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) Applicant(ctx context.Context, applicantID int32) (*Applicant, error) {
+	out := &Applicant{}
+	if err := c.do(ctx, call{
+		method:     http.MethodGet,
+		path:       fmt.Sprintf("/admin/applicant/%d", applicantID),
+		out:        out,
+		auth:       true,
+		idempotent: true,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CloseJob stops a job from accepting applications. Only the admin who
+// posted the job may close it.
+func (c *Client) CloseJob(ctx context.Context, jobID int32) (*Job, error) {
+	out := &Job{}
+	if err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   fmt.Sprintf("/admin/job/%d/close", jobID),
+		out:    out,
+		auth:   true,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateApplicationStatus changes the status of an application to a job
+// the caller posted.
+func (c *Client) UpdateApplicationStatus(ctx context.Context, applicationID int32, status ApplicationStatus) (*Application, error) {
+	out := &Application{}
+	if err := c.do(ctx, call{
+		method:     http.MethodPatch,
+		path:       fmt.Sprintf("/admin/application/%d", applicationID),
+		body:       UpdateApplicationRequest{Status: status},
+		out:        out,
+		auth:       true,
+		idempotent: true,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) CreateWebhook(ctx context.Context, req CreateWebhookRequest) (*Webhook, error) {
+	out := &Webhook{}
+	if err := c.do(ctx, call{method: http.MethodPost, path: "/admin/webhooks", body: req, out: out, auth: true}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) Webhooks(ctx context.Context) ([]Webhook, error) {
+	out := []Webhook{}
+	if err := c.do(ctx, call{method: http.MethodGet, path: "/admin/webhooks", out: &out, auth: true, idempotent: true}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteWebhook removes a webhook along with its delivery log.
+func (c *Client) DeleteWebhook(ctx context.Context, webhookID int32) error {
+	return c.do(ctx, call{
+		method:     http.MethodDelete,
+		path:       fmt.Sprintf("/admin/webhooks/%d", webhookID),
+		auth:       true,
+		idempotent: true,
+	})
+}
+
+// WebhookDeliveries returns the latest deliveries of a webhook first.
+func (c *Client) WebhookDeliveries(ctx context.Context, webhookID int32) ([]Delivery, error) {
+	out := []Delivery{}
+	if err := c.do(ctx, call{
+		method:     http.MethodGet,
+		path:       fmt.Sprintf("/admin/webhooks/%d/deliveries", webhookID),
+		out:        &out,
+		auth:       true,
+		idempotent: true,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeadLetters returns the deliveries that ran out of attempts, latest
+// first.
+func (c *Client) DeadLetters(ctx context.Context) ([]Delivery, error) {
+	out := []Delivery{}
+	if err := c.do(ctx, call{method: http.MethodGet, path: "/admin/webhooks/dead-letters", out: &out, auth: true, idempotent: true}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RetryDelivery queues a dead delivery again.
+func (c *Client) RetryDelivery(ctx context.Context, deliveryID int64) error {
+	return c.do(ctx, call{
+		method: http.MethodPost,
+		path:   fmt.Sprintf("/admin/webhooks/deliveries/%d/retry", deliveryID),
+		auth:   true,
+	})
+}
+
+type call struct {
+	method string
+	path   string
+	query  url.Values
+	body   interface{}
+	out    interface{}
+	auth   bool
+	// idempotent calls are retried after any 5xx or transport error.
+	// Others only after 503, when the request was not handled.
+	idempotent bool
+}
+
+func (c *Client) do(ctx context.Context, cl call) error {
+	var body []byte
+	if cl.body != nil {
+		var err error
+		if body, err = json.Marshal(cl.body); err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+	}
+	u := c.baseURL + cl.path
+	if len(cl.query) > 0 {
+		u += "?" + cl.query.Encode()
+	}
+
+	refreshed := false
+	for attempt := 0; ; attempt++ {
+		token := ""
+		if cl.auth {
+			var err error
+			if token, err = c.validToken(ctx); err != nil {
+				return err
+			}
+		}
+
+		res, err := c.send(ctx, cl.method, u, body, token)
+		if err != nil {
+			if ctx.Err() != nil || !cl.idempotent || attempt >= c.retries {
+				return err
+			}
+			if err := c.wait(ctx, attempt, 0); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if res.StatusCode == http.StatusUnauthorized && cl.auth && !refreshed && c.canRefresh() {
+			discard(res)
+			refreshed = true
+			if err := c.refresh(ctx, token); err != nil {
+				return err
+			}
+			continue
+		}
+		if res.StatusCode >= 500 && attempt < c.retries &&
+			(cl.idempotent || res.StatusCode == http.StatusServiceUnavailable) {
+			retryAfter := parseRetryAfter(res.Header.Get("Retry-After"))
+			discard(res)
+			if err := c.wait(ctx, attempt, retryAfter); err != nil {
+				return err
+			}
+			continue
+		}
+
+		return decode(res, cl.out)
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, u string, body []byte, token string) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return c.http.Do(req)
+}
+
+// wait sleeps before retry number attempt+1: the server's Retry-After
+// when it gave one, otherwise minBackoff doubled per attempt with half
+// of it jittered.
+func (c *Client) wait(ctx context.Context, attempt int, retryAfter time.Duration) error {
+	d := min(c.minBackoff<<attempt, maxBackoff)
+	d = d/2 + rand.N(d/2+1)
+	d = max(d, retryAfter)
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func parseRetryAfter(v string) time.Duration {
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return min(time.Duration(secs)*time.Second, maxBackoff)
+	}
+	return 0
+}
+
+func (c *Client) canRefresh() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.creds != nil
+}
+
+// validToken returns the token for an authenticated request, logging in
+// first when there is none yet or it is about to expire.
+func (c *Client) validToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	token, expires, creds := c.token, c.expires, c.creds
+	c.mu.Unlock()
+
+	stale := token == "" || (!expires.IsZero() && time.Until(expires) < refreshMargin)
+	if !stale || creds == nil {
+		return token, nil
+	}
+	if err := c.refresh(ctx, token); err != nil {
+		return "", err
+	}
+	return c.Token(), nil
+}
+
+// refresh logs in again unless another request already replaced stale.
+func (c *Client) refresh(ctx context.Context, stale string) error {
+	c.refreshing.Lock()
+	defer c.refreshing.Unlock()
+
+	c.mu.Lock()
+	current, creds := c.token, c.creds
+	c.mu.Unlock()
+	if current != stale && current != "" {
+		return nil
+	}
+	if _, err := c.login(ctx, *creds); err != nil {
+		return fmt.Errorf("refreshing access token: %w", err)
+	}
+	return nil
+}
+
+func discard(res *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	res.Body.Close()
+}
+
+func decode(res *http.Response, out interface{}) error {
+	defer res.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(res.Body, 10<<20))
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+
+	if res.StatusCode >= 400 {
+		apiErr := &Error{}
+		mt, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+		if mt != "application/problem+json" || json.Unmarshal(data, apiErr) != nil {
+			apiErr = &Error{Title: http.StatusText(res.StatusCode)}
+		}
+		apiErr.Status = res.StatusCode
+		return apiErr
+	}
+	if out == nil {
+		return nil
+	}
+	// Bodies other than JSON, such as calendar invites, are kept as is.
+	if raw, ok := out.(*[]byte); ok {
+		*raw = data
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) NotificationPreferences(ctx context.Context) (*NotificationPreferences, error) {
+	out := &NotificationPreferences{}
+	if err := c.do(ctx, call{method: http.MethodGet, path: "/me/notification-preferences", out: out, auth: true, idempotent: true}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SetNotificationPreferences replaces every preference of the user.
+func (c *Client) SetNotificationPreferences(ctx context.Context, prefs NotificationPreferences) (*NotificationPreferences, error) {
+	out := &NotificationPreferences{}
+	if err := c.do(ctx, call{
+		method:     http.MethodPut,
+		path:       "/me/notification-preferences",
+		body:       prefs,
+		out:        out,
+		auth:       true,
+		idempotent: true,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Notifications returns up to limit notifications older than before,
+// or the newest when before is 0. A limit of 0 uses the server default.
+func (c *Client) Notifications(ctx context.Context, limit int, before int64) (*NotificationPage, error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if before > 0 {
+		query.Set("before", strconv.FormatInt(before, 10))
+	}
+	out := &NotificationPage{}
+	if err := c.do(ctx, call{
+		method:     http.MethodGet,
+		path:       "/me/notifications",
+		query:      query,
+		out:        out,
+		auth:       true,
+		idempotent: true,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) MarkNotificationsRead(ctx context.Context, req MarkNotificationsReadRequest) (*MarkNotificationsReadResult, error) {
+	out := &MarkNotificationsReadResult{}
+	if err := c.do(ctx, call{
+		method:     http.MethodPost,
+		path:       "/me/notifications/read",
+		body:       req,
+		out:        out,
+		auth:       true,
+		idempotent: true,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) ScheduleInterview(ctx context.Context, applicationID int32, req InterviewRequest) (*Interview, error) {
+	out := &Interview{}
+	if err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   fmt.Sprintf("/admin/application/%d/interviews", applicationID),
+		body:   req,
+		out:    out,
+		auth:   true,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) ApplicationInterviews(ctx context.Context, applicationID int32) ([]Interview, error) {
+	out := []Interview{}
+	if err := c.do(ctx, call{
+		method:     http.MethodGet,
+		path:       fmt.Sprintf("/admin/application/%d/interviews", applicationID),
+		out:        &out,
+		auth:       true,
+		idempotent: true,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RescheduleInterview replaces the slot, interviewers and place of an
+// interview, which the applicant then has to confirm again.
+func (c *Client) RescheduleInterview(ctx context.Context, interviewID int32, req InterviewRequest) (*Interview, error) {
+	out := &Interview{}
+	if err := c.do(ctx, call{
+		method:     http.MethodPut,
+		path:       fmt.Sprintf("/admin/interview/%d", interviewID),
+		body:       req,
+		out:        out,
+		auth:       true,
+		idempotent: true,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) CancelInterview(ctx context.Context, interviewID int32) (*Interview, error) {
+	out := &Interview{}
+	if err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   fmt.Sprintf("/admin/interview/%d/cancel", interviewID),
+		out:    out,
+		auth:   true,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// InterviewInvite returns the iCalendar invite to an interview. Admins
+// get any interview; applicants only their own.
+func (c *Client) InterviewInvite(ctx context.Context, interviewID int32, asApplicant bool) ([]byte, error) {
+	path := fmt.Sprintf("/admin/interview/%d/invite.ics", interviewID)
+	if asApplicant {
+		path = fmt.Sprintf("/me/interviews/%d/invite.ics", interviewID)
+	}
+	var out []byte
+	if err := c.do(ctx, call{method: http.MethodGet, path: path, out: &out, auth: true, idempotent: true}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MyInterviews returns the interviews of the applicant.
+func (c *Client) MyInterviews(ctx context.Context) ([]Interview, error) {
+	out := []Interview{}
+	if err := c.do(ctx, call{method: http.MethodGet, path: "/me/interviews", out: &out, auth: true, idempotent: true}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) ConfirmInterview(ctx context.Context, interviewID int32) (*Interview, error) {
+	out := &Interview{}
+	if err := c.do(ctx, call{
+		method:     http.MethodPost,
+		path:       fmt.Sprintf("/me/interviews/%d/confirm", interviewID),
+		out:        out,
+		auth:       true,
+		idempotent: true,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RequestReschedule asks the interviewers for another slot, saying why
+// in note.
+func (c *Client) RequestReschedule(ctx context.Context, interviewID int32, note string) (*Interview, error) {
+	out := &Interview{}
+	if err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   fmt.Sprintf("/me/interviews/%d/reschedule", interviewID),
+		body:   RescheduleRequest{Note: note},
+		out:    out,
+		auth:   true,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SetScorecard replaces the scorecard of a job.
+func (c *Client) SetScorecard(ctx context.Context, jobID int32, req ScorecardRequest) (*Scorecard, error) {
+	out := &Scorecard{}
+	if err := c.do(ctx, call{
+		method:     http.MethodPut,
+		path:       fmt.Sprintf("/admin/job/%d/scorecard", jobID),
+		body:       req,
+		out:        out,
+		auth:       true,
+		idempotent: true,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) Scorecard(ctx context.Context, jobID int32) (*Scorecard, error) {
+	out := &Scorecard{}
+	if err := c.do(ctx, call{
+		method:     http.MethodGet,
+		path:       fmt.Sprintf("/admin/job/%d/scorecard", jobID),
+		out:        out,
+		auth:       true,
+		idempotent: true,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SaveFeedback saves the caller's feedback on an application. It fails
+// with CodeForbidden unless the caller interviews for the application,
+// and with CodeFeedbackSubmitted once the feedback was submitted.
+func (c *Client) SaveFeedback(ctx context.Context, applicationID int32, req FeedbackRequest) (*Feedback, error) {
+	out := &Feedback{}
+	if err := c.do(ctx, call{
+		method: http.MethodPut,
+		path:   fmt.Sprintf("/admin/application/%d/feedback", applicationID),
+		body:   req,
+		out:    out,
+		auth:   true,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ApplicationFeedback returns the feedback on an application that the
+// caller may see.
+func (c *Client) ApplicationFeedback(ctx context.Context, applicationID int32) (*ApplicationFeedback, error) {
+	out := &ApplicationFeedback{}
+	if err := c.do(ctx, call{
+		method:     http.MethodGet,
+		path:       fmt.Sprintf("/admin/application/%d/feedback", applicationID),
+		out:        out,
+		auth:       true,
+		idempotent: true,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
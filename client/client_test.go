@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, h http.HandlerFunc, opts ...Option) *Client {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	c, err := New(srv.URL, append([]Option{WithRetries(2, time.Millisecond)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func fakeJWT(exp time.Time) string {
+	claims := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, exp.Unix())))
+	return "e30." + claims + ".sig"
+}
+
+func TestRetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `[{"title":"Go developer"}]`)
+	}, WithToken("token"))
+
+	jobs, err := c.Jobs(context.Background())
+	if err != nil || len(jobs) != 1 || calls.Load() != 3 {
+		t.Fatalf("jobs = %+v, err = %v after %d calls", jobs, err, calls.Load())
+	}
+}
+
+func TestDoesNotRetryUnsafeRequestsAfterServerError(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"title":"Internal server error","status":500,"code":"internal.error","request_id":"abc"}`)
+	}, WithToken("token"))
+
+	_, err := c.CreateJob(context.Background(), CreateJobRequest{Title: "Go developer"})
+	apiErr, ok := err.(*Error)
+	if !ok || apiErr.Code != CodeInternal || apiErr.RequestID != "abc" || calls.Load() != 1 {
+		t.Fatalf("err = %#v after %d calls", err, calls.Load())
+	}
+}
+
+func TestErrorWithoutProblemDocument(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusTooManyRequests)
+		io.WriteString(w, "<h1>slow down</h1>")
+	})
+
+	_, err := c.Signup(context.Background(), SignupRequest{})
+	apiErr, ok := err.(*Error)
+	if !ok || apiErr.Status != http.StatusTooManyRequests || apiErr.Title != "Too Many Requests" || CodeOf(err) != "" {
+		t.Fatalf("err = %#v", err)
+	}
+}
+
+func TestRefreshesToken(t *testing.T) {
+	var logins atomic.Int32
+	valid := fakeJWT(time.Now().Add(time.Hour))
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			logins.Add(1)
+			fmt.Fprintf(w, `{"access_token":%q,"user_type":"admin"}`, valid)
+		default:
+			if r.Header.Get("Authorization") != "Bearer "+valid {
+				w.Header().Set("Content-Type", "application/problem+json")
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"status":401,"code":"auth.unauthorized"}`)
+				return
+			}
+			fmt.Fprint(w, `[]`)
+		}
+	})
+	ctx := context.Background()
+
+	// Logs in lazily, and again for an expiring token or a rejected one.
+	for _, token := range []string{"", fakeJWT(time.Now().Add(time.Second)), "revoked"} {
+		c.setToken(token)
+		c.creds = &LoginRequest{Email: "ann@example.com", Password: "secret"}
+		before := logins.Load()
+		if _, err := c.Applicants(ctx); err != nil || logins.Load() != before+1 {
+			t.Errorf("token %q: err = %v after %d logins", token, err, logins.Load()-before)
+		}
+	}
+
+	before := logins.Load()
+	if _, err := c.Applicants(ctx); err != nil || logins.Load() != before {
+		t.Errorf("valid token refreshed: err = %v", err)
+	}
+
+	c.setToken("revoked")
+	c.creds = nil
+	if _, err := c.Applicants(ctx); CodeOf(err) != CodeUnauthorized {
+		t.Errorf("without credentials err = %v, want %s", err, CodeUnauthorized)
+	}
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Code is the stable, machine-readable identifier of an API error.
+type Code string
+
+const (
+	CodeMalformedRequest    Code = "request.malformed"
+	CodeValidationFailed    Code = "validation.failed"
+	CodeUnauthorized        Code = "auth.unauthorized"
+	CodeInvalidCredentials  Code = "auth.invalid_credentials"
+	CodeForbidden           Code = "auth.forbidden"
+	CodeLoginSession        Code = "auth.login_session_invalid"
+	CodeNotFound            Code = "route.not_found"
+	CodeMethodNotAllowed    Code = "route.method_not_allowed"
+	CodeEmailTaken          Code = "user.email_taken"
+	CodeJobNotFound         Code = "job.not_found"
+	CodeJobClosed           Code = "job.closed"
+	CodeApplicantNotFound   Code = "applicant.not_found"
+	CodeApplicationNotFound Code = "application.not_found"
+	CodeWebhookNotFound     Code = "webhook.not_found"
+	CodeDeliveryNotFound    Code = "webhook.delivery_not_found"
+	CodeInterviewNotFound   Code = "interview.not_found"
+	CodeInterviewConflict   Code = "interview.conflict"
+	CodeInterviewCancelled  Code = "interview.cancelled"
+	CodeScorecardNotFound   Code = "scorecard.not_found"
+	CodeFeedbackSubmitted   Code = "feedback.submitted"
+	CodeUpstreamFailed      Code = "upstream.unavailable"
+	CodeInternal            Code = "internal.error"
+)
+
+// FieldError describes one invalid field of a request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error is an error response from the API, decoded from its RFC 7807
+// problem document. Responses that are not problem documents, such as
+// those of a proxy in front of the API, only carry Status and Title.
+type Error struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      Code         `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("synlabs: %d", e.Status)
+	if e.Code != "" {
+		msg += " " + string(e.Code)
+	}
+	if e.Detail != "" {
+		return msg + ": " + e.Detail
+	}
+	if e.Title != "" {
+		return msg + ": " + e.Title
+	}
+	return msg
+}
+
+// CodeOf returns the code of the API error in err's chain, or "" when
+// err did not come from an API response.
+func CodeOf(err error) Code {
+	apiErr := &Error{}
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	return ""
+}
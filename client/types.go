@@ -0,0 +1,355 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type UserType string
+
+const (
+	UserTypeApplicant UserType = "applicant"
+	UserTypeAdmin     UserType = "admin"
+)
+
+type SignupRequest struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	// UserType defaults to applicant.
+	UserType        UserType `json:"user_type,omitempty"`
+	ProfileHeadline string   `json:"profile_headline,omitempty"`
+	Address         string   `json:"address,omitempty"`
+}
+
+type User struct {
+	Name     string   `json:"name"`
+	Email    string   `json:"email"`
+	UserType UserType `json:"user_type"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type LoginResponse struct {
+	AccessToken string   `json:"access_token"`
+	UserType    UserType `json:"user_type"`
+}
+
+type UploadResumeRequest struct {
+	// FileAddress is the path of a pdf or docx file readable by the
+	// server.
+	FileAddress string `json:"file_address"`
+}
+
+// Resume holds the details the parser extracted from a resume. Skills
+// and Education are comma separated.
+type Resume struct {
+	Name      string `json:"name"`
+	Phone     string `json:"phone"`
+	Email     string `json:"email"`
+	Skills    string `json:"skills"`
+	Education string `json:"education"`
+}
+
+// JobListing is a job as applicants see it.
+type JobListing struct {
+	Title             string    `json:"title"`
+	Description       string    `json:"description"`
+	PostedOn          time.Time `json:"posted_on"`
+	TotalApplications int32     `json:"total_application"`
+	CompanyName       string    `json:"company_name"`
+	PostedBy          int32     `json:"posted_by"`
+}
+
+type ApplyResponse struct {
+	Success bool `json:"success"`
+}
+
+type CreateJobRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	CompanyName string `json:"company_name"`
+}
+
+type CreateJobResponse struct {
+	ID          int32  `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	CompanyName string `json:"company_name"`
+}
+
+// Job is a job as admins see it.
+type Job struct {
+	Title             string     `json:"title"`
+	Description       string     `json:"description"`
+	PostedOn          time.Time  `json:"posted_on"`
+	CompanyName       string     `json:"company_name"`
+	PostedBy          int32      `json:"posted_by"`
+	TotalApplications *int32     `json:"total_applications"`
+	ClosedAt          *time.Time `json:"closed_at,omitempty"`
+}
+
+type ApplicantSummary struct {
+	Name            string `json:"name"`
+	Email           string `json:"email"`
+	Address         string `json:"address"`
+	ProfileHeadline string `json:"profile_headline"`
+}
+
+// Applicant is an applicant's profile including their parsed resume.
+type Applicant struct {
+	Name            string        `json:"name"`
+	Email           string        `json:"email"`
+	Address         string        `json:"address"`
+	ProfileHeadline string        `json:"profile_headline"`
+	Resume          string        `json:"resume"`
+	Skills          string        `json:"skills"`
+	Education       string        `json:"education"`
+	Phone           string        `json:"phone"`
+	Applications    []Application `json:"applications"`
+	// Scorecards summarizes the feedback on the applications that have
+	// any.
+	Scorecards []ScorecardSummary `json:"scorecards"`
+}
+
+type ApplicationStatus string
+
+const (
+	ApplicationStatusApplied      ApplicationStatus = "applied"
+	ApplicationStatusReviewing    ApplicationStatus = "reviewing"
+	ApplicationStatusInterviewing ApplicationStatus = "interviewing"
+	ApplicationStatusOffered      ApplicationStatus = "offered"
+	ApplicationStatusHired        ApplicationStatus = "hired"
+	ApplicationStatusRejected     ApplicationStatus = "rejected"
+)
+
+// Application is one job an applicant applied to.
+type Application struct {
+	ID        int32             `json:"id"`
+	JobID     int32             `json:"job_id"`
+	JobTitle  string            `json:"job_title"`
+	Status    ApplicationStatus `json:"status"`
+	AppliedAt time.Time         `json:"applied_at"`
+}
+
+type UpdateApplicationRequest struct {
+	Status ApplicationStatus `json:"status"`
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Secret signs the deliveries; the server generates one when it is
+	// empty.
+	Secret string `json:"secret,omitempty"`
+}
+
+type Webhook struct {
+	ID        int32     `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	CreatedBy int32     `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	// Secret is only set on the webhook returned by CreateWebhook.
+	Secret string `json:"secret,omitempty"`
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "pending"
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	DeliveryStatusDead      DeliveryStatus = "dead"
+)
+
+// Delivery is one event sent, or to be sent, to a webhook.
+type Delivery struct {
+	ID             int64           `json:"id"`
+	WebhookID      int32           `json:"webhook_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         DeliveryStatus  `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	LastStatusCode *int32          `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// NotificationPreferences turns the email lists of a user on or off.
+type NotificationPreferences struct {
+	// ApplicationUpdates emails applicants when they apply and whenever
+	// their application changes status. Interview invites are sent
+	// regardless.
+	ApplicationUpdates bool `json:"application_updates"`
+	// ApplicantDigest emails admins a periodic digest of new applicants
+	// to their jobs.
+	ApplicantDigest bool `json:"applicant_digest"`
+}
+
+type Notification struct {
+	ID    int64  `json:"id"`
+	Kind  string `json:"kind"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	// Data is the data of the event the notification was made from.
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+	ReadAt    *time.Time      `json:"read_at,omitempty"`
+}
+
+// NotificationPage is one page of the inbox, newest first.
+type NotificationPage struct {
+	UnreadCount   int64          `json:"unread_count"`
+	Notifications []Notification `json:"notifications"`
+	// NextBefore is passed to Notifications for the next page; it is 0
+	// on the last one.
+	NextBefore int64 `json:"next_before,omitempty"`
+}
+
+type MarkNotificationsReadRequest struct {
+	IDs []int64 `json:"ids,omitempty"`
+	// All marks every notification as read instead.
+	All bool `json:"all,omitempty"`
+}
+
+type MarkNotificationsReadResult struct {
+	Marked      int64 `json:"marked"`
+	UnreadCount int64 `json:"unread_count"`
+}
+
+type InterviewStatus string
+
+const (
+	InterviewStatusScheduled           InterviewStatus = "scheduled"
+	InterviewStatusConfirmed           InterviewStatus = "confirmed"
+	InterviewStatusRescheduleRequested InterviewStatus = "reschedule_requested"
+	InterviewStatusCancelled           InterviewStatus = "cancelled"
+)
+
+// InterviewRequest schedules or moves an interview. It needs a location,
+// a video link or both.
+type InterviewRequest struct {
+	StartsAt        time.Time `json:"starts_at"`
+	DurationMinutes int       `json:"duration_minutes"`
+	// InterviewerIDs are admins, each free during the slot.
+	InterviewerIDs []int32 `json:"interviewer_ids"`
+	Location       string  `json:"location"`
+	VideoURL       string  `json:"video_url"`
+}
+
+type Interviewer struct {
+	ID    int32  `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type Interview struct {
+	ID              int32           `json:"id"`
+	ApplicationID   int32           `json:"application_id"`
+	ApplicantID     int32           `json:"applicant_id"`
+	JobID           int32           `json:"job_id"`
+	JobTitle        string          `json:"job_title"`
+	StartsAt        time.Time       `json:"starts_at"`
+	EndsAt          time.Time       `json:"ends_at"`
+	DurationMinutes int             `json:"duration_minutes"`
+	Location        string          `json:"location,omitempty"`
+	VideoURL        string          `json:"video_url,omitempty"`
+	Interviewers    []Interviewer   `json:"interviewers"`
+	Status          InterviewStatus `json:"status"`
+	// RescheduleNote is why the applicant asked to reschedule.
+	RescheduleNote string `json:"reschedule_note,omitempty"`
+	// Sequence counts the changes to the interview, as in its invite.
+	Sequence  int32     `json:"sequence"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type RescheduleRequest struct {
+	Note string `json:"note"`
+}
+
+// ScorecardCriterion is rated from 1 to Scale.
+type ScorecardCriterion struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Scale       int    `json:"scale"`
+}
+
+type ScorecardRequest struct {
+	Criteria []ScorecardCriterion `json:"criteria"`
+}
+
+type Scorecard struct {
+	JobID     int32                `json:"job_id"`
+	Criteria  []ScorecardCriterion `json:"criteria"`
+	UpdatedBy int32                `json:"updated_by"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
+
+type Recommendation string
+
+const (
+	RecommendationStrongNo  Recommendation = "strong_no"
+	RecommendationNo        Recommendation = "no"
+	RecommendationYes       Recommendation = "yes"
+	RecommendationStrongYes Recommendation = "strong_yes"
+)
+
+// FeedbackRequest saves feedback as a draft, or submits it. Submitting
+// needs every criterion rated and a recommendation.
+type FeedbackRequest struct {
+	// Ratings maps criterion names to ratings.
+	Ratings        map[string]int `json:"ratings"`
+	Recommendation Recommendation `json:"recommendation"`
+	Notes          string         `json:"notes"`
+	Submit         bool           `json:"submit"`
+}
+
+type FeedbackRating struct {
+	Criterion string `json:"criterion"`
+	Rating    int    `json:"rating"`
+	Scale     int    `json:"scale"`
+}
+
+type Feedback struct {
+	ID              int32            `json:"id"`
+	ApplicationID   int32            `json:"application_id"`
+	InterviewerID   int32            `json:"interviewer_id"`
+	InterviewerName string           `json:"interviewer_name"`
+	Ratings         []FeedbackRating `json:"ratings"`
+	Recommendation  Recommendation   `json:"recommendation,omitempty"`
+	Notes           string           `json:"notes"`
+	SubmittedAt     *time.Time       `json:"submitted_at,omitempty"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+}
+
+type CriterionSummary struct {
+	Criterion string  `json:"criterion"`
+	Scale     int     `json:"scale"`
+	Average   float64 `json:"average"`
+	Ratings   int     `json:"ratings"`
+}
+
+// ScorecardSummary aggregates the submitted feedback on an application.
+// While Hidden, only the number submitted is given.
+type ScorecardSummary struct {
+	ApplicationID   int32              `json:"application_id"`
+	Hidden          bool               `json:"hidden"`
+	Submitted       int                `json:"submitted"`
+	Criteria        []CriterionSummary `json:"criteria"`
+	Recommendations map[string]int     `json:"recommendations"`
+}
+
+type ApplicationFeedback struct {
+	Mine *Feedback `json:"mine,omitempty"`
+	// Feedback is the submitted feedback of the other admins, empty
+	// while it is hidden.
+	Feedback []Feedback       `json:"feedback"`
+	Summary  ScorecardSummary `json:"summary"`
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Vikuuu/synlabs-assignment/client"
+	"github.com/Vikuuu/synlabs-assignment/internal/auth"
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+	"github.com/Vikuuu/synlabs-assignment/internal/openapi"
+)
+
+func TestClientAgainstServer(t *testing.T) {
+	api := newTestAPI(t)
+	ctx := context.Background()
+	newClient := func(opts ...client.Option) *client.Client {
+		c, err := client.New(api.srv.URL+"/v1", opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	admin := newClient()
+	if _, err := admin.Signup(ctx, client.SignupRequest{
+		Name: "Ada", Email: "ada@example.com", Password: "secret123", UserType: client.UserTypeAdmin,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := admin.Login(ctx, "ada@example.com", "secret123"); err != nil {
+		t.Fatal(err)
+	}
+	created, err := admin.CreateJob(ctx, client.CreateJobRequest{Title: "Go developer", Description: "Write Go", CompanyName: "Synlabs"})
+	if err != nil || created.Title != "Go developer" {
+		t.Fatalf("created = %+v, err = %v", created, err)
+	}
+
+	// Logs in on the first request since the token has expired.
+	expired, _ := auth.MakeJWT(api.userID("ada@example.com"), api.cfg.secret, -time.Minute)
+	admin = newClient(client.WithToken(expired), client.WithCredentials("ada@example.com", "secret123"))
+	job, err := admin.Job(ctx, 1)
+	if err != nil || job.Title != "Go developer" || job.TotalApplications == nil || admin.Token() == expired {
+		t.Fatalf("job = %+v, err = %v", job, err)
+	}
+
+	ann := newClient(client.WithCredentials("ann@example.com", "secret123"))
+	if _, err := ann.Signup(ctx, client.SignupRequest{
+		Name: "Ann", Email: "ann@example.com", Password: "secret123", Address: "1 Main St", ProfileHeadline: "Gopher",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	jobs, err := ann.Jobs(ctx)
+	if err != nil || len(jobs) != 1 {
+		t.Fatalf("jobs = %+v, err = %v", jobs, err)
+	}
+	if err := ann.Apply(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := ann.Apply(ctx, 99); client.CodeOf(err) != client.CodeJobNotFound {
+		t.Errorf("apply to missing job: %v", err)
+	}
+
+	applicants, err := admin.Applicants(ctx)
+	if err != nil || len(applicants) != 1 || applicants[0].ProfileHeadline != "Gopher" {
+		t.Fatalf("applicants = %+v, err = %v", applicants, err)
+	}
+	applicant, err := admin.Applicant(ctx, api.userID("ann@example.com"))
+	if err != nil || applicant.Address != "1 Main St" {
+		t.Fatalf("applicant = %+v, err = %v", applicant, err)
+	}
+	if _, err := ann.Applicants(ctx); client.CodeOf(err) != client.CodeForbidden {
+		t.Errorf("applicant listing applicants: %v", err)
+	}
+
+	_, err = newClient().Signup(ctx, client.SignupRequest{Name: "Ann", Email: "not-an-email", Password: "x"})
+	apiErr := &client.Error{}
+	if !errors.As(err, &apiErr) || apiErr.Code != client.CodeValidationFailed || len(apiErr.Errors) == 0 || apiErr.RequestID == "" {
+		t.Errorf("err = %#v", err)
+	}
+	if _, err := newClient().Login(ctx, "ann@example.com", "wrong-password"); client.CodeOf(err) != client.CodeInvalidCredentials {
+		t.Errorf("login with wrong password: %v", err)
+	}
+}
+
+// TestClientTypesMatchServer guards against the client package drifting
+// from the payloads the handlers decode and encode.
+func TestClientTypesMatchServer(t *testing.T) {
+	pairs := []struct{ server, client interface{} }{
+		{signupPayload{}, client.SignupRequest{}},
+		{signupResponse{}, client.User{}},
+		{loginPayload{}, client.LoginRequest{}},
+		{loginResponse{}, client.LoginResponse{}},
+		{uploadResumePayload{}, client.UploadResumeRequest{}},
+		{uploadResumeResponse{}, client.Resume{}},
+		{jobListResponse{}, client.JobListing{}},
+		{applyResponse{}, client.ApplyResponse{}},
+		{addJobPayload{}, client.CreateJobRequest{}},
+		{addJobResponse{}, client.CreateJobResponse{}},
+		{jobResponse{}, client.Job{}},
+		{applicantsResponse{}, client.ApplicantSummary{}},
+		{applicantResponse{}, client.Applicant{}},
+		{problem{}, client.Error{}},
+		{fieldError{}, client.FieldError{}},
+		{applicationResponse{}, client.Application{}},
+		{updateApplicationPayload{}, client.UpdateApplicationRequest{}},
+		{addWebhookPayload{}, client.CreateWebhookRequest{}},
+		{webhookResponse{}, client.Webhook{}},
+		{deliveryResponse{}, client.Delivery{}},
+		{notificationPreferences{}, client.NotificationPreferences{}},
+		{notificationResponse{}, client.Notification{}},
+		{notificationsResponse{}, client.NotificationPage{}},
+		{markReadPayload{}, client.MarkNotificationsReadRequest{}},
+		{markReadResponse{}, client.MarkNotificationsReadResult{}},
+		{interviewPayload{}, client.InterviewRequest{}},
+		{interviewerResponse{}, client.Interviewer{}},
+		{interviewResponse{}, client.Interview{}},
+		{rescheduleRequestPayload{}, client.RescheduleRequest{}},
+		{scorecardPayload{}, client.ScorecardRequest{}},
+		{scorecardResponse{}, client.Scorecard{}},
+		{feedbackPayload{}, client.FeedbackRequest{}},
+		{feedbackResponse{}, client.Feedback{}},
+		{scorecardSummary{}, client.ScorecardSummary{}},
+		{applicationFeedbackResponse{}, client.ApplicationFeedback{}},
+	}
+
+	schemas := newSchemas()
+	schemas.Define(client.UserType(""), schemas.For(database.UserType("")))
+	schemas.Define(client.Code(""), schemas.For(errorCode("")))
+	schemas.Define(client.ApplicationStatus(""), schemas.For(database.ApplicationStatus("")))
+	schemas.Define(client.DeliveryStatus(""), schemas.For(database.WebhookDeliveryStatus("")))
+	schemas.Define(client.InterviewStatus(""), schemas.For(database.InterviewStatus("")))
+	schemas.Define(client.Recommendation(""), schemas.For(database.Recommendation("")))
+	doc := openapi.New(openapi.Info{}, schemas)
+
+	for _, p := range pairs {
+		got, want := expandSchema(t, doc, schemas.For(p.client)), expandSchema(t, doc, schemas.For(p.server))
+		if got != want {
+			t.Errorf("%T does not match %T:\n%s\nwant\n%s", p.client, p.server, got, want)
+		}
+	}
+}
+
+// expandSchema encodes s with every component reference replaced by the
+// component, so types with different names compare equal.
+func expandSchema(t *testing.T, doc *openapi.Document, s *openapi.Schema) string {
+	t.Helper()
+	var expand func(v interface{}) interface{}
+	expand = func(v interface{}) interface{} {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if ref, ok := v["$ref"].(string); ok {
+				var c interface{}
+				data, _ := json.Marshal(doc.Components.Schemas[strings.TrimPrefix(ref, "#/components/schemas/")])
+				json.Unmarshal(data, &c)
+				return expand(c)
+			}
+			for k, e := range v {
+				v[k] = expand(e)
+			}
+		case []interface{}:
+			for i, e := range v {
+				v[i] = expand(e)
+			}
+		}
+		return v
+	}
+
+	var v interface{}
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	json.Unmarshal(data, &v)
+	out, _ := json.Marshal(expand(v))
+	return string(out)
+}
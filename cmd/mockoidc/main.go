@@ -0,0 +1,26 @@
+// Command mockoidc runs the oidctest provider on a fixed address so the
+// SSO login can be tried locally without a real identity provider.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/oidc/oidctest"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:9999", "listen address")
+	clientID := flag.String("client-id", "synlabs", "expected client_id")
+	email := flag.String("email", "recruiter@example.com", "email of the signed in user")
+	flag.Parse()
+
+	p, err := oidctest.New("http://"+*addr, *clientID, *email)
+	if err != nil {
+		log.Fatalf("creating provider: %s", err)
+	}
+
+	log.Printf("Mock OIDC issuer: %s\n", p.Issuer)
+	log.Fatal(http.ListenAndServe(*addr, p))
+}
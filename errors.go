@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+)
+
+// errorCode is the stable, machine-readable identifier of a problem.
+// Clients branch on it, so existing values must never change meaning.
+type errorCode string
+
+const (
+	codeMalformedRequest    errorCode = "request.malformed"
+	codeValidationFailed    errorCode = "validation.failed"
+	codeUnauthorized        errorCode = "auth.unauthorized"
+	codeInvalidCredentials  errorCode = "auth.invalid_credentials"
+	codeForbidden           errorCode = "auth.forbidden"
+	codeLoginSession        errorCode = "auth.login_session_invalid"
+	codeNotFound            errorCode = "route.not_found"
+	codeMethodNotAllowed    errorCode = "route.method_not_allowed"
+	codeEmailTaken          errorCode = "user.email_taken"
+	codeJobNotFound         errorCode = "job.not_found"
+	codeJobClosed           errorCode = "job.closed"
+	codeApplicantNotFound   errorCode = "applicant.not_found"
+	codeApplicationNotFound errorCode = "application.not_found"
+	codeWebhookNotFound     errorCode = "webhook.not_found"
+	codeDeliveryNotFound    errorCode = "webhook.delivery_not_found"
+	codeInterviewNotFound   errorCode = "interview.not_found"
+	codeInterviewConflict   errorCode = "interview.conflict"
+	codeInterviewCancelled  errorCode = "interview.cancelled"
+	codeScorecardNotFound   errorCode = "scorecard.not_found"
+	codeFeedbackSubmitted   errorCode = "feedback.submitted"
+	codeUpstreamFailed      errorCode = "upstream.unavailable"
+	codeInternal            errorCode = "internal.error"
+)
+
+var errorCodes = map[errorCode]struct {
+	status int
+	title  string
+}{
+	codeMalformedRequest:    {http.StatusBadRequest, "Malformed request"},
+	codeValidationFailed:    {http.StatusUnprocessableEntity, "Validation failed"},
+	codeUnauthorized:        {http.StatusUnauthorized, "Authentication required"},
+	codeInvalidCredentials:  {http.StatusUnauthorized, "Invalid credentials"},
+	codeForbidden:           {http.StatusForbidden, "Forbidden"},
+	codeLoginSession:        {http.StatusBadRequest, "Login session invalid"},
+	codeNotFound:            {http.StatusNotFound, "Not found"},
+	codeMethodNotAllowed:    {http.StatusMethodNotAllowed, "Method not allowed"},
+	codeEmailTaken:          {http.StatusConflict, "Email already registered"},
+	codeJobNotFound:         {http.StatusNotFound, "Job not found"},
+	codeJobClosed:           {http.StatusConflict, "Job closed"},
+	codeApplicantNotFound:   {http.StatusNotFound, "Applicant not found"},
+	codeApplicationNotFound: {http.StatusNotFound, "Application not found"},
+	codeWebhookNotFound:     {http.StatusNotFound, "Webhook not found"},
+	codeDeliveryNotFound:    {http.StatusNotFound, "Webhook delivery not found"},
+	codeInterviewNotFound:   {http.StatusNotFound, "Interview not found"},
+	codeInterviewConflict:   {http.StatusConflict, "Interviewer unavailable"},
+	codeInterviewCancelled:  {http.StatusConflict, "Interview cancelled"},
+	codeScorecardNotFound:   {http.StatusNotFound, "Scorecard not found"},
+	codeFeedbackSubmitted:   {http.StatusConflict, "Feedback already submitted"},
+	codeUpstreamFailed:      {http.StatusBadGateway, "Upstream service unavailable"},
+	codeInternal:            {http.StatusInternalServerError, "Internal server error"},
+}
+
+// fieldError describes one invalid field of a request.
+type fieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// apiError is returned by handlers for failures the client should hear
+// about. Msg becomes the problem detail, Err is only logged.
+type apiError struct {
+	Code   errorCode
+	Msg    string
+	Err    error
+	Fields []fieldError
+}
+
+func newAPIError(code errorCode, msg string, err error) *apiError {
+	return &apiError{Code: code, Msg: msg, Err: err}
+}
+
+func newValidationError(fields ...fieldError) *apiError {
+	return &apiError{
+		Code:   codeValidationFailed,
+		Msg:    "One or more fields are invalid",
+		Fields: fields,
+	}
+}
+
+func (e *apiError) Status() int {
+	if c, ok := errorCodes[e.Code]; ok {
+		return c.status
+	}
+	return http.StatusInternalServerError
+}
+
+func (e *apiError) Error() string {
+	if e.Err != nil {
+		return e.Msg + ": " + e.Err.Error()
+	}
+	return e.Msg
+}
+
+func (e *apiError) Unwrap() error {
+	return e.Err
+}
+
+// apiHandler is a handler that reports failures by returning an error
+// instead of writing the response itself. Errors must be returned before
+// anything is written to w.
+type apiHandler func(w http.ResponseWriter, r *http.Request) error
+
+func (h apiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := h(w, r); err != nil {
+		writeError(w, r, err)
+	}
+}
+
+// writeError maps err to a problem response. Anything that is not an
+// apiError is treated as an internal failure and its details are kept
+// out of the response.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	apiErr := &apiError{}
+	if !errors.As(err, &apiErr) {
+		requestLogger(r).Error("request failed", "error", err)
+		apiErr = newAPIError(codeInternal, "The server could not complete the request", nil)
+	} else if apiErr.Status() >= 500 {
+		requestLogger(r).Error(apiErr.Msg, "code", apiErr.Code, "error", apiErr.Err)
+	} else if apiErr.Err != nil {
+		requestLogger(r).Warn(apiErr.Msg, "code", apiErr.Code, "error", apiErr.Err)
+	}
+
+	respondWithProblem(w, r, apiErr)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+	"github.com/Vikuuu/synlabs-assignment/internal/live"
+)
+
+// Domain event types. Webhook subscribers and outbox consumers rely on
+// them and on the shape of their data, so existing ones must not change.
+const (
+	eventJobCreated               = "job.created"
+	eventJobClosed                = "job.closed"
+	eventApplicationCreated       = "application.created"
+	eventApplicationStatusChanged = "application.status_changed"
+	eventResumeParsed             = "resume.parsed"
+	eventInterviewScheduled       = "interview.scheduled"
+	eventInterviewStatusChanged   = "interview.status_changed"
+	eventFeedbackSubmitted        = "feedback.submitted"
+)
+
+var eventTypes = []string{
+	eventJobCreated,
+	eventJobClosed,
+	eventApplicationCreated,
+	eventApplicationStatusChanged,
+	eventResumeParsed,
+	eventInterviewScheduled,
+	eventInterviewStatusChanged,
+	eventFeedbackSubmitted,
+}
+
+// liveEventTypes are streamed by GET /admin/events and GET /me/events.
+var liveEventTypes = []string{
+	eventApplicationCreated,
+	eventApplicationStatusChanged,
+}
+
+// event is the payload of every outbox event and the body of every
+// webhook request. ID is the same wherever the event is delivered, so
+// receivers can deduplicate retried deliveries with it.
+type event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// jobEvent is the data of job.created and job.closed.
+type jobEvent struct {
+	ID          int32      `json:"id"`
+	Title       string     `json:"title"`
+	CompanyName string     `json:"company_name"`
+	PostedBy    int32      `json:"posted_by"`
+	PostedOn    time.Time  `json:"posted_on"`
+	ClosedAt    *time.Time `json:"closed_at,omitempty"`
+}
+
+// applicationEvent is the data of application.created and
+// application.status_changed.
+type applicationEvent struct {
+	ID             int32                      `json:"id"`
+	ApplicantID    int32                      `json:"applicant_id"`
+	JobID          int32                      `json:"job_id"`
+	Status         database.ApplicationStatus `json:"status"`
+	PreviousStatus database.ApplicationStatus `json:"previous_status,omitempty"`
+	AppliedAt      time.Time                  `json:"applied_at"`
+}
+
+// interviewEvent is the data of interview.scheduled, sent when an
+// interview is created or moved, and of interview.status_changed.
+type interviewEvent struct {
+	ID             int32                    `json:"id"`
+	ApplicationID  int32                    `json:"application_id"`
+	ApplicantID    int32                    `json:"applicant_id"`
+	JobID          int32                    `json:"job_id"`
+	StartsAt       time.Time                `json:"starts_at"`
+	EndsAt         time.Time                `json:"ends_at"`
+	Location       string                   `json:"location,omitempty"`
+	VideoURL       string                   `json:"video_url,omitempty"`
+	InterviewerIDs []int32                  `json:"interviewer_ids"`
+	Status         database.InterviewStatus `json:"status"`
+	PreviousStatus database.InterviewStatus `json:"previous_status,omitempty"`
+	RescheduleNote string                   `json:"reschedule_note,omitempty"`
+	Sequence       int32                    `json:"sequence"`
+}
+
+// feedbackEvent is the data of feedback.submitted. What the feedback
+// says stays behind the scorecard routes, which hide it from interviewers
+// that have not given theirs yet.
+type feedbackEvent struct {
+	ID            int32     `json:"id"`
+	ApplicationID int32     `json:"application_id"`
+	ApplicantID   int32     `json:"applicant_id"`
+	JobID         int32     `json:"job_id"`
+	InterviewerID int32     `json:"interviewer_id"`
+	SubmittedAt   time.Time `json:"submitted_at"`
+}
+
+type resumeParsedEvent struct {
+	ApplicantID int32  `json:"applicant_id"`
+	Skills      string `json:"skills"`
+	Education   string `json:"education"`
+}
+
+func newEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "evt_" + hex.EncodeToString(b), nil
+}
+
+// publish records an event in the outbox and queues it for every
+// webhook subscribed to its type. q should be the transaction making the
+// change, so the event only exists if the change commits.
+func publish(ctx context.Context, q database.Querier, eventType string, data interface{}) error {
+	id, err := newEventID()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	payload, err := json.Marshal(event{ID: id, Type: eventType, CreatedAt: now, Data: data})
+	if err != nil {
+		return fmt.Errorf("encoding %s event: %w", eventType, err)
+	}
+
+	err = q.CreateOutboxEvent(ctx, database.CreateOutboxEventParams{
+		EventID:   id,
+		EventType: eventType,
+		Payload:   payload,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return fmt.Errorf("recording %s event: %w", eventType, err)
+	}
+
+	// Live events also go straight to the event streams of every replica.
+	if slices.Contains(liveEventTypes, eventType) && len(payload) <= live.MaxPayload {
+		err := q.NotifyEvent(ctx, database.NotifyEventParams{Channel: live.Channel, Payload: string(payload)})
+		if err != nil {
+			return fmt.Errorf("notifying %s event: %w", eventType, err)
+		}
+	}
+
+	subs, err := q.ListWebhooksForEvent(ctx, eventType)
+	if err != nil {
+		return fmt.Errorf("listing webhooks for %s: %w", eventType, err)
+	}
+	for _, sub := range subs {
+		err := q.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+			SubscriptionID: sub,
+			EventID:        id,
+			EventType:      eventType,
+			Payload:        payload,
+			NextAttemptAt:  now,
+		})
+		if err != nil {
+			return fmt.Errorf("queueing %s for webhook %d: %w", eventType, sub, err)
+		}
+	}
+	return nil
+}
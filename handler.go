@@ -1,17 +1,25 @@
 package main
 
 import (
+	"bytes"
 	"context"
-	"database/sql"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/Vikuuu/synlabs-assignment/internal/auth"
 	"github.com/Vikuuu/synlabs-assignment/internal/database"
+	"github.com/Vikuuu/synlabs-assignment/internal/tracing"
 )
 
 func handlerLandingPage(w http.ResponseWriter, r *http.Request) {
@@ -20,13 +28,58 @@ func handlerLandingPage(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("Working"))
 }
 
+// handlerNotFound answers requests no route matched, telling apart an
+// unknown path from a known path used with the wrong method.
+func handlerNotFound(mux *http.ServeMux) apiHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		allowed := []string{}
+		for _, method := range []string{"GET", "POST", "PUT", "PATCH", "DELETE"} {
+			probe := r.Clone(r.Context())
+			probe.Method = method
+			if _, pattern := mux.Handler(probe); pattern != "" && pattern != "/" {
+				allowed = append(allowed, method)
+			}
+		}
+		if len(allowed) > 0 {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			return newAPIError(codeMethodNotAllowed, r.Method+" is not supported on this path", nil)
+		}
+		return newAPIError(codeNotFound, "No route matches this path", nil)
+	}
+}
+
 type signupPayload struct {
-	Name            string `json:"name"`
-	Email           string `json:"email"`
-	Password        string `json:"password"`
-	UserType        string `json:"user_type"`
-	ProfileHeadline string `json:"profile_headline"`
-	Address         string `json:"address"`
+	Name            string            `json:"name"`
+	Email           string            `json:"email"`
+	Password        string            `json:"password"`
+	UserType        database.UserType `json:"user_type,omitempty"`
+	ProfileHeadline string            `json:"profile_headline,omitempty"`
+	Address         string            `json:"address,omitempty"`
+}
+
+func (p *signupPayload) validate(v *validator) {
+	p.Name = strings.TrimSpace(p.Name)
+	p.Email = normalizeEmail(p.Email)
+	p.UserType = database.UserType(strings.TrimSpace(string(p.UserType)))
+	p.ProfileHeadline = strings.TrimSpace(p.ProfileHeadline)
+	p.Address = strings.TrimSpace(p.Address)
+
+	v.required("name", p.Name)
+	v.maxLen("name", p.Name, 100)
+	v.required("email", p.Email)
+	v.maxLen("email", p.Email, 254)
+	v.email("email", p.Email)
+	v.required("password", p.Password)
+	v.password("password", p.Password)
+	v.check(
+		p.Password == "" || !strings.EqualFold(p.Password, p.Email),
+		"password", "weak_password", "Must not be the same as the email",
+	)
+	if p.UserType != "" {
+		v.oneOf("user_type", string(p.UserType), string(database.UserTypeApplicant), string(database.UserTypeAdmin))
+	}
+	v.maxLen("profile_headline", p.ProfileHeadline, 200)
+	v.maxLen("address", p.Address, 500)
 }
 
 type signupResponse struct {
@@ -35,25 +88,24 @@ type signupResponse struct {
 	UserType database.UserType `json:"user_type"`
 }
 
-func (cfg *apiConfig) handlerSignUp(w http.ResponseWriter, r *http.Request) {
+func (cfg *apiConfig) handlerSignUp(w http.ResponseWriter, r *http.Request) error {
 	payload := signupPayload{}
-	decoder := json.NewDecoder(r.Body)
-	err := decoder.Decode(&payload)
+	err := decodeJSON(w, r, &payload)
 	if err != nil {
-		log.Fatalf("error decoding JSON: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return err
 	}
 
 	userType := database.UserTypeApplicant
-	if payload.UserType == "admin" {
+	if payload.UserType == database.UserTypeAdmin {
 		userType = database.UserTypeAdmin
 	}
 
 	hashPassword, err := auth.HashPassword(payload.Password)
+	if err != nil {
+		return fmt.Errorf("hashing password: %w", err)
+	}
 
-	// Add the in sql
-	dat, err := cfg.db.CreateUser(context.Background(), database.CreateUserParams{
+	dat, err := cfg.createUser(r.Context(), database.CreateUserParams{
 		Name:            payload.Name,
 		Email:           payload.Email,
 		Address:         payload.Address,
@@ -61,45 +113,54 @@ func (cfg *apiConfig) handlerSignUp(w http.ResponseWriter, r *http.Request) {
 		PasswordHash:    hashPassword,
 		ProfileHeadline: payload.ProfileHeadline,
 	})
+	if database.IsUniqueViolation(err) {
+		return newAPIError(codeEmailTaken, "An account with this email already exists", err)
+	}
 	if err != nil {
-		log.Fatalf("error saving to db: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return err
 	}
 
-	data := signupResponse{
+	cfg.metrics.Signup(string(dat.UserType))
+	return respondWithJSON(w, signupResponse{
 		Name:     dat.Name,
 		Email:    dat.Email,
 		UserType: dat.UserType,
-	}
-
-	resp, err := json.Marshal(data)
-	if err != nil {
-		log.Fatalf("error encoding JSON: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	w.Write(resp)
+	}, http.StatusCreated)
+}
 
-	if userType == "applicant" {
-		appID, err := cfg.db.CreateApplicantProfile(context.Background(), dat.ID)
+// createUser saves a user and, for applicants, their empty profile, in
+// one transaction so no applicant is left without a profile.
+func (cfg *apiConfig) createUser(ctx context.Context, params database.CreateUserParams) (database.CreateUserRow, error) {
+	var dat database.CreateUserRow
+	err := cfg.db.WithTx(ctx, func(q database.Querier) error {
+		var err error
+		dat, err = q.CreateUser(ctx, params)
 		if err != nil {
-			log.Fatalf("error creating applicant profile: %s", err)
-			return
+			return fmt.Errorf("saving user: %w", err)
 		}
-
-		err = cfg.db.AddProfileIDInUser(
-			context.Background(),
-			sql.NullInt32{Int32: appID, Valid: true},
-		)
-		if err != nil {
-			log.Fatalf("error adding profile_id: %s", err)
-			return
+		if dat.UserType != database.UserTypeApplicant {
+			return nil
 		}
+		return addApplicantProfile(ctx, q, dat.ID)
+	})
+	return dat, err
+}
+
+// addApplicantProfile creates the empty profile every applicant has and
+// links it to the user.
+func addApplicantProfile(ctx context.Context, q database.Querier, userID int32) error {
+	appID, err := q.CreateApplicantProfile(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("creating applicant profile: %w", err)
 	}
+	err = q.AddProfileIDInUser(ctx, database.AddProfileIDInUserParams{
+		ProfileID: pgtype.Int4{Int32: appID, Valid: true},
+		ID:        userID,
+	})
+	if err != nil {
+		return fmt.Errorf("adding profile_id: %w", err)
+	}
+	return nil
 }
 
 type loginPayload struct {
@@ -107,39 +168,39 @@ type loginPayload struct {
 	Password string `json:"password"`
 }
 
+func (p *loginPayload) validate(v *validator) {
+	p.Email = normalizeEmail(p.Email)
+
+	v.required("email", p.Email)
+	v.email("email", p.Email)
+	v.required("password", p.Password)
+}
+
 type loginResponse struct {
 	AccessToken string            `json:"access_token"`
 	UserType    database.UserType `json:"user_type"`
 }
 
-func (cfg *apiConfig) handlerLogIn(w http.ResponseWriter, r *http.Request) {
+func (cfg *apiConfig) handlerLogIn(w http.ResponseWriter, r *http.Request) error {
 	payload := loginPayload{}
-	decoder := json.NewDecoder(r.Body)
-	err := decoder.Decode(&payload)
+	err := decodeJSON(w, r, &payload)
 	if err != nil {
-		log.Fatalf("error decoding JSON: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return err
 	}
 
 	// get user data from the table
-	user, err := cfg.db.GetUser(context.Background(), payload.Email)
+	user, err := cfg.db.GetUser(r.Context(), payload.Email)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			errMsg := "Invalid credentials"
-			respondWithError(w, errMsg, http.StatusUnauthorized)
-			return
-		} else {
-			log.Fatalf("error getting user: %s", err)
-			w.WriteHeader(http.StatusInternalServerError)
-			return
+		if errors.Is(err, pgx.ErrNoRows) {
+			cfg.metrics.Login("password", false)
+			return newAPIError(codeInvalidCredentials, "Invalid credentials", nil)
 		}
+		return fmt.Errorf("getting user: %w", err)
 	}
 	err = auth.CheckPassword(payload.Password, user.PasswordHash)
 	if err != nil {
-		errMsg := "Invalid credentials"
-		respondWithError(w, errMsg, http.StatusUnauthorized)
-		return
+		cfg.metrics.Login("password", false)
+		return newAPIError(codeInvalidCredentials, "Invalid credentials", nil)
 	}
 
 	// create jwt and return the response
@@ -148,24 +209,31 @@ func (cfg *apiConfig) handlerLogIn(w http.ResponseWriter, r *http.Request) {
 
 	jwtToken, err := auth.MakeJWT(user.ID, tokenSecret, expiresIn)
 	if err != nil {
-		log.Fatalf("error creating JWT: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return fmt.Errorf("creating JWT: %w", err)
 	}
 
-	resp, err := json.Marshal(loginResponse{
+	cfg.metrics.Login("password", true)
+	return respondWithJSON(w, loginResponse{
 		AccessToken: jwtToken,
 		UserType:    user.UserType,
-	})
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write(resp)
+	}, http.StatusOK)
 }
 
 type uploadResumePayload struct {
 	FileAddress string `json:"file_address"`
 }
 
+func (p *uploadResumePayload) validate(v *validator) {
+	p.FileAddress = strings.TrimSpace(p.FileAddress)
+
+	v.required("file_address", p.FileAddress)
+	ext := strings.ToLower(filepath.Ext(p.FileAddress))
+	v.check(
+		ext == ".pdf" || ext == ".docx",
+		"file_address", "unsupported_format", "Only pdf and docx file supported",
+	)
+}
+
 type apiPayload struct {
 	Name      string   `json:"name"`
 	Phone     string   `json:"phone"`
@@ -190,84 +258,92 @@ type uploadResumeResponse struct {
 	Education string `json:"education"`
 }
 
-func (cfg *apiConfig) handlerUploadResume(w http.ResponseWriter, r *http.Request) {
-	// TODO: Authenticate API: (GetBearerToken)
-	jwt, err := auth.GetBearerToken(r.Header)
-	if err != nil {
-		log.Fatalf("error getting jwtToken: %s", err)
-		respondWithError(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-
-	userID, err := auth.ValidateJWT(jwt, cfg.secret)
-	if err != nil {
-		log.Printf("error validating token: %s", err)
-		respondWithError(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-
-	// TODO: Authorization: Applicant only
-	userType, err := cfg.db.GetUserFromID(context.Background(), int32(userID))
-	if err != nil {
-		log.Fatalf("error getting user: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	if userType == "admin" {
-		respondWithError(w, "You are not allowed to access this endpoint", http.StatusUnauthorized)
-		return
+func (cfg *apiConfig) handlerUploadResume(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := r.Context().Value("userID").(int)
+	if !ok {
+		return errors.New("user ID missing from request context")
 	}
 
 	// TODO: Decode the payload
 	payload := uploadResumePayload{}
-	decoder := json.NewDecoder(r.Body)
-	err = decoder.Decode(&payload)
+	err := decodeJSON(w, r, &payload)
 	if err != nil {
-		log.Fatalf("error decoding JSON: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return err
 	}
 
-	log.Printf("FileAddress: %s", payload.FileAddress)
-	// TODO: Check for suffix to be .pdf or .docx
-	if !strings.HasSuffix(payload.FileAddress, "pdf") &&
-		!strings.HasSuffix(payload.FileAddress, "docx") {
-		respondWithError(w, "Only pdf and docx file supported", http.StatusBadRequest)
-		return
+	requestLogger(r).Debug("parsing resume", "file_address", payload.FileAddress)
+
+	dat, err := cfg.parseResume(r.Context(), int32(userID), payload.FileAddress)
+	if err != nil {
+		return err
 	}
+	// TODO: If all goes well return 200
+	return respondWithJSON(w, uploadResumeResponse{
+		Name:      dat.Name.String,
+		Email:     dat.Email.String,
+		Phone:     dat.Phone.String,
+		Skills:    dat.Skills.String,
+		Education: dat.Education.String,
+	}, http.StatusOK)
+}
 
+// parseResume reads the resume at fileAddress, sends it to the parser and
+// stores the extracted details on the applicant's profile. It backs both
+// the upload endpoint and the reparse admin command.
+func (cfg *apiConfig) parseResume(ctx context.Context, userID int32, fileAddress string) (database.UpdateProfileRow, error) {
 	// TODO: Open the file
-	file, err := os.Open(payload.FileAddress)
+	_, span := tracing.Tracer().Start(ctx, "resume.read_file")
+	resume, err := readResume(fileAddress)
+	span.SetAttributes(attribute.Int("resume.size_bytes", len(resume)))
+	span.End()
 	if err != nil {
-		log.Fatalf("error opening file: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return database.UpdateProfileRow{}, newValidationError(fieldError{
+			Field:   "file_address",
+			Code:    "unreadable",
+			Message: "Resume file could not be opened",
+		})
 	}
-	defer file.Close()
 
 	// TODO: Make call to the 3rd party API
-	client := &http.Client{}
-	req, err := http.NewRequest("POST", "https://api.apilayer.com/resume_parser/upload", file)
+	parseCtx, cancel := context.WithTimeout(ctx, cfg.resumeParser.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(
+		parseCtx,
+		"POST",
+		cfg.resumeParser.URL,
+		bytes.NewReader(resume),
+	)
+	if err != nil {
+		return database.UpdateProfileRow{}, fmt.Errorf("building resume parser request: %w", err)
+	}
 	req.Header.Add("Content-Type", "application/octet-stream")
-	req.Header.Add("apiKey", "0bWeisRWoLj3UdXt3MXMSMWptYFIpQfS")
+	req.Header.Add("apiKey", cfg.resumeParser.APIKey.Reveal())
 
-	res, err := client.Do(req)
+	start := time.Now()
+	res, err := cfg.httpClient.Do(req)
 	if err != nil {
-		log.Fatalf("error making request to API: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		cfg.metrics.ObserveResumeParse("request_error", time.Since(start))
+		return database.UpdateProfileRow{}, newAPIError(codeUpstreamFailed, "Resume parser unavailable", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		cfg.metrics.ObserveResumeParse("bad_status", time.Since(start))
+		return database.UpdateProfileRow{}, newAPIError(
+			codeUpstreamFailed,
+			"Resume parser unavailable",
+			fmt.Errorf("unexpected status %d", res.StatusCode),
+		)
 	}
 
 	// TODO: Decode the data from 3rd party API
 	apiPl := apiPayload{}
-	decoder = json.NewDecoder(res.Body)
+	decoder := json.NewDecoder(res.Body)
 	err = decoder.Decode(&apiPl)
 	if err != nil {
-		log.Fatalf("error marshaling JSON: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		cfg.metrics.ObserveResumeParse("invalid_response", time.Since(start))
+		return database.UpdateProfileRow{}, newAPIError(codeUpstreamFailed, "Resume parser returned an invalid response", err)
 	}
+	cfg.metrics.ObserveResumeParse("success", time.Since(start))
 
 	// TODO: Concatenate the skills into comma separated.
 	skills := strings.Join(apiPl.Skills, ",")
@@ -277,29 +353,51 @@ func (cfg *apiConfig) handlerUploadResume(w http.ResponseWriter, r *http.Request
 	}
 	educations := strings.Join(e, ",")
 	// TODO: Save all the details into Applicant profile
-	dat, err := cfg.db.UpdateProfile(context.Background(), database.UpdateProfileParams{
-		Name:              sql.NullString{String: apiPl.Name, Valid: true},
-		Email:             sql.NullString{String: apiPl.Email, Valid: true},
-		Phone:             sql.NullString{String: apiPl.Phone, Valid: true},
-		Skills:            sql.NullString{String: skills, Valid: true},
-		Education:         sql.NullString{String: educations, Valid: true},
-		ResumeFileAddress: sql.NullString{String: payload.FileAddress, Valid: true},
-		Applicant:         int32(userID),
+	// The parser is called before the transaction starts so a slow
+	// upstream never holds a connection.
+	var dat database.UpdateProfileRow
+	err = cfg.db.WithTx(ctx, func(q database.Querier) error {
+		dat, err = q.UpdateProfile(ctx, database.UpdateProfileParams{
+			Name:              pgtype.Text{String: apiPl.Name, Valid: true},
+			Email:             pgtype.Text{String: apiPl.Email, Valid: true},
+			Phone:             pgtype.Text{String: apiPl.Phone, Valid: true},
+			Skills:            pgtype.Text{String: skills, Valid: true},
+			Education:         pgtype.Text{String: educations, Valid: true},
+			ResumeFileAddress: pgtype.Text{String: fileAddress, Valid: true},
+			Applicant:         userID,
+		})
+		if err != nil {
+			return fmt.Errorf("updating profile: %w", err)
+		}
+		return publish(ctx, q, eventResumeParsed, resumeParsedEvent{
+			ApplicantID: userID,
+			Skills:      skills,
+			Education:   educations,
+		})
 	})
 	if err != nil {
-		log.Fatalf("error updating profile: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return database.UpdateProfileRow{}, err
 	}
-	// TODO: If all goes well return 200
-	resp, err := json.Marshal(uploadResumeResponse{
-		Name:      dat.Name.String,
-		Email:     dat.Email.String,
-		Phone:     dat.Phone.String,
-		Skills:    dat.Skills.String,
-		Education: dat.Education.String,
-	})
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write(resp)
+	return dat, nil
+}
+
+const maxResumeBytes = 10 << 20
+
+// readResume loads the resume into memory up front so reading the file
+// and calling the parser show up as separate steps in traces.
+func readResume(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxResumeBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxResumeBytes {
+		return nil, fmt.Errorf("resume is larger than %d bytes", maxResumeBytes)
+	}
+	return data, nil
 }
@@ -2,13 +2,16 @@ package main
 
 import (
 	"context"
-	"database/sql"
-	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
 	"github.com/Vikuuu/synlabs-assignment/internal/database"
 )
 
@@ -18,88 +21,176 @@ type addJobPayload struct {
 	CompanyName string `json:"company_name"`
 }
 
+func (p *addJobPayload) validate(v *validator) {
+	p.Title = strings.TrimSpace(p.Title)
+	p.Description = strings.TrimSpace(p.Description)
+	p.CompanyName = strings.TrimSpace(p.CompanyName)
+
+	v.required("title", p.Title)
+	v.maxLen("title", p.Title, 200)
+	v.required("description", p.Description)
+	v.maxLen("description", p.Description, 10000)
+	v.required("company_name", p.CompanyName)
+	v.maxLen("company_name", p.CompanyName, 200)
+}
+
 type addJobResponse struct {
+	ID          int32  `json:"id"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	CompanyName string `json:"company_name"`
 }
 
-func (cfg *apiConfig) handlerAddJob(w http.ResponseWriter, r *http.Request) {
+func (cfg *apiConfig) handlerAddJob(w http.ResponseWriter, r *http.Request) error {
 	userID, ok := r.Context().Value("userID").(int)
 	if !ok {
-		respondWithError(w, "Failed to retrieve user ID", http.StatusInternalServerError)
-		return
+		return errors.New("user ID missing from request context")
 	}
 
 	payload := addJobPayload{}
-	decoder := json.NewDecoder(r.Body)
-	err := decoder.Decode(&payload)
+	err := decodeJSON(w, r, &payload)
 	if err != nil {
-		log.Printf("error decoding JSON: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return err
 	}
 
 	// TODO: create job openings
-	data, err := cfg.db.CreateJob(context.Background(), database.CreateJobParams{
-		Title:       payload.Title,
-		Description: payload.Description,
-		PostedOn:    time.Now(),
-		CompanyName: payload.CompanyName,
-		PostedBy:    int32(userID),
+	ctx := r.Context()
+	var data database.CreateJobRow
+	err = cfg.db.WithTx(ctx, func(q database.Querier) error {
+		data, err = q.CreateJob(ctx, database.CreateJobParams{
+			Title:       payload.Title,
+			Description: payload.Description,
+			PostedOn:    time.Now(),
+			CompanyName: payload.CompanyName,
+			PostedBy:    int32(userID),
+		})
+		if err != nil {
+			return fmt.Errorf("creating job: %w", err)
+		}
+		return publish(ctx, q, eventJobCreated, jobEvent{
+			ID:          data.ID,
+			Title:       data.Title,
+			CompanyName: data.CompanyName,
+			PostedBy:    data.PostedBy,
+			PostedOn:    data.PostedOn,
+		})
 	})
 	if err != nil {
-		log.Printf("error creating job: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return err
 	}
 
-	resp, err := json.Marshal(addJobResponse{
+	cfg.metrics.JobPosted()
+	return respondWithJSON(w, addJobResponse{
+		ID:          data.ID,
 		Title:       data.Title,
 		Description: data.Description,
 		CompanyName: data.CompanyName,
-	})
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	w.Write(resp)
+	}, http.StatusCreated)
 }
 
 type jobResponse struct {
-	Title             string        `json:"title"`
-	Description       string        `json:"description"`
-	PostedOn          time.Time     `json:"posted_on"`
-	CompanyName       string        `json:"company_name"`
-	PostedBy          int32         `json:"posted_by"`
-	TotalApplications sql.NullInt32 `json:"total_applications"`
+	Title             string      `json:"title"`
+	Description       string      `json:"description"`
+	PostedOn          time.Time   `json:"posted_on"`
+	CompanyName       string      `json:"company_name"`
+	PostedBy          int32       `json:"posted_by"`
+	TotalApplications pgtype.Int4 `json:"total_applications"`
+	ClosedAt          *time.Time  `json:"closed_at,omitempty"`
 }
 
-func (cfg *apiConfig) handlerJob(w http.ResponseWriter, r *http.Request) {
+func (cfg *apiConfig) handlerJob(w http.ResponseWriter, r *http.Request) error {
 	jobID, err := strconv.Atoi(r.PathValue("job_id"))
 	if err != nil {
-		log.Printf("error in type changing: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return newValidationError(fieldError{
+			Field:   "job_id",
+			Code:    "invalid",
+			Message: "Job ID must be an integer",
+		})
 	}
 
-	data, err := cfg.db.GetJob(context.Background(), int32(jobID))
+	data, err := cfg.db.GetJob(r.Context(), int32(jobID))
 	if err != nil {
-		respondWithError(w, "error getting job", http.StatusNotFound)
-		return
+		if errors.Is(err, pgx.ErrNoRows) {
+			return newAPIError(codeJobNotFound, "No job exists with this ID", nil)
+		}
+		return fmt.Errorf("getting job: %w", err)
 	}
 
-	resp, err := json.Marshal(jobResponse{
+	return respondWithJSON(w, newJobResponse(data), http.StatusOK)
+}
+
+func newJobResponse(data database.GetJobRow) jobResponse {
+	res := jobResponse{
 		Title:             data.Title,
 		Description:       data.Description,
 		PostedOn:          data.PostedOn,
 		CompanyName:       data.CompanyName,
 		PostedBy:          data.PostedBy,
 		TotalApplications: data.TotalApplications,
-	})
+	}
+	if data.ClosedAt.Valid {
+		res.ClosedAt = &data.ClosedAt.Time
+	}
+	return res
+}
+
+func (cfg *apiConfig) handlerCloseJob(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := r.Context().Value("userID").(int)
+	if !ok {
+		return errors.New("user ID missing from request context")
+	}
+	jobID, err := strconv.Atoi(r.PathValue("job_id"))
+	if err != nil {
+		return newValidationError(fieldError{
+			Field:   "job_id",
+			Code:    "invalid",
+			Message: "Job ID must be an integer",
+		})
+	}
+
+	poster := int32(userID)
+	data, err := cfg.closeJob(r.Context(), int32(jobID), &poster)
+	if err != nil {
+		return err
+	}
+	return respondWithJSON(w, newJobResponse(data), http.StatusOK)
+}
+
+// closeJob stops a job from accepting applications and publishes
+// job.closed. When poster is set, the job must be theirs.
+func (cfg *apiConfig) closeJob(ctx context.Context, jobID int32, poster *int32) (database.GetJobRow, error) {
+	var job database.GetJobRow
+	err := cfg.db.WithTx(ctx, func(q database.Querier) error {
+		var err error
+		job, err = q.GetJob(ctx, jobID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return newAPIError(codeJobNotFound, "No job exists with this ID", nil)
+			}
+			return fmt.Errorf("getting job: %w", err)
+		}
+		if poster != nil && job.PostedBy != *poster {
+			return newAPIError(codeForbidden, "Only the admin who posted the job can close it", nil)
+		}
+		if job.ClosedAt.Valid {
+			return newAPIError(codeJobClosed, "This job is already closed", nil)
+		}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write(resp)
+		job.ClosedAt = pgtype.Timestamp{Time: time.Now(), Valid: true}
+		_, err = q.CloseJob(ctx, database.CloseJobParams{ID: jobID, ClosedAt: job.ClosedAt})
+		if err != nil {
+			return fmt.Errorf("closing job: %w", err)
+		}
+		return publish(ctx, q, eventJobClosed, jobEvent{
+			ID:          jobID,
+			Title:       job.Title,
+			CompanyName: job.CompanyName,
+			PostedBy:    job.PostedBy,
+			PostedOn:    job.PostedOn,
+			ClosedAt:    &job.ClosedAt.Time,
+		})
+	})
+	return job, err
 }
 
 type applicantsResponse struct {
@@ -109,12 +200,10 @@ type applicantsResponse struct {
 	ProfileHeadline string `json:"profile_headline"`
 }
 
-func (cfg *apiConfig) handlerApplicants(w http.ResponseWriter, r *http.Request) {
-	data, err := cfg.db.GetApplicants(context.Background())
+func (cfg *apiConfig) handlerApplicants(w http.ResponseWriter, r *http.Request) error {
+	data, err := cfg.db.GetApplicants(r.Context())
 	if err != nil {
-		log.Printf("error fetching applicants: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return fmt.Errorf("fetching applicants: %w", err)
 	}
 	res := []applicantsResponse{}
 	for _, val := range data {
@@ -127,45 +216,73 @@ func (cfg *apiConfig) handlerApplicants(w http.ResponseWriter, r *http.Request)
 		res = append(res, i)
 	}
 
-	resp, err := json.Marshal(res)
-	if err != nil {
-		log.Printf("error encoding JSON: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write(resp)
+	return respondWithJSON(w, res, http.StatusOK)
 }
 
 type applicantResponse struct {
-	Name            string `json:"name"`
-	Email           string `json:"email"`
-	Address         string `json:"address"`
-	ProfileHeadline string `json:"profile_headline"`
-	Resume          string `json:"resume"`
-	Skills          string `json:"skills"`
-	Education       string `json:"education"`
-	Phone           string `json:"phone"`
+	Name            string                `json:"name"`
+	Email           string                `json:"email"`
+	Address         string                `json:"address"`
+	ProfileHeadline string                `json:"profile_headline"`
+	Resume          string                `json:"resume"`
+	Skills          string                `json:"skills"`
+	Education       string                `json:"education"`
+	Phone           string                `json:"phone"`
+	Applications    []applicationResponse `json:"applications"`
+	// Scorecards summarizes the feedback on the applications that have
+	// any.
+	Scorecards []scorecardSummary `json:"scorecards"`
 }
 
-func (cfg *apiConfig) handlerApplicant(w http.ResponseWriter, r *http.Request) {
+type applicationResponse struct {
+	ID        int32                      `json:"id"`
+	JobID     int32                      `json:"job_id"`
+	JobTitle  string                     `json:"job_title"`
+	Status    database.ApplicationStatus `json:"status"`
+	AppliedAt time.Time                  `json:"applied_at"`
+}
+
+func (cfg *apiConfig) handlerApplicant(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := r.Context().Value("userID").(int)
+	if !ok {
+		return errors.New("user ID missing from request context")
+	}
 	aID, err := strconv.Atoi(r.PathValue("applicant_id"))
 	if err != nil {
-		log.Printf("error in type changing: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return newValidationError(fieldError{
+			Field:   "applicant_id",
+			Code:    "invalid",
+			Message: "Applicant ID must be an integer",
+		})
 	}
 
-	data, err := cfg.db.GetApplicant(context.Background(), int32(aID))
+	data, err := cfg.db.GetApplicant(r.Context(), int32(aID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return newAPIError(codeApplicantNotFound, "No applicant exists with this ID", nil)
+		}
+		return fmt.Errorf("getting applicant: %w", err)
+	}
+	apps, err := cfg.db.ListApplicationsForApplicant(r.Context(), pgtype.Int4{Int32: int32(aID), Valid: true})
 	if err != nil {
-		log.Printf("error getting applicant: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return fmt.Errorf("listing applications: %w", err)
+	}
+	applications := []applicationResponse{}
+	for _, a := range apps {
+		applications = append(applications, applicationResponse{
+			ID:        a.ID,
+			JobID:     a.JobID.Int32,
+			JobTitle:  a.Title,
+			Status:    a.Status,
+			AppliedAt: a.AppliedAt,
+		})
+	}
+	scorecards, err := cfg.applicantScorecards(r.Context(), int32(userID), int32(aID))
+	if err != nil {
+		return err
 	}
 
-	resp, err := json.Marshal(applicantResponse{
+	return respondWithJSON(w, applicantResponse{
 		Name:            data.Name,
 		Email:           data.Email,
 		Address:         data.Address,
@@ -174,13 +291,101 @@ func (cfg *apiConfig) handlerApplicant(w http.ResponseWriter, r *http.Request) {
 		Skills:          data.Skills.String,
 		Education:       data.Education.String,
 		Phone:           data.Phone.String,
+		Applications:    applications,
+		Scorecards:      scorecards,
+	}, http.StatusOK)
+}
+
+var applicationStatuses = []string{
+	string(database.ApplicationStatusApplied),
+	string(database.ApplicationStatusReviewing),
+	string(database.ApplicationStatusInterviewing),
+	string(database.ApplicationStatusOffered),
+	string(database.ApplicationStatusHired),
+	string(database.ApplicationStatusRejected),
+}
+
+type updateApplicationPayload struct {
+	Status database.ApplicationStatus `json:"status"`
+}
+
+func (p *updateApplicationPayload) validate(v *validator) {
+	p.Status = database.ApplicationStatus(strings.TrimSpace(string(p.Status)))
+
+	v.required("status", string(p.Status))
+	if p.Status != "" {
+		v.oneOf("status", string(p.Status), applicationStatuses...)
+	}
+}
+
+func (cfg *apiConfig) handlerUpdateApplication(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := r.Context().Value("userID").(int)
+	if !ok {
+		return errors.New("user ID missing from request context")
+	}
+	appID, err := strconv.Atoi(r.PathValue("application_id"))
+	if err != nil {
+		return newValidationError(fieldError{
+			Field:   "application_id",
+			Code:    "invalid",
+			Message: "Application ID must be an integer",
+		})
+	}
+	payload := updateApplicationPayload{}
+	if err := decodeJSON(w, r, &payload); err != nil {
+		return err
+	}
+
+	// Setting the current status again is a no-op and publishes nothing.
+	ctx := r.Context()
+	var app database.GetApplicationRow
+	var job database.GetJobRow
+	err = cfg.db.WithTx(ctx, func(q database.Querier) error {
+		var err error
+		app, err = q.GetApplication(ctx, int32(appID))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return newAPIError(codeApplicationNotFound, "No application exists with this ID", nil)
+			}
+			return fmt.Errorf("getting application: %w", err)
+		}
+		job, err = q.GetJob(ctx, app.JobID.Int32)
+		if err != nil {
+			return fmt.Errorf("getting job: %w", err)
+		}
+		if job.PostedBy != int32(userID) {
+			return newAPIError(codeForbidden, "Only the admin who posted the job can change its applications", nil)
+		}
+		if app.Status == payload.Status {
+			return nil
+		}
+
+		_, err = q.UpdateApplicationStatus(ctx, database.UpdateApplicationStatusParams{
+			ID:     app.ID,
+			Status: payload.Status,
+		})
+		if err != nil {
+			return fmt.Errorf("updating application status: %w", err)
+		}
+		previous := app.Status
+		app.Status = payload.Status
+		return publish(ctx, q, eventApplicationStatusChanged, applicationEvent{
+			ID:             app.ID,
+			ApplicantID:    app.ApplicantID.Int32,
+			JobID:          app.JobID.Int32,
+			Status:         app.Status,
+			PreviousStatus: previous,
+			AppliedAt:      app.AppliedAt,
+		})
 	})
 	if err != nil {
-		log.Printf("error marshaling JSON: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return err
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write(resp)
+	return respondWithJSON(w, applicationResponse{
+		ID:        app.ID,
+		JobID:     app.JobID.Int32,
+		JobTitle:  job.Title,
+		Status:    app.Status,
+		AppliedAt: app.AppliedAt,
+	}, http.StatusOK)
 }
@@ -1,14 +1,15 @@
 package main
 
 import (
-	"context"
-	"database/sql"
-	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
 	"github.com/Vikuuu/synlabs-assignment/internal/database"
 )
 
@@ -21,12 +22,10 @@ type jobListResponse struct {
 	PostedBy          int32     `json:"posted_by"`
 }
 
-func (cfg *apiConfig) handlerViewJobs(w http.ResponseWriter, r *http.Request) {
-	data, err := cfg.db.GetJobsApplicant(context.Background())
+func (cfg *apiConfig) handlerViewJobs(w http.ResponseWriter, r *http.Request) error {
+	data, err := cfg.db.GetJobsApplicant(r.Context())
 	if err != nil {
-		log.Printf("error getting data: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return fmt.Errorf("getting jobs: %w", err)
 	}
 
 	res := []jobListResponse{}
@@ -41,53 +40,70 @@ func (cfg *apiConfig) handlerViewJobs(w http.ResponseWriter, r *http.Request) {
 		}
 		res = append(res, i)
 	}
-	resp, err := json.Marshal(res)
-	if err != nil {
-		log.Printf("error marshaling JSON: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write(resp)
+	return respondWithJSON(w, res, http.StatusOK)
+}
+
+type applyResponse struct {
+	Success bool `json:"success"`
 }
 
-func (cfg *apiConfig) handlerApplyJob(w http.ResponseWriter, r *http.Request) {
+func (cfg *apiConfig) handlerApplyJob(w http.ResponseWriter, r *http.Request) error {
 	jobID, err := strconv.Atoi(r.URL.Query().Get("job_id"))
 	if err != nil {
-		respondWithError(w, "Failed to get JobID", http.StatusInternalServerError)
-		return
+		return newValidationError(fieldError{
+			Field:   "job_id",
+			Code:    "invalid",
+			Message: "Job ID must be an integer",
+		})
+	}
+	userID, ok := r.Context().Value("userID").(int)
+	if !ok {
+		return errors.New("user ID missing from request context")
 	}
-	userID := r.Context().Value("userID").(int)
 
-	err = cfg.db.ApplyJob(context.Background(), database.ApplyJobParams{
-		ApplicantID: sql.NullInt32{Int32: int32(userID), Valid: true},
-		JobID:       sql.NullInt32{Int32: int32(jobID), Valid: true},
-	})
+	// The job is checked inside the transaction so it cannot close
+	// between the check and the insert.
+	ctx := r.Context()
+	err = cfg.db.WithTx(ctx, func(q database.Querier) error {
+		job, err := q.GetJob(ctx, int32(jobID))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return newAPIError(codeJobNotFound, "No job exists with this ID", nil)
+			}
+			return fmt.Errorf("getting job: %w", err)
+		}
+		if job.ClosedAt.Valid {
+			return newAPIError(codeJobClosed, "This job no longer accepts applications", nil)
+		}
+
+		appliedAt := time.Now()
+		appID, err := q.ApplyJob(ctx, database.ApplyJobParams{
+			ApplicantID: pgtype.Int4{Int32: int32(userID), Valid: true},
+			JobID:       pgtype.Int4{Int32: int32(jobID), Valid: true},
+			AppliedAt:   appliedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("applying to job: %w", err)
+		}
 
-	err = cfg.db.UpdateTotalApplications(context.Background(), int32(jobID))
+		err = q.UpdateTotalApplications(ctx, int32(jobID))
+		if err != nil {
+			return fmt.Errorf("increasing the count of total applicants: %w", err)
+		}
+		return publish(ctx, q, eventApplicationCreated, applicationEvent{
+			ID:          appID,
+			ApplicantID: int32(userID),
+			JobID:       int32(jobID),
+			Status:      database.ApplicationStatusApplied,
+			AppliedAt:   appliedAt,
+		})
+	})
 	if err != nil {
-		respondWithError(
-			w,
-			"Failed to increase the count of total applicants",
-			http.StatusInternalServerError,
-		)
-		return
+		return err
 	}
 
-	type applyResponse struct {
-		Success bool `json:"success"`
-	}
-	res := applyResponse{Success: true}
+	cfg.metrics.ApplicationSubmitted()
 
-	resp, err := json.Marshal(res)
-	if err != nil {
-		log.Printf("error marshaling JSON: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write(resp)
+	return respondWithJSON(w, applyResponse{Success: true}, http.StatusOK)
 }
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/live"
+)
+
+// eventStreamHeartbeat is how often an idle stream gets a comment, so
+// proxies do not time it out.
+const eventStreamHeartbeat = 15 * time.Second
+
+// streamEvents sends the live events keep accepts as server-sent events
+// until the client goes away or the server shuts down. Events committed
+// while a client is disconnected are not replayed.
+func (cfg *apiConfig) streamEvents(w http.ResponseWriter, r *http.Request, keep func(ctx context.Context, e live.Event) (bool, error)) error {
+	rc := http.NewResponseController(w)
+	// The server write timeout is meant for ordinary responses.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		return fmt.Errorf("clearing write deadline: %w", err)
+	}
+
+	sub := cfg.live.Subscribe()
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Keeps nginx from buffering the stream.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprint(w, "retry: 5000\n\n"); err != nil {
+		return nil
+	}
+	if err := rc.Flush(); err != nil {
+		return nil
+	}
+
+	heartbeat := time.NewTicker(eventStreamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+		case e, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+			send, err := keep(r.Context(), e)
+			if err != nil {
+				requestLogger(r).Error("filtering event", "event_id", e.ID, "error", err)
+				continue
+			}
+			if !send {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, e.Payload); err != nil {
+				return nil
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return nil
+		}
+	}
+}
+
+// handlerAdminEvents streams new applications to the jobs of the admin
+// and changes to their status.
+func (cfg *apiConfig) handlerAdminEvents(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := r.Context().Value("userID").(int)
+	if !ok {
+		return errors.New("user ID missing from request context")
+	}
+
+	// Who posted a job never changes, so it is looked up once per job.
+	owned := map[int32]bool{}
+	return cfg.streamEvents(w, r, func(ctx context.Context, e live.Event) (bool, error) {
+		data := applicationEvent{}
+		if err := decodeEventData(e.Payload, &data); err != nil {
+			return false, err
+		}
+		mine, ok := owned[data.JobID]
+		if !ok {
+			job, err := cfg.db.GetJob(ctx, data.JobID)
+			if err != nil {
+				return false, fmt.Errorf("getting job: %w", err)
+			}
+			mine = job.PostedBy == int32(userID)
+			owned[data.JobID] = mine
+		}
+		return mine, nil
+	})
+}
+
+// handlerApplicantEvents streams changes to the applications of the
+// applicant.
+func (cfg *apiConfig) handlerApplicantEvents(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := r.Context().Value("userID").(int)
+	if !ok {
+		return errors.New("user ID missing from request context")
+	}
+
+	return cfg.streamEvents(w, r, func(ctx context.Context, e live.Event) (bool, error) {
+		data := applicationEvent{}
+		if err := decodeEventData(e.Payload, &data); err != nil {
+			return false, err
+		}
+		return data.ApplicantID == int32(userID), nil
+	})
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Vikuuu/synlabs-assignment/sql/schema"
+)
+
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// handlerHealthz is the liveness probe. It only proves the process can
+// still serve HTTP and deliberately does not touch the database.
+func handlerHealthz(w http.ResponseWriter, r *http.Request) error {
+	return respondWithJSON(w, healthResponse{Status: "ok"}, http.StatusOK)
+}
+
+// handlerReadyz is the readiness probe. It fails while shutting down,
+// when the database is unreachable, or when the schema is behind the
+// migrations embedded in the binary.
+func (cfg *apiConfig) handlerReadyz(w http.ResponseWriter, r *http.Request) error {
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+
+	res := healthResponse{Status: "ready", Checks: map[string]string{}}
+	status := http.StatusOK
+	fail := func(check, reason string) {
+		res.Status = "unavailable"
+		res.Checks[check] = reason
+		status = http.StatusServiceUnavailable
+	}
+
+	if cfg.shuttingDown.Load() {
+		fail("server", "shutting down")
+	} else {
+		res.Checks["server"] = "ok"
+	}
+
+	if err := cfg.health.Ping(ctx); err != nil {
+		requestLogger(r).Warn("readiness database ping failed", "error", err)
+		fail("database", "unreachable")
+		fail("migrations", "unknown")
+		return respondWithJSON(w, res, status)
+	}
+	res.Checks["database"] = "ok"
+
+	if err := cfg.checkMigrations(ctx); err != nil {
+		requestLogger(r).Warn("readiness migration check failed", "error", err)
+		fail("migrations", err.Error())
+	} else {
+		res.Checks["migrations"] = "ok"
+	}
+
+	return respondWithJSON(w, res, status)
+}
+
+// healthChecker is what readiness needs from the database beyond the
+// sqlc queries.
+type healthChecker interface {
+	Ping(ctx context.Context) error
+	// SchemaVersion returns the newest applied goose migration.
+	SchemaVersion(ctx context.Context) (int64, error)
+}
+
+type poolHealth struct {
+	pool *pgxpool.Pool
+}
+
+func (h poolHealth) Ping(ctx context.Context) error {
+	return h.pool.Ping(ctx)
+}
+
+func (h poolHealth) SchemaVersion(ctx context.Context) (int64, error) {
+	var v int64
+	err := h.pool.QueryRow(ctx, `
+SELECT COALESCE(MAX(version_id), 0) FROM (
+    SELECT DISTINCT ON (version_id) version_id, is_applied
+    FROM goose_db_version
+    ORDER BY version_id, id DESC
+) v
+WHERE is_applied`).Scan(&v)
+	return v, err
+}
+
+// checkMigrations compares the goose version recorded in the database
+// with the newest embedded migration.
+func (cfg *apiConfig) checkMigrations(ctx context.Context) error {
+	want, err := schema.LatestVersion()
+	if err != nil {
+		return fmt.Errorf("reading embedded migrations: %w", err)
+	}
+
+	got, err := cfg.health.SchemaVersion(ctx)
+	if err != nil {
+		return errors.New("schema version unknown")
+	}
+	if got < want {
+		return fmt.Errorf("schema at version %d, want %d", got, want)
+	}
+	return nil
+}
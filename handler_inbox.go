@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+)
+
+const (
+	defaultNotificationLimit = 20
+	maxNotificationLimit     = 100
+)
+
+type notificationResponse struct {
+	ID    int64  `json:"id"`
+	Kind  string `json:"kind"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	// Data is the data of the event the notification was made from.
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+	ReadAt    *time.Time      `json:"read_at,omitempty"`
+}
+
+type notificationsResponse struct {
+	UnreadCount   int64                  `json:"unread_count"`
+	Notifications []notificationResponse `json:"notifications"`
+	// NextBefore is passed as before to get the next page; it is left
+	// out on the last one.
+	NextBefore int64 `json:"next_before,omitempty"`
+}
+
+// notificationPage parses the limit and before query parameters.
+func notificationPage(r *http.Request) (limit int32, before int64, err error) {
+	v := &validator{}
+	limit = defaultNotificationLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		v.check(err == nil && n >= 1 && n <= maxNotificationLimit, "limit", "invalid",
+			"Must be an integer from 1 to "+strconv.Itoa(maxNotificationLimit))
+		limit = int32(n)
+	}
+	if s := r.URL.Query().Get("before"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		v.check(err == nil && n > 0, "before", "invalid", "Must be a notification ID")
+		before = n
+	}
+	return limit, before, v.err()
+}
+
+// handlerNotifications lists the notifications of the user, newest
+// first, one page at a time.
+func (cfg *apiConfig) handlerNotifications(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := r.Context().Value("userID").(int)
+	if !ok {
+		return errors.New("user ID missing from request context")
+	}
+	limit, before, err := notificationPage(r)
+	if err != nil {
+		return err
+	}
+
+	// One more than asked tells whether there is a next page.
+	data, err := cfg.db.ListNotifications(r.Context(), database.ListNotificationsParams{
+		UserID:           int32(userID),
+		Before:           before,
+		MaxNotifications: limit + 1,
+	})
+	if err != nil {
+		return fmt.Errorf("listing notifications: %w", err)
+	}
+	unread, err := cfg.db.CountUnreadNotifications(r.Context(), int32(userID))
+	if err != nil {
+		return fmt.Errorf("counting unread notifications: %w", err)
+	}
+
+	res := notificationsResponse{UnreadCount: unread, Notifications: []notificationResponse{}}
+	if len(data) > int(limit) {
+		data = data[:limit]
+		res.NextBefore = data[len(data)-1].ID
+	}
+	for _, d := range data {
+		n := notificationResponse{
+			ID:        d.ID,
+			Kind:      d.Kind,
+			Title:     d.Title,
+			Body:      d.Body,
+			Data:      d.Data,
+			CreatedAt: d.CreatedAt,
+		}
+		if d.ReadAt.Valid {
+			n.ReadAt = &d.ReadAt.Time
+		}
+		res.Notifications = append(res.Notifications, n)
+	}
+	return respondWithJSON(w, res, http.StatusOK)
+}
+
+type markReadPayload struct {
+	// IDs are the notifications to mark as read.
+	IDs []int64 `json:"ids,omitempty"`
+	// All marks every notification as read instead.
+	All bool `json:"all,omitempty"`
+}
+
+func (p *markReadPayload) validate(v *validator) {
+	v.check(len(p.IDs) > 0 || p.All, "ids", "required", "Is required unless all is set")
+	v.check(len(p.IDs) == 0 || !p.All, "all", "invalid", "Cannot be set along with ids")
+	v.check(len(p.IDs) <= maxNotificationLimit, "ids", "too_long",
+		"Must hold at most "+strconv.Itoa(maxNotificationLimit)+" IDs")
+}
+
+type markReadResponse struct {
+	// Marked is how many notifications were unread before.
+	Marked      int64 `json:"marked"`
+	UnreadCount int64 `json:"unread_count"`
+}
+
+// handlerMarkNotificationsRead marks notifications of the user as read.
+// IDs of other users' notifications or of read ones are ignored.
+func (cfg *apiConfig) handlerMarkNotificationsRead(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := r.Context().Value("userID").(int)
+	if !ok {
+		return errors.New("user ID missing from request context")
+	}
+	payload := markReadPayload{}
+	if err := decodeJSON(w, r, &payload); err != nil {
+		return err
+	}
+
+	res := markReadResponse{}
+	readAt := pgtype.Timestamp{Time: time.Now(), Valid: true}
+	var err error
+	if payload.All {
+		res.Marked, err = cfg.db.MarkAllNotificationsRead(r.Context(), database.MarkAllNotificationsReadParams{
+			UserID: int32(userID),
+			ReadAt: readAt,
+		})
+	} else {
+		res.Marked, err = cfg.db.MarkNotificationsRead(r.Context(), database.MarkNotificationsReadParams{
+			UserID: int32(userID),
+			Ids:    payload.IDs,
+			ReadAt: readAt,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("marking notifications read: %w", err)
+	}
+	res.UnreadCount, err = cfg.db.CountUnreadNotifications(r.Context(), int32(userID))
+	if err != nil {
+		return fmt.Errorf("counting unread notifications: %w", err)
+	}
+	return respondWithJSON(w, res, http.StatusOK)
+}
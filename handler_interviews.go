@@ -0,0 +1,631 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+	"github.com/Vikuuu/synlabs-assignment/internal/ical"
+)
+
+type interviewPayload struct {
+	StartsAt        time.Time `json:"starts_at"`
+	DurationMinutes int       `json:"duration_minutes"`
+	InterviewerIDs  []int32   `json:"interviewer_ids"`
+	Location        string    `json:"location"`
+	VideoURL        string    `json:"video_url"`
+}
+
+func (p *interviewPayload) validate(v *validator) {
+	// Timestamps are stored without a zone, in UTC.
+	p.StartsAt = p.StartsAt.UTC()
+	p.Location = strings.TrimSpace(p.Location)
+	p.VideoURL = strings.TrimSpace(p.VideoURL)
+
+	v.check(!p.StartsAt.IsZero(), "starts_at", "required", "Is required")
+	v.check(p.StartsAt.IsZero() || p.StartsAt.After(time.Now()), "starts_at", "in_past", "Must be in the future")
+	v.check(p.DurationMinutes >= 15 && p.DurationMinutes <= 480,
+		"duration_minutes", "out_of_range", "Must be between 15 and 480")
+
+	v.check(len(p.InterviewerIDs) > 0, "interviewer_ids", "required", "Is required")
+	v.check(len(p.InterviewerIDs) <= 10, "interviewer_ids", "too_many", "Must have at most 10 interviewers")
+	for i, id := range p.InterviewerIDs {
+		if slices.Contains(p.InterviewerIDs[:i], id) {
+			v.add("interviewer_ids", "duplicate", "Must not repeat an interviewer")
+		}
+	}
+
+	v.check(p.Location != "" || p.VideoURL != "", "location", "required", "Location or video_url is required")
+	v.maxLen("location", p.Location, 200)
+	if p.VideoURL != "" {
+		u, err := url.Parse(p.VideoURL)
+		v.check(
+			err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"video_url", "invalid_format", "Must be an absolute http or https URL",
+		)
+	}
+	v.maxLen("video_url", p.VideoURL, 2000)
+}
+
+func (p *interviewPayload) endsAt() time.Time {
+	return p.StartsAt.Add(time.Duration(p.DurationMinutes) * time.Minute)
+}
+
+type interviewerResponse struct {
+	ID    int32  `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type interviewResponse struct {
+	ID              int32                    `json:"id"`
+	ApplicationID   int32                    `json:"application_id"`
+	ApplicantID     int32                    `json:"applicant_id"`
+	JobID           int32                    `json:"job_id"`
+	JobTitle        string                   `json:"job_title"`
+	StartsAt        time.Time                `json:"starts_at"`
+	EndsAt          time.Time                `json:"ends_at"`
+	DurationMinutes int                      `json:"duration_minutes"`
+	Location        string                   `json:"location,omitempty"`
+	VideoURL        string                   `json:"video_url,omitempty"`
+	Interviewers    []interviewerResponse    `json:"interviewers"`
+	Status          database.InterviewStatus `json:"status"`
+	RescheduleNote  string                   `json:"reschedule_note,omitempty"`
+	Sequence        int32                    `json:"sequence"`
+	CreatedAt       time.Time                `json:"created_at"`
+	UpdatedAt       time.Time                `json:"updated_at"`
+}
+
+func (cfg *apiConfig) newInterviewResponse(ctx context.Context, i database.Interview, applicantID, jobID int32) (interviewResponse, error) {
+	job, err := cfg.db.GetJob(ctx, jobID)
+	if err != nil {
+		return interviewResponse{}, fmt.Errorf("getting job: %w", err)
+	}
+	rows, err := cfg.db.ListInterviewers(ctx, i.ID)
+	if err != nil {
+		return interviewResponse{}, fmt.Errorf("listing interviewers: %w", err)
+	}
+	interviewers := make([]interviewerResponse, 0, len(rows))
+	for _, row := range rows {
+		interviewers = append(interviewers, interviewerResponse{ID: row.ID, Name: row.Name, Email: row.Email})
+	}
+	return interviewResponse{
+		ID:              i.ID,
+		ApplicationID:   i.ApplicationID,
+		ApplicantID:     applicantID,
+		JobID:           jobID,
+		JobTitle:        job.Title,
+		StartsAt:        i.StartsAt,
+		EndsAt:          i.EndsAt,
+		DurationMinutes: int(i.EndsAt.Sub(i.StartsAt) / time.Minute),
+		Location:        i.Location,
+		VideoURL:        i.VideoUrl,
+		Interviewers:    interviewers,
+		Status:          i.Status,
+		RescheduleNote:  i.RescheduleNote,
+		Sequence:        i.Sequence,
+		CreatedAt:       i.CreatedAt,
+		UpdatedAt:       i.UpdatedAt,
+	}, nil
+}
+
+func newInterviewEvent(i database.Interview, applicantID, jobID int32, interviewerIDs []int32) interviewEvent {
+	return interviewEvent{
+		ID:             i.ID,
+		ApplicationID:  i.ApplicationID,
+		ApplicantID:    applicantID,
+		JobID:          jobID,
+		StartsAt:       i.StartsAt,
+		EndsAt:         i.EndsAt,
+		Location:       i.Location,
+		VideoURL:       i.VideoUrl,
+		InterviewerIDs: interviewerIDs,
+		Status:         i.Status,
+		RescheduleNote: i.RescheduleNote,
+		Sequence:       i.Sequence,
+	}
+}
+
+func interviewIDParam(r *http.Request) (int32, error) {
+	id, err := strconv.Atoi(r.PathValue("interview_id"))
+	if err != nil {
+		return 0, newValidationError(fieldError{
+			Field:   "interview_id",
+			Code:    "invalid",
+			Message: "Interview ID must be an integer",
+		})
+	}
+	return int32(id), nil
+}
+
+func getInterview(ctx context.Context, q database.Querier, id int32) (database.GetInterviewRow, error) {
+	row, err := q.GetInterview(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return row, newAPIError(codeInterviewNotFound, "No interview exists with this ID", nil)
+	}
+	if err != nil {
+		return row, fmt.Errorf("getting interview: %w", err)
+	}
+	return row, nil
+}
+
+// setInterviewers checks that every interviewer is an admin and free
+// during the slot, then makes them the interviewers of the interview.
+func setInterviewers(ctx context.Context, q database.Querier, interviewID int32, p interviewPayload) error {
+	for _, id := range p.InterviewerIDs {
+		userType, err := q.GetUserFromID(ctx, id)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("getting interviewer: %w", err)
+		}
+		if err != nil || userType != database.UserTypeAdmin {
+			return newValidationError(fieldError{
+				Field:   "interviewer_ids",
+				Code:    "invalid",
+				Message: fmt.Sprintf("User %d is not an admin", id),
+			})
+		}
+	}
+
+	conflicts, err := q.FindInterviewConflicts(ctx, database.FindInterviewConflictsParams{
+		InterviewerIds: p.InterviewerIDs,
+		ExcludeID:      interviewID,
+		StartsAt:       p.StartsAt,
+		EndsAt:         p.endsAt(),
+	})
+	if err != nil {
+		return fmt.Errorf("finding conflicts: %w", err)
+	}
+	if len(conflicts) > 0 {
+		c := conflicts[0]
+		return newAPIError(codeInterviewConflict, fmt.Sprintf(
+			"Interviewer %d already has interview %d from %s to %s",
+			c.UserID, c.ID, c.StartsAt.Format(time.RFC3339), c.EndsAt.Format(time.RFC3339),
+		), nil)
+	}
+
+	if err := q.RemoveInterviewers(ctx, interviewID); err != nil {
+		return fmt.Errorf("removing interviewers: %w", err)
+	}
+	for _, id := range p.InterviewerIDs {
+		err := q.AddInterviewer(ctx, database.AddInterviewerParams{InterviewID: interviewID, UserID: id})
+		if err != nil {
+			return fmt.Errorf("adding interviewer: %w", err)
+		}
+	}
+	return nil
+}
+
+func (cfg *apiConfig) handlerCreateInterview(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := r.Context().Value("userID").(int)
+	if !ok {
+		return errors.New("user ID missing from request context")
+	}
+	appID, err := strconv.Atoi(r.PathValue("application_id"))
+	if err != nil {
+		return newValidationError(fieldError{
+			Field:   "application_id",
+			Code:    "invalid",
+			Message: "Application ID must be an integer",
+		})
+	}
+	payload := interviewPayload{}
+	if err := decodeJSON(w, r, &payload); err != nil {
+		return err
+	}
+
+	ctx := r.Context()
+	var app database.GetApplicationRow
+	var interview database.Interview
+	err = cfg.db.WithTx(ctx, func(q database.Querier) error {
+		var err error
+		app, err = q.GetApplication(ctx, int32(appID))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return newAPIError(codeApplicationNotFound, "No application exists with this ID", nil)
+			}
+			return fmt.Errorf("getting application: %w", err)
+		}
+
+		now := time.Now()
+		interview, err = q.CreateInterview(ctx, database.CreateInterviewParams{
+			ApplicationID: app.ID,
+			StartsAt:      payload.StartsAt,
+			EndsAt:        payload.endsAt(),
+			Location:      payload.Location,
+			VideoUrl:      payload.VideoURL,
+			CreatedBy:     int32(userID),
+			CreatedAt:     now,
+		})
+		if err != nil {
+			return fmt.Errorf("creating interview: %w", err)
+		}
+		if err := setInterviewers(ctx, q, interview.ID, payload); err != nil {
+			return err
+		}
+		return publish(ctx, q, eventInterviewScheduled,
+			newInterviewEvent(interview, app.ApplicantID.Int32, app.JobID.Int32, payload.InterviewerIDs))
+	})
+	if err != nil {
+		return err
+	}
+
+	res, err := cfg.newInterviewResponse(ctx, interview, app.ApplicantID.Int32, app.JobID.Int32)
+	if err != nil {
+		return err
+	}
+	return respondWithJSON(w, res, http.StatusCreated)
+}
+
+func (cfg *apiConfig) handlerApplicationInterviews(w http.ResponseWriter, r *http.Request) error {
+	appID, err := strconv.Atoi(r.PathValue("application_id"))
+	if err != nil {
+		return newValidationError(fieldError{
+			Field:   "application_id",
+			Code:    "invalid",
+			Message: "Application ID must be an integer",
+		})
+	}
+
+	ctx := r.Context()
+	app, err := cfg.db.GetApplication(ctx, int32(appID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return newAPIError(codeApplicationNotFound, "No application exists with this ID", nil)
+		}
+		return fmt.Errorf("getting application: %w", err)
+	}
+	interviews, err := cfg.db.ListInterviewsForApplication(ctx, app.ID)
+	if err != nil {
+		return fmt.Errorf("listing interviews: %w", err)
+	}
+
+	res := make([]interviewResponse, 0, len(interviews))
+	for _, i := range interviews {
+		item, err := cfg.newInterviewResponse(ctx, i, app.ApplicantID.Int32, app.JobID.Int32)
+		if err != nil {
+			return err
+		}
+		res = append(res, item)
+	}
+	return respondWithJSON(w, res, http.StatusOK)
+}
+
+// handlerRescheduleInterview moves an interview or changes its
+// interviewers or place. The applicant has to confirm it again.
+func (cfg *apiConfig) handlerRescheduleInterview(w http.ResponseWriter, r *http.Request) error {
+	id, err := interviewIDParam(r)
+	if err != nil {
+		return err
+	}
+	payload := interviewPayload{}
+	if err := decodeJSON(w, r, &payload); err != nil {
+		return err
+	}
+
+	ctx := r.Context()
+	var row database.GetInterviewRow
+	err = cfg.db.WithTx(ctx, func(q database.Querier) error {
+		var err error
+		row, err = getInterview(ctx, q, id)
+		if err != nil {
+			return err
+		}
+		if row.Interview.Status == database.InterviewStatusCancelled {
+			return newAPIError(codeInterviewCancelled, "A cancelled interview cannot be rescheduled", nil)
+		}
+		if err := setInterviewers(ctx, q, id, payload); err != nil {
+			return err
+		}
+
+		previous := row.Interview.Status
+		row.Interview, err = q.RescheduleInterview(ctx, database.RescheduleInterviewParams{
+			ID:        id,
+			StartsAt:  payload.StartsAt,
+			EndsAt:    payload.endsAt(),
+			Location:  payload.Location,
+			VideoUrl:  payload.VideoURL,
+			UpdatedAt: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("rescheduling interview: %w", err)
+		}
+		data := newInterviewEvent(row.Interview, row.ApplicantID.Int32, row.JobID.Int32, payload.InterviewerIDs)
+		data.PreviousStatus = previous
+		return publish(ctx, q, eventInterviewScheduled, data)
+	})
+	if err != nil {
+		return err
+	}
+
+	res, err := cfg.newInterviewResponse(ctx, row.Interview, row.ApplicantID.Int32, row.JobID.Int32)
+	if err != nil {
+		return err
+	}
+	return respondWithJSON(w, res, http.StatusOK)
+}
+
+// setInterviewStatus moves an interview found by find to status and
+// publishes interview.status_changed. Setting the current status again
+// is a no-op, except that a new reschedule note replaces the old one.
+func (cfg *apiConfig) setInterviewStatus(ctx context.Context, find func(q database.Querier) (database.GetInterviewRow, error), status database.InterviewStatus, note string) (database.GetInterviewRow, error) {
+	var row database.GetInterviewRow
+	err := cfg.db.WithTx(ctx, func(q database.Querier) error {
+		var err error
+		row, err = find(q)
+		if err != nil {
+			return err
+		}
+		previous := row.Interview.Status
+		if previous == database.InterviewStatusCancelled {
+			return newAPIError(codeInterviewCancelled, "The interview was cancelled", nil)
+		}
+		if previous == status && row.Interview.RescheduleNote == note {
+			return nil
+		}
+
+		row.Interview, err = q.UpdateInterviewStatus(ctx, database.UpdateInterviewStatusParams{
+			ID:             row.Interview.ID,
+			Status:         status,
+			RescheduleNote: note,
+			UpdatedAt:      time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("updating interview status: %w", err)
+		}
+		interviewers, err := q.ListInterviewers(ctx, row.Interview.ID)
+		if err != nil {
+			return fmt.Errorf("listing interviewers: %w", err)
+		}
+		ids := make([]int32, 0, len(interviewers))
+		for _, i := range interviewers {
+			ids = append(ids, i.ID)
+		}
+		data := newInterviewEvent(row.Interview, row.ApplicantID.Int32, row.JobID.Int32, ids)
+		data.PreviousStatus = previous
+		return publish(ctx, q, eventInterviewStatusChanged, data)
+	})
+	return row, err
+}
+
+func (cfg *apiConfig) handlerCancelInterview(w http.ResponseWriter, r *http.Request) error {
+	id, err := interviewIDParam(r)
+	if err != nil {
+		return err
+	}
+
+	ctx := r.Context()
+	row, err := cfg.setInterviewStatus(ctx, func(q database.Querier) (database.GetInterviewRow, error) {
+		return getInterview(ctx, q, id)
+	}, database.InterviewStatusCancelled, "")
+	if err != nil {
+		return err
+	}
+
+	res, err := cfg.newInterviewResponse(ctx, row.Interview, row.ApplicantID.Int32, row.JobID.Int32)
+	if err != nil {
+		return err
+	}
+	return respondWithJSON(w, res, http.StatusOK)
+}
+
+func (cfg *apiConfig) handlerInterviewInvite(w http.ResponseWriter, r *http.Request) error {
+	id, err := interviewIDParam(r)
+	if err != nil {
+		return err
+	}
+	row, err := getInterview(r.Context(), cfg.db, id)
+	if err != nil {
+		return err
+	}
+	return cfg.respondWithInvite(w, r.Context(), row)
+}
+
+// getApplicantInterview finds an interview for one of the applications
+// of an applicant. Those of other applicants are not found.
+func getApplicantInterview(ctx context.Context, q database.Querier, applicantID int, id int32) (database.GetInterviewRow, error) {
+	row, err := getInterview(ctx, q, id)
+	if err != nil {
+		return row, err
+	}
+	if row.ApplicantID.Int32 != int32(applicantID) {
+		return row, newAPIError(codeInterviewNotFound, "No interview exists with this ID", nil)
+	}
+	return row, nil
+}
+
+func (cfg *apiConfig) handlerApplicantInterviews(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := r.Context().Value("userID").(int)
+	if !ok {
+		return errors.New("user ID missing from request context")
+	}
+
+	ctx := r.Context()
+	interviews, err := cfg.db.ListInterviewsForApplicant(ctx, pgtype.Int4{Int32: int32(userID), Valid: true})
+	if err != nil {
+		return fmt.Errorf("listing interviews: %w", err)
+	}
+
+	res := make([]interviewResponse, 0, len(interviews))
+	for _, i := range interviews {
+		item, err := cfg.newInterviewResponse(ctx, i.Interview, int32(userID), i.JobID.Int32)
+		if err != nil {
+			return err
+		}
+		res = append(res, item)
+	}
+	return respondWithJSON(w, res, http.StatusOK)
+}
+
+func (cfg *apiConfig) handlerApplicantInterviewInvite(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := r.Context().Value("userID").(int)
+	if !ok {
+		return errors.New("user ID missing from request context")
+	}
+	id, err := interviewIDParam(r)
+	if err != nil {
+		return err
+	}
+	row, err := getApplicantInterview(r.Context(), cfg.db, userID, id)
+	if err != nil {
+		return err
+	}
+	return cfg.respondWithInvite(w, r.Context(), row)
+}
+
+func (cfg *apiConfig) handlerConfirmInterview(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := r.Context().Value("userID").(int)
+	if !ok {
+		return errors.New("user ID missing from request context")
+	}
+	id, err := interviewIDParam(r)
+	if err != nil {
+		return err
+	}
+
+	ctx := r.Context()
+	row, err := cfg.setInterviewStatus(ctx, func(q database.Querier) (database.GetInterviewRow, error) {
+		return getApplicantInterview(ctx, q, userID, id)
+	}, database.InterviewStatusConfirmed, "")
+	if err != nil {
+		return err
+	}
+
+	res, err := cfg.newInterviewResponse(ctx, row.Interview, row.ApplicantID.Int32, row.JobID.Int32)
+	if err != nil {
+		return err
+	}
+	return respondWithJSON(w, res, http.StatusOK)
+}
+
+type rescheduleRequestPayload struct {
+	Note string `json:"note"`
+}
+
+func (p *rescheduleRequestPayload) validate(v *validator) {
+	p.Note = strings.TrimSpace(p.Note)
+
+	v.required("note", p.Note)
+	v.maxLen("note", p.Note, 1000)
+}
+
+// handlerRequestReschedule asks the interviewers for another slot. The
+// interview stays where it is until an admin moves it.
+func (cfg *apiConfig) handlerRequestReschedule(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := r.Context().Value("userID").(int)
+	if !ok {
+		return errors.New("user ID missing from request context")
+	}
+	id, err := interviewIDParam(r)
+	if err != nil {
+		return err
+	}
+	payload := rescheduleRequestPayload{}
+	if err := decodeJSON(w, r, &payload); err != nil {
+		return err
+	}
+
+	ctx := r.Context()
+	row, err := cfg.setInterviewStatus(ctx, func(q database.Querier) (database.GetInterviewRow, error) {
+		return getApplicantInterview(ctx, q, userID, id)
+	}, database.InterviewStatusRescheduleRequested, payload.Note)
+	if err != nil {
+		return err
+	}
+
+	res, err := cfg.newInterviewResponse(ctx, row.Interview, row.ApplicantID.Int32, row.JobID.Int32)
+	if err != nil {
+		return err
+	}
+	return respondWithJSON(w, res, http.StatusOK)
+}
+
+func (cfg *apiConfig) respondWithInvite(w http.ResponseWriter, ctx context.Context, row database.GetInterviewRow) error {
+	method, invite, err := cfg.interviewInvite(ctx, row)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8; method="+method)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="interview-%d.ics"`, row.Interview.ID))
+	w.WriteHeader(http.StatusOK)
+	w.Write(invite)
+	return nil
+}
+
+// interviewInvite returns the iCalendar invite for an interview, from
+// the admin that scheduled it to the applicant and the interviewers. A
+// cancelled interview gives an invite that cancels it.
+func (cfg *apiConfig) interviewInvite(ctx context.Context, row database.GetInterviewRow) (string, []byte, error) {
+	i := row.Interview
+	job, err := cfg.db.GetJob(ctx, row.JobID.Int32)
+	if err != nil {
+		return "", nil, fmt.Errorf("getting job: %w", err)
+	}
+	organizer, err := cfg.db.GetNotificationRecipient(ctx, i.CreatedBy)
+	if err != nil {
+		return "", nil, fmt.Errorf("getting organizer: %w", err)
+	}
+	applicant, err := cfg.db.GetNotificationRecipient(ctx, row.ApplicantID.Int32)
+	if err != nil {
+		return "", nil, fmt.Errorf("getting applicant: %w", err)
+	}
+	interviewers, err := cfg.db.ListInterviewers(ctx, i.ID)
+	if err != nil {
+		return "", nil, fmt.Errorf("listing interviewers: %w", err)
+	}
+
+	method, status, partStat := ical.MethodRequest, ical.StatusTentative, ical.PartStatNeedsAction
+	switch i.Status {
+	case database.InterviewStatusConfirmed:
+		status, partStat = ical.StatusConfirmed, ical.PartStatAccepted
+	case database.InterviewStatusCancelled:
+		method, status = ical.MethodCancel, ical.StatusCancelled
+	}
+	attendees := []ical.Attendee{{Person: ical.Person{Name: applicant.Name, Email: applicant.Email}, PartStat: partStat}}
+	for _, u := range interviewers {
+		attendees = append(attendees, ical.Attendee{
+			Person:   ical.Person{Name: u.Name, Email: u.Email},
+			PartStat: ical.PartStatAccepted,
+		})
+	}
+
+	description := fmt.Sprintf("Interview for %s at %s.", job.Title, job.CompanyName)
+	if i.VideoUrl != "" {
+		description += "\nJoin: " + i.VideoUrl
+	}
+	location := i.Location
+	if location == "" {
+		location = i.VideoUrl
+	}
+	return method, ical.Encode(method, ical.Event{
+		UID:         fmt.Sprintf("interview-%d@%s", i.ID, cfg.inviteDomain()),
+		Sequence:    int(i.Sequence),
+		Stamp:       i.UpdatedAt,
+		Start:       i.StartsAt,
+		End:         i.EndsAt,
+		Summary:     fmt.Sprintf("Interview: %s, %s", job.Title, job.CompanyName),
+		Description: description,
+		Location:    location,
+		URL:         i.VideoUrl,
+		Status:      status,
+		Organizer:   ical.Person{Name: organizer.Name, Email: organizer.Email},
+		Attendees:   attendees,
+	}), nil
+}
+
+// inviteDomain makes the UIDs of invites unique to this deployment.
+func (cfg *apiConfig) inviteDomain() string {
+	if u, err := url.Parse(cfg.mailBaseURL); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return "localhost"
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/auth"
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+)
+
+type notificationPreferences struct {
+	// ApplicationUpdates sends applicants an email when they apply and
+	// whenever their application changes status. Interview invites are
+	// sent regardless.
+	ApplicationUpdates bool `json:"application_updates"`
+	// ApplicantDigest sends admins a periodic digest of new applicants
+	// to their jobs.
+	ApplicantDigest bool `json:"applicant_digest"`
+}
+
+// notificationPreferencesPayload replaces every preference, so none may
+// be left out.
+type notificationPreferencesPayload struct {
+	ApplicationUpdates *bool `json:"application_updates"`
+	ApplicantDigest    *bool `json:"applicant_digest"`
+}
+
+func (p *notificationPreferencesPayload) validate(v *validator) {
+	v.check(p.ApplicationUpdates != nil, "application_updates", "required", "Is required")
+	v.check(p.ApplicantDigest != nil, "applicant_digest", "required", "Is required")
+}
+
+func (cfg *apiConfig) handlerNotificationPreferences(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := r.Context().Value("userID").(int)
+	if !ok {
+		return errors.New("user ID missing from request context")
+	}
+
+	data, err := cfg.db.GetNotificationRecipient(r.Context(), int32(userID))
+	if err != nil {
+		return fmt.Errorf("getting notification preferences: %w", err)
+	}
+	return respondWithJSON(w, notificationPreferences{
+		ApplicationUpdates: data.ApplicationUpdates,
+		ApplicantDigest:    data.ApplicantDigest,
+	}, http.StatusOK)
+}
+
+func (cfg *apiConfig) handlerSetNotificationPreferences(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := r.Context().Value("userID").(int)
+	if !ok {
+		return errors.New("user ID missing from request context")
+	}
+
+	payload := notificationPreferencesPayload{}
+	if err := decodeJSON(w, r, &payload); err != nil {
+		return err
+	}
+
+	err := cfg.db.SetNotificationPreferences(r.Context(), database.SetNotificationPreferencesParams{
+		UserID:             int32(userID),
+		ApplicationUpdates: *payload.ApplicationUpdates,
+		ApplicantDigest:    *payload.ApplicantDigest,
+	})
+	if err != nil {
+		return fmt.Errorf("setting notification preferences: %w", err)
+	}
+	return respondWithJSON(w, notificationPreferences{
+		ApplicationUpdates: *payload.ApplicationUpdates,
+		ApplicantDigest:    *payload.ApplicantDigest,
+	}, http.StatusOK)
+}
+
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Unsubscribe</title></head>
+<body style="font-family: -apple-system, Helvetica, Arial, sans-serif; max-width: 600px; margin: 32px auto">
+{{if .Done}}
+<p>You will no longer get {{.List}} emails. You can turn them back on in your notification preferences.</p>
+{{else}}
+<p>Stop getting {{.List}} emails?</p>
+<form method="post"><button type="submit">Unsubscribe</button></form>
+{{end}}
+</body>
+</html>
+`))
+
+var listNames = map[string]string{
+	listApplicationUpdates: "application update",
+	listApplicantDigest:    "new applicant digest",
+}
+
+// unsubscribeToken returns the user and list of the token query
+// parameter.
+func (cfg *apiConfig) unsubscribeToken(r *http.Request) (int32, string, error) {
+	userID, list, err := auth.ParseUnsubscribeToken(r.URL.Query().Get("token"), cfg.secret)
+	if err == nil && listNames[list] == "" {
+		err = auth.ErrInvalidUnsubscribeToken
+	}
+	if err != nil {
+		return 0, "", newValidationError(fieldError{
+			Field:   "token",
+			Code:    "invalid",
+			Message: "Unsubscribe link is invalid",
+		})
+	}
+	return userID, list, nil
+}
+
+func renderUnsubscribePage(w http.ResponseWriter, list string, done bool) error {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	return unsubscribePage.Execute(w, struct {
+		List string
+		Done bool
+	}{listNames[list], done})
+}
+
+// handlerUnsubscribePage asks to confirm, since mail scanners follow the
+// links in emails.
+func (cfg *apiConfig) handlerUnsubscribePage(w http.ResponseWriter, r *http.Request) error {
+	_, list, err := cfg.unsubscribeToken(r)
+	if err != nil {
+		return err
+	}
+	return renderUnsubscribePage(w, list, false)
+}
+
+// handlerUnsubscribe turns off the list of the token. Mail clients also
+// post here for one-click unsubscribes (RFC 8058).
+func (cfg *apiConfig) handlerUnsubscribe(w http.ResponseWriter, r *http.Request) error {
+	userID, list, err := cfg.unsubscribeToken(r)
+	if err != nil {
+		return err
+	}
+
+	err = cfg.db.WithTx(r.Context(), func(q database.Querier) error {
+		prefs, err := q.GetNotificationRecipient(r.Context(), userID)
+		if err != nil {
+			return err
+		}
+		params := database.SetNotificationPreferencesParams{
+			UserID:             userID,
+			ApplicationUpdates: prefs.ApplicationUpdates && list != listApplicationUpdates,
+			ApplicantDigest:    prefs.ApplicantDigest && list != listApplicantDigest,
+		}
+		return q.SetNotificationPreferences(r.Context(), params)
+	})
+	// A deleted user gets no more emails either.
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("unsubscribing: %w", err)
+	}
+	return renderUnsubscribePage(w, list, true)
+}
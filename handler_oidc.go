@@ -0,0 +1,191 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/auth"
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+	"github.com/Vikuuu/synlabs-assignment/internal/oidc"
+)
+
+const oidcSessionCookie = "oidc_session"
+
+// oidcCookiePath returns the directory of the callback in redirectURL,
+// so the session cookie is sent to the callback and nowhere else.
+func oidcCookiePath(redirectURL string) string {
+	u, err := url.Parse(redirectURL)
+	if err != nil || u.Path == "" {
+		return "/"
+	}
+	return path.Dir(u.Path)
+}
+
+func (cfg *apiConfig) handlerOIDCLogin(w http.ResponseWriter, r *http.Request) error {
+	session, err := oidc.NewSession()
+	if err != nil {
+		return fmt.Errorf("creating oidc session: %w", err)
+	}
+
+	authURL, err := cfg.oidc.AuthCodeURL(r.Context(), session.State, session.Nonce, session.Verifier)
+	if err != nil {
+		return newAPIError(codeUpstreamFailed, "Identity provider unavailable", err)
+	}
+
+	value, err := session.Encode(cfg.secret, 10*time.Minute)
+	if err != nil {
+		return fmt.Errorf("encoding oidc session: %w", err)
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcSessionCookie,
+		Value:    value,
+		Path:     cfg.oidcCookiePath,
+		MaxAge:   int((10 * time.Minute).Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+	return nil
+}
+
+func (cfg *apiConfig) handlerOIDCCallback(w http.ResponseWriter, r *http.Request) error {
+	q := r.URL.Query()
+	if errCode := q.Get("error"); errCode != "" {
+		return newAPIError(
+			codeInvalidCredentials,
+			"Login was not completed",
+			fmt.Errorf("identity provider returned %s: %s", errCode, q.Get("error_description")),
+		)
+	}
+
+	cookie, err := r.Cookie(oidcSessionCookie)
+	if err != nil {
+		return newAPIError(codeLoginSession, "Login session expired", nil)
+	}
+	session, err := oidc.DecodeSession(cookie.Value, cfg.secret)
+	if err != nil {
+		return newAPIError(codeLoginSession, "Login session expired", err)
+	}
+	if q.Get("state") == "" || q.Get("state") != session.State {
+		return newAPIError(codeLoginSession, "Invalid login state", nil)
+	}
+
+	// The session is single use, drop it whatever the outcome.
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcSessionCookie,
+		Path:     cfg.oidcCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+
+	claims, err := cfg.oidc.Exchange(r.Context(), q.Get("code"), session.Verifier, session.Nonce)
+	if err != nil {
+		cfg.metrics.Login("oidc", false)
+		return newAPIError(codeInvalidCredentials, "Invalid credentials", err)
+	}
+	// Without the provider vouching for the email, the login could be
+	// for someone else's account.
+	if claims.Email == "" || claims.EmailVerified == nil || !*claims.EmailVerified {
+		return newAPIError(codeForbidden, "A verified email is required", nil)
+	}
+
+	userID, userType, err := cfg.provisionOIDCUser(r, claims)
+	if err != nil {
+		return err
+	}
+	if userType != database.UserTypeAdmin {
+		return newAPIError(
+			codeForbidden,
+			"Single sign-on is only available to admins",
+			nil,
+		)
+	}
+
+	jwtToken, err := auth.MakeJWT(userID, cfg.secret, time.Hour)
+	if err != nil {
+		return fmt.Errorf("creating JWT: %w", err)
+	}
+
+	cfg.metrics.Login("oidc", true)
+	return respondWithJSON(w, loginResponse{
+		AccessToken: jwtToken,
+		UserType:    userType,
+	}, http.StatusOK)
+}
+
+// provisionOIDCUser returns the account for the verified email or, when
+// there is none, creates an admin on the fly. Only emails in the domains
+// mapped to admins are linked or provisioned: elsewhere an existing
+// account is a conflict, and a new user comes back with an empty user
+// type.
+func (cfg *apiConfig) provisionOIDCUser(r *http.Request, claims *oidc.Claims) (int32, database.UserType, error) {
+	email := strings.ToLower(claims.Email)
+	adminDomain := cfg.isAdminDomain(email)
+
+	user, err := cfg.db.GetUser(r.Context(), email)
+	if err == nil {
+		if !adminDomain {
+			return 0, "", newAPIError(codeEmailTaken, "An account with this email already exists", nil)
+		}
+		return user.ID, user.UserType, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, "", fmt.Errorf("getting user: %w", err)
+	}
+
+	if !adminDomain {
+		return 0, "", nil
+	}
+
+	// SSO users never log in with a password, store a hash nobody knows.
+	password, err := oidc.RandomString()
+	if err != nil {
+		return 0, "", err
+	}
+	hashPassword, err := auth.HashPassword(password)
+	if err != nil {
+		return 0, "", fmt.Errorf("hashing password: %w", err)
+	}
+
+	name := claims.Name
+	if name == "" {
+		name = email
+	}
+	dat, err := cfg.db.CreateUser(r.Context(), database.CreateUserParams{
+		Name:            name,
+		Email:           email,
+		Address:         "",
+		UserType:        database.UserTypeAdmin,
+		PasswordHash:    hashPassword,
+		ProfileHeadline: "",
+	})
+	if err != nil {
+		return 0, "", fmt.Errorf("creating user: %w", err)
+	}
+	requestLogger(r).Info("provisioned admin from single sign-on", "email", email)
+
+	return dat.ID, dat.UserType, nil
+}
+
+func (cfg *apiConfig) isAdminDomain(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := email[at+1:]
+	for _, d := range cfg.oidcAdminDomains {
+		if strings.EqualFold(d, domain) {
+			return true
+		}
+	}
+	return false
+}
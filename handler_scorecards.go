@@ -0,0 +1,501 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+)
+
+var recommendations = []string{
+	string(database.RecommendationStrongNo),
+	string(database.RecommendationNo),
+	string(database.RecommendationYes),
+	string(database.RecommendationStrongYes),
+}
+
+// scorecardCriterion is rated from 1 to Scale.
+type scorecardCriterion struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Scale       int    `json:"scale"`
+}
+
+type scorecardPayload struct {
+	Criteria []scorecardCriterion `json:"criteria"`
+}
+
+func (p *scorecardPayload) validate(v *validator) {
+	v.check(len(p.Criteria) > 0, "criteria", "required", "Is required")
+	v.check(len(p.Criteria) <= 20, "criteria", "too_many", "Must have at most 20 criteria")
+	seen := map[string]bool{}
+	for i := range p.Criteria {
+		c := &p.Criteria[i]
+		c.Name = strings.TrimSpace(c.Name)
+		c.Description = strings.TrimSpace(c.Description)
+		field := fmt.Sprintf("criteria[%d]", i)
+
+		v.required(field+".name", c.Name)
+		v.maxLen(field+".name", c.Name, 100)
+		v.check(!seen[strings.ToLower(c.Name)], field+".name", "duplicate", "Must not repeat a criterion")
+		seen[strings.ToLower(c.Name)] = true
+		v.maxLen(field+".description", c.Description, 500)
+		v.check(c.Scale >= 2 && c.Scale <= 10, field+".scale", "out_of_range", "Must be between 2 and 10")
+	}
+}
+
+type scorecardResponse struct {
+	JobID     int32                `json:"job_id"`
+	Criteria  []scorecardCriterion `json:"criteria"`
+	UpdatedBy int32                `json:"updated_by"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
+
+func newScorecardResponse(t database.ScorecardTemplate) (scorecardResponse, error) {
+	criteria := []scorecardCriterion{}
+	if err := json.Unmarshal(t.Criteria, &criteria); err != nil {
+		return scorecardResponse{}, fmt.Errorf("decoding scorecard: %w", err)
+	}
+	return scorecardResponse{JobID: t.JobID, Criteria: criteria, UpdatedBy: t.UpdatedBy, UpdatedAt: t.UpdatedAt}, nil
+}
+
+func getScorecard(ctx context.Context, q database.Querier, jobID int32) (scorecardResponse, error) {
+	t, err := q.GetScorecardTemplate(ctx, jobID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return scorecardResponse{}, newAPIError(codeScorecardNotFound, "The job has no scorecard", nil)
+	}
+	if err != nil {
+		return scorecardResponse{}, fmt.Errorf("getting scorecard: %w", err)
+	}
+	return newScorecardResponse(t)
+}
+
+// handlerSetScorecard replaces the scorecard of a job. Feedback already
+// given keeps the criteria it was given on.
+func (cfg *apiConfig) handlerSetScorecard(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := r.Context().Value("userID").(int)
+	if !ok {
+		return errors.New("user ID missing from request context")
+	}
+	jobID, err := strconv.Atoi(r.PathValue("job_id"))
+	if err != nil {
+		return newValidationError(fieldError{
+			Field:   "job_id",
+			Code:    "invalid",
+			Message: "Job ID must be an integer",
+		})
+	}
+	payload := scorecardPayload{}
+	if err := decodeJSON(w, r, &payload); err != nil {
+		return err
+	}
+	criteria, err := json.Marshal(payload.Criteria)
+	if err != nil {
+		return fmt.Errorf("encoding criteria: %w", err)
+	}
+
+	ctx := r.Context()
+	if _, err := cfg.db.GetJob(ctx, int32(jobID)); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return newAPIError(codeJobNotFound, "No job exists with this ID", nil)
+		}
+		return fmt.Errorf("getting job: %w", err)
+	}
+	t, err := cfg.db.SetScorecardTemplate(ctx, database.SetScorecardTemplateParams{
+		JobID:     int32(jobID),
+		Criteria:  criteria,
+		UpdatedBy: int32(userID),
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("setting scorecard: %w", err)
+	}
+
+	res, err := newScorecardResponse(t)
+	if err != nil {
+		return err
+	}
+	return respondWithJSON(w, res, http.StatusOK)
+}
+
+func (cfg *apiConfig) handlerScorecard(w http.ResponseWriter, r *http.Request) error {
+	jobID, err := strconv.Atoi(r.PathValue("job_id"))
+	if err != nil {
+		return newValidationError(fieldError{
+			Field:   "job_id",
+			Code:    "invalid",
+			Message: "Job ID must be an integer",
+		})
+	}
+
+	res, err := getScorecard(r.Context(), cfg.db, int32(jobID))
+	if err != nil {
+		return err
+	}
+	return respondWithJSON(w, res, http.StatusOK)
+}
+
+// feedbackPayload saves the feedback of an admin as a draft, or submits
+// it. Drafts may leave criteria unrated.
+type feedbackPayload struct {
+	// Ratings maps criterion names to ratings.
+	Ratings        map[string]int          `json:"ratings"`
+	Recommendation database.Recommendation `json:"recommendation"`
+	Notes          string                  `json:"notes"`
+	Submit         bool                    `json:"submit"`
+}
+
+func (p *feedbackPayload) validate(v *validator) {
+	p.Recommendation = database.Recommendation(strings.TrimSpace(string(p.Recommendation)))
+	p.Notes = strings.TrimSpace(p.Notes)
+
+	if p.Submit {
+		v.required("recommendation", string(p.Recommendation))
+	}
+	if p.Recommendation != "" {
+		v.oneOf("recommendation", string(p.Recommendation), recommendations...)
+	}
+	v.maxLen("notes", p.Notes, 5000)
+}
+
+// feedbackRating keeps the scale of the criterion it rates, since the
+// scorecard may change later.
+type feedbackRating struct {
+	Criterion string `json:"criterion"`
+	Rating    int    `json:"rating"`
+	Scale     int    `json:"scale"`
+}
+
+// rate checks the ratings against the criteria of the scorecard, and
+// returns them in the order of the criteria.
+func (p *feedbackPayload) rate(criteria []scorecardCriterion) ([]feedbackRating, error) {
+	v := &validator{}
+	ratings := []feedbackRating{}
+	known := map[string]bool{}
+	for _, c := range criteria {
+		known[c.Name] = true
+		field := "ratings." + c.Name
+		rating, ok := p.Ratings[c.Name]
+		if !ok {
+			v.check(!p.Submit, field, "required", "Is required to submit")
+			continue
+		}
+		v.check(rating >= 1 && rating <= c.Scale, field, "out_of_range", fmt.Sprintf("Must be between 1 and %d", c.Scale))
+		ratings = append(ratings, feedbackRating{Criterion: c.Name, Rating: rating, Scale: c.Scale})
+	}
+	for _, name := range slices.Sorted(maps.Keys(p.Ratings)) {
+		if !known[name] {
+			v.add("ratings."+name, "unknown_criterion", "Is not a criterion of the scorecard")
+		}
+	}
+	return ratings, v.err()
+}
+
+type feedbackResponse struct {
+	ID              int32                   `json:"id"`
+	ApplicationID   int32                   `json:"application_id"`
+	InterviewerID   int32                   `json:"interviewer_id"`
+	InterviewerName string                  `json:"interviewer_name"`
+	Ratings         []feedbackRating        `json:"ratings"`
+	Recommendation  database.Recommendation `json:"recommendation,omitempty"`
+	Notes           string                  `json:"notes"`
+	SubmittedAt     *time.Time              `json:"submitted_at,omitempty"`
+	UpdatedAt       time.Time               `json:"updated_at"`
+}
+
+func newFeedbackResponse(f database.ScorecardFeedback, interviewerName string) (feedbackResponse, error) {
+	ratings := []feedbackRating{}
+	if err := json.Unmarshal(f.Ratings, &ratings); err != nil {
+		return feedbackResponse{}, fmt.Errorf("decoding ratings: %w", err)
+	}
+	res := feedbackResponse{
+		ID:              f.ID,
+		ApplicationID:   f.ApplicationID,
+		InterviewerID:   f.InterviewerID,
+		InterviewerName: interviewerName,
+		Ratings:         ratings,
+		Recommendation:  f.Recommendation.Recommendation,
+		Notes:           f.Notes,
+		UpdatedAt:       f.UpdatedAt,
+	}
+	if f.SubmittedAt.Valid {
+		res.SubmittedAt = &f.SubmittedAt.Time
+	}
+	return res, nil
+}
+
+func (cfg *apiConfig) handlerSaveFeedback(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := r.Context().Value("userID").(int)
+	if !ok {
+		return errors.New("user ID missing from request context")
+	}
+	appID, err := strconv.Atoi(r.PathValue("application_id"))
+	if err != nil {
+		return newValidationError(fieldError{
+			Field:   "application_id",
+			Code:    "invalid",
+			Message: "Application ID must be an integer",
+		})
+	}
+	payload := feedbackPayload{}
+	if err := decodeJSON(w, r, &payload); err != nil {
+		return err
+	}
+
+	ctx := r.Context()
+	var saved database.ScorecardFeedback
+	err = cfg.db.WithTx(ctx, func(q database.Querier) error {
+		app, err := q.GetApplication(ctx, int32(appID))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return newAPIError(codeApplicationNotFound, "No application exists with this ID", nil)
+			}
+			return fmt.Errorf("getting application: %w", err)
+		}
+		interviewer, err := q.IsInterviewer(ctx, database.IsInterviewerParams{ApplicationID: app.ID, UserID: int32(userID)})
+		if err != nil {
+			return fmt.Errorf("checking interviewers: %w", err)
+		}
+		if !interviewer {
+			return newAPIError(codeForbidden, "Only interviewers of the application can give feedback on it", nil)
+		}
+		scorecard, err := getScorecard(ctx, q, app.JobID.Int32)
+		if err != nil {
+			return err
+		}
+		ratings, err := payload.rate(scorecard.Criteria)
+		if err != nil {
+			return err
+		}
+		raw, err := json.Marshal(ratings)
+		if err != nil {
+			return fmt.Errorf("encoding ratings: %w", err)
+		}
+
+		now := time.Now()
+		arg := database.SaveFeedbackParams{
+			ApplicationID: app.ID,
+			InterviewerID: int32(userID),
+			Ratings:       raw,
+			Recommendation: database.NullRecommendation{
+				Recommendation: payload.Recommendation,
+				Valid:          payload.Recommendation != "",
+			},
+			Notes: payload.Notes,
+			Now:   now,
+		}
+		if payload.Submit {
+			arg.SubmittedAt = pgtype.Timestamp{Time: now, Valid: true}
+		}
+		// Nothing is saved over submitted feedback.
+		saved, err = q.SaveFeedback(ctx, arg)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return newAPIError(codeFeedbackSubmitted, "Your feedback on this application was already submitted", nil)
+		}
+		if err != nil {
+			return fmt.Errorf("saving feedback: %w", err)
+		}
+		if !payload.Submit {
+			return nil
+		}
+		return publish(ctx, q, eventFeedbackSubmitted, feedbackEvent{
+			ID:            saved.ID,
+			ApplicationID: app.ID,
+			ApplicantID:   app.ApplicantID.Int32,
+			JobID:         app.JobID.Int32,
+			InterviewerID: saved.InterviewerID,
+			SubmittedAt:   saved.SubmittedAt.Time,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	user, err := cfg.db.GetNotificationRecipient(ctx, int32(userID))
+	if err != nil {
+		return fmt.Errorf("getting user: %w", err)
+	}
+	res, err := newFeedbackResponse(saved, user.Name)
+	if err != nil {
+		return err
+	}
+	return respondWithJSON(w, res, http.StatusOK)
+}
+
+type criterionSummary struct {
+	Criterion string  `json:"criterion"`
+	Scale     int     `json:"scale"`
+	Average   float64 `json:"average"`
+	Ratings   int     `json:"ratings"`
+}
+
+// scorecardSummary aggregates the submitted feedback on an application.
+// While Hidden, only the number submitted is given.
+type scorecardSummary struct {
+	ApplicationID int32 `json:"application_id"`
+	Hidden        bool  `json:"hidden"`
+	Submitted     int   `json:"submitted"`
+	// Criteria averages the ratings of each criterion. A criterion whose
+	// scale changed is listed once per scale.
+	Criteria []criterionSummary `json:"criteria"`
+	// Recommendations counts the feedback giving each recommendation.
+	Recommendations map[string]int `json:"recommendations"`
+}
+
+// applicationFeedback is what an admin may see of the feedback on one
+// application.
+type applicationFeedback struct {
+	Mine    *feedbackResponse
+	Others  []feedbackResponse
+	Summary scorecardSummary
+}
+
+// viewFeedback sorts the feedback on an application for an admin. To
+// keep them from being swayed, admins who interview for the application
+// or have started their own feedback see that of others only once they
+// have submitted theirs.
+func viewFeedback(ctx context.Context, q database.Querier, userID, applicationID int32, rows []database.ListFeedbackForApplicationRow) (applicationFeedback, error) {
+	view := applicationFeedback{
+		Others:  []feedbackResponse{},
+		Summary: scorecardSummary{ApplicationID: applicationID, Criteria: []criterionSummary{}, Recommendations: map[string]int{}},
+	}
+	submitted := []feedbackResponse{}
+	for _, row := range rows {
+		f, err := newFeedbackResponse(row.ScorecardFeedback, row.InterviewerName)
+		if err != nil {
+			return view, err
+		}
+		if f.InterviewerID == userID {
+			view.Mine = &f
+		}
+		if f.SubmittedAt != nil {
+			submitted = append(submitted, f)
+		}
+	}
+
+	if view.Mine != nil {
+		view.Summary.Hidden = view.Mine.SubmittedAt == nil
+	} else {
+		interviewer, err := q.IsInterviewer(ctx, database.IsInterviewerParams{ApplicationID: applicationID, UserID: userID})
+		if err != nil {
+			return view, fmt.Errorf("checking interviewers: %w", err)
+		}
+		view.Summary.Hidden = interviewer
+	}
+	view.Summary.Submitted = len(submitted)
+	if view.Summary.Hidden {
+		return view, nil
+	}
+
+	type key struct {
+		criterion string
+		scale     int
+	}
+	index := map[key]int{}
+	totals := []int{}
+	for _, f := range submitted {
+		if f.InterviewerID != userID {
+			view.Others = append(view.Others, f)
+		}
+		view.Summary.Recommendations[string(f.Recommendation)]++
+		for _, r := range f.Ratings {
+			i, ok := index[key{r.Criterion, r.Scale}]
+			if !ok {
+				i = len(totals)
+				index[key{r.Criterion, r.Scale}] = i
+				view.Summary.Criteria = append(view.Summary.Criteria, criterionSummary{Criterion: r.Criterion, Scale: r.Scale})
+				totals = append(totals, 0)
+			}
+			view.Summary.Criteria[i].Ratings++
+			totals[i] += r.Rating
+		}
+	}
+	for i := range view.Summary.Criteria {
+		c := &view.Summary.Criteria[i]
+		c.Average = float64(totals[i]) / float64(c.Ratings)
+	}
+	return view, nil
+}
+
+type applicationFeedbackResponse struct {
+	Mine *feedbackResponse `json:"mine,omitempty"`
+	// Feedback is the submitted feedback of the other admins, empty
+	// while it is hidden.
+	Feedback []feedbackResponse `json:"feedback"`
+	Summary  scorecardSummary   `json:"summary"`
+}
+
+func (cfg *apiConfig) handlerApplicationFeedback(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := r.Context().Value("userID").(int)
+	if !ok {
+		return errors.New("user ID missing from request context")
+	}
+	appID, err := strconv.Atoi(r.PathValue("application_id"))
+	if err != nil {
+		return newValidationError(fieldError{
+			Field:   "application_id",
+			Code:    "invalid",
+			Message: "Application ID must be an integer",
+		})
+	}
+
+	ctx := r.Context()
+	if _, err := cfg.db.GetApplication(ctx, int32(appID)); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return newAPIError(codeApplicationNotFound, "No application exists with this ID", nil)
+		}
+		return fmt.Errorf("getting application: %w", err)
+	}
+	rows, err := cfg.db.ListFeedbackForApplication(ctx, int32(appID))
+	if err != nil {
+		return fmt.Errorf("listing feedback: %w", err)
+	}
+	view, err := viewFeedback(ctx, cfg.db, int32(userID), int32(appID), rows)
+	if err != nil {
+		return err
+	}
+	return respondWithJSON(w, applicationFeedbackResponse{
+		Mine:     view.Mine,
+		Feedback: view.Others,
+		Summary:  view.Summary,
+	}, http.StatusOK)
+}
+
+// applicantScorecards summarizes the feedback on each application of an
+// applicant that has any, as an admin may see it.
+func (cfg *apiConfig) applicantScorecards(ctx context.Context, userID, applicantID int32) ([]scorecardSummary, error) {
+	rows, err := cfg.db.ListFeedbackForApplicant(ctx, pgtype.Int4{Int32: applicantID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("listing feedback: %w", err)
+	}
+	byApplication := map[int32][]database.ListFeedbackForApplicationRow{}
+	order := []int32{}
+	for _, row := range rows {
+		id := row.ScorecardFeedback.ApplicationID
+		if _, ok := byApplication[id]; !ok {
+			order = append(order, id)
+		}
+		byApplication[id] = append(byApplication[id], database.ListFeedbackForApplicationRow(row))
+	}
+
+	summaries := []scorecardSummary{}
+	for _, id := range order {
+		view, err := viewFeedback(ctx, cfg.db, userID, id, byApplication[id])
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, view.Summary)
+	}
+	return summaries, nil
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/auth"
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+	"github.com/Vikuuu/synlabs-assignment/internal/memstore"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return startTestServer(t, &apiConfig{
+		db:     memstore.New(),
+		secret: "test-secret",
+	})
+}
+
+func startTestServer(t *testing.T, cfg *apiConfig) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(cfg.routes(newLogger(io.Discard, "error")))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func doRequest(t *testing.T, method, url, body string, header http.Header) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("building request: %s", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %s", method, url, err)
+	}
+	t.Cleanup(func() { res.Body.Close() })
+	return res
+}
+
+func makeTestToken(t *testing.T, secret string, userID int32) string {
+	t.Helper()
+	token, err := auth.MakeJWT(userID, secret, time.Hour)
+	if err != nil {
+		t.Fatalf("making token: %s", err)
+	}
+	return token
+}
+
+func decodeProblem(t *testing.T, res *http.Response) problem {
+	t.Helper()
+	if ct := res.Header.Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("Content-Type = %q, want application/problem+json", ct)
+	}
+	p := problem{}
+	if err := json.NewDecoder(res.Body).Decode(&p); err != nil {
+		t.Fatalf("decoding problem: %s", err)
+	}
+	return p
+}
+
+func TestBadInputReturnsClientError(t *testing.T) {
+	srv := newTestServer(t)
+	bearer := func(token string) http.Header {
+		return http.Header{"Authorization": {"Bearer " + token}}
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		header http.Header
+		want   int
+		code   errorCode
+	}{
+		{"signup malformed JSON", "POST", "/signup", "{not json", nil, http.StatusBadRequest, codeMalformedRequest},
+		{"signup empty body", "POST", "/signup", "", nil, http.StatusBadRequest, codeMalformedRequest},
+		{"login malformed JSON", "POST", "/login", "[", nil, http.StatusBadRequest, codeMalformedRequest},
+		{"jobs without token", "GET", "/jobs", "", nil, http.StatusUnauthorized, codeUnauthorized},
+		{"jobs with garbage token", "GET", "/jobs", "", bearer("garbage"), http.StatusUnauthorized, codeUnauthorized},
+		{"apply without token", "GET", "/jobs/apply?job_id=1", "", nil, http.StatusUnauthorized, codeUnauthorized},
+		{"upload without token", "POST", "/uploadResume", "{}", nil, http.StatusUnauthorized, codeUnauthorized},
+		{"admin job without token", "GET", "/admin/job/1", "", nil, http.StatusUnauthorized, codeUnauthorized},
+		{"admin applicants with basic auth", "GET", "/admin/applicants", "", http.Header{"Authorization": {"Basic Zm9vOmJhcg=="}}, http.StatusUnauthorized, codeUnauthorized},
+		{"unknown path", "GET", "/nope", "", nil, http.StatusNotFound, codeNotFound},
+		{"wrong method", "DELETE", "/jobs", "", nil, http.StatusMethodNotAllowed, codeMethodNotAllowed},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res := doRequest(t, tc.method, srv.URL+tc.path, tc.body, tc.header)
+			if res.StatusCode != tc.want {
+				t.Fatalf("status = %d, want %d", res.StatusCode, tc.want)
+			}
+
+			p := decodeProblem(t, res)
+			if p.Code != tc.code {
+				t.Fatalf("code = %q, want %q", p.Code, tc.code)
+			}
+			if p.Status != tc.want || p.Detail == "" {
+				t.Fatalf("incomplete problem: %+v", p)
+			}
+
+			// The server must still be serving after the bad request.
+			res = doRequest(t, "GET", srv.URL+"/", "", nil)
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("landing page status = %d after bad request", res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRecoverFromPanic(t *testing.T) {
+	h := middlewareRecover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if strings.Contains(rec.Body.String(), "boom") {
+		t.Fatalf("panic value leaked to client: %s", rec.Body.String())
+	}
+}
+
+func TestServerSurvivesHandlerPanic(t *testing.T) {
+	// A valid token makes the middleware query the database, which is
+	// nil here and panics.
+	srv := startTestServer(t, &apiConfig{
+		db:     database.NewStore(nil, nil),
+		secret: "test-secret",
+	})
+	token := makeTestToken(t, "test-secret", 1)
+
+	res := doRequest(t, "GET", srv.URL+"/jobs", "", http.Header{"Authorization": {"Bearer " + token}})
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", res.StatusCode, http.StatusInternalServerError)
+	}
+
+	res = doRequest(t, "GET", srv.URL+"/", "", nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("landing page status = %d after panic", res.StatusCode)
+	}
+}
+
+func TestWriteErrorHidesInternalErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		want   int
+		code   errorCode
+		detail string
+	}{
+		{"plain error", errors.New("pq: connection refused"), http.StatusInternalServerError, codeInternal, "The server could not complete the request"},
+		{"api error", newAPIError(codeJobNotFound, "No job exists with this ID", errors.New("sql: no rows")), http.StatusNotFound, codeJobNotFound, "No job exists with this ID"},
+		{"wrapped api error", fmt.Errorf("ctx: %w", newAPIError(codeForbidden, "Nope", nil)), http.StatusForbidden, codeForbidden, "Nope"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			writeError(rec, httptest.NewRequest("GET", "/", nil), tc.err)
+
+			p := decodeProblem(t, rec.Result())
+			if rec.Code != tc.want || p.Status != tc.want {
+				t.Fatalf("status = %d (body %d), want %d", rec.Code, p.Status, tc.want)
+			}
+			if p.Code != tc.code || p.Detail != tc.detail {
+				t.Fatalf("problem = %+v, want code %q detail %q", p, tc.code, tc.detail)
+			}
+			if p.Type != problemTypePrefix+string(tc.code) {
+				t.Fatalf("type = %q", p.Type)
+			}
+		})
+	}
+}
+
+func TestValidationProblemListsFields(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeError(rec, httptest.NewRequest("GET", "/jobs/apply", nil), newValidationError(
+		fieldError{Field: "job_id", Code: "invalid", Message: "Job ID must be an integer"},
+		fieldError{Field: "note", Code: "too_long", Message: "Note is too long"},
+	))
+
+	p := decodeProblem(t, rec.Result())
+	if p.Status != http.StatusUnprocessableEntity || p.Code != codeValidationFailed {
+		t.Fatalf("problem = %+v", p)
+	}
+	if len(p.Errors) != 2 || p.Errors[0].Field != "job_id" || p.Errors[1].Code != "too_long" {
+		t.Fatalf("errors = %+v", p.Errors)
+	}
+}
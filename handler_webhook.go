@@ -0,0 +1,246 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+)
+
+// deliveryLogLimit caps the deliveries listed per request.
+const deliveryLogLimit = 100
+
+type addWebhookPayload struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Secret signs the deliveries; one is generated when it is empty.
+	Secret string `json:"secret,omitempty"`
+}
+
+func (p *addWebhookPayload) validate(v *validator) {
+	p.URL = strings.TrimSpace(p.URL)
+
+	v.required("url", p.URL)
+	if p.URL != "" {
+		u, err := url.Parse(p.URL)
+		v.check(
+			err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"url", "invalid_format", "Must be an absolute http or https URL",
+		)
+	}
+	v.maxLen("url", p.URL, 2000)
+
+	v.check(len(p.Events) > 0, "events", "required", "Is required")
+	for _, e := range p.Events {
+		if !slices.Contains(eventTypes, e) {
+			v.add("events", "invalid_choice", "Must be one of: "+strings.Join(eventTypes, ", "))
+		}
+	}
+	slices.Sort(p.Events)
+	p.Events = slices.Compact(p.Events)
+
+	if p.Secret != "" {
+		v.check(len(p.Secret) >= 16, "secret", "too_short", "Must be at least 16 characters")
+		v.maxLen("secret", p.Secret, 200)
+	}
+}
+
+type webhookResponse struct {
+	ID        int32     `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	CreatedBy int32     `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	// Secret is only returned when the webhook is created.
+	Secret string `json:"secret,omitempty"`
+}
+
+func (cfg *apiConfig) handlerAddWebhook(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := r.Context().Value("userID").(int)
+	if !ok {
+		return errors.New("user ID missing from request context")
+	}
+
+	payload := addWebhookPayload{}
+	if err := decodeJSON(w, r, &payload); err != nil {
+		return err
+	}
+	if payload.Secret == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return fmt.Errorf("generating webhook secret: %w", err)
+		}
+		payload.Secret = "whsec_" + hex.EncodeToString(b)
+	}
+
+	data, err := cfg.db.CreateWebhook(r.Context(), database.CreateWebhookParams{
+		Url:       payload.URL,
+		Secret:    payload.Secret,
+		Events:    payload.Events,
+		CreatedBy: int32(userID),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("creating webhook: %w", err)
+	}
+
+	return respondWithJSON(w, webhookResponse{
+		ID:        data.ID,
+		URL:       data.Url,
+		Events:    data.Events,
+		CreatedBy: data.CreatedBy,
+		CreatedAt: data.CreatedAt,
+		Secret:    payload.Secret,
+	}, http.StatusCreated)
+}
+
+func (cfg *apiConfig) handlerWebhooks(w http.ResponseWriter, r *http.Request) error {
+	data, err := cfg.db.ListWebhooks(r.Context())
+	if err != nil {
+		return fmt.Errorf("listing webhooks: %w", err)
+	}
+	res := []webhookResponse{}
+	for _, d := range data {
+		res = append(res, webhookResponse{
+			ID:        d.ID,
+			URL:       d.Url,
+			Events:    d.Events,
+			CreatedBy: d.CreatedBy,
+			CreatedAt: d.CreatedAt,
+		})
+	}
+	return respondWithJSON(w, res, http.StatusOK)
+}
+
+func webhookIDParam(r *http.Request) (int32, error) {
+	id, err := strconv.Atoi(r.PathValue("webhook_id"))
+	if err != nil {
+		return 0, newValidationError(fieldError{
+			Field:   "webhook_id",
+			Code:    "invalid",
+			Message: "Webhook ID must be an integer",
+		})
+	}
+	return int32(id), nil
+}
+
+// handlerDeleteWebhook removes a webhook along with its delivery log.
+func (cfg *apiConfig) handlerDeleteWebhook(w http.ResponseWriter, r *http.Request) error {
+	id, err := webhookIDParam(r)
+	if err != nil {
+		return err
+	}
+	n, err := cfg.db.DeleteWebhook(r.Context(), id)
+	if err != nil {
+		return fmt.Errorf("deleting webhook: %w", err)
+	}
+	if n == 0 {
+		return newAPIError(codeWebhookNotFound, "No webhook exists with this ID", nil)
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+type deliveryResponse struct {
+	ID             int64                          `json:"id"`
+	WebhookID      int32                          `json:"webhook_id"`
+	EventID        string                         `json:"event_id"`
+	EventType      string                         `json:"event_type"`
+	Payload        json.RawMessage                `json:"payload"`
+	Status         database.WebhookDeliveryStatus `json:"status"`
+	Attempts       int32                          `json:"attempts"`
+	NextAttemptAt  *time.Time                     `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time                     `json:"last_attempt_at,omitempty"`
+	LastStatusCode *int32                         `json:"last_status_code,omitempty"`
+	LastError      string                         `json:"last_error,omitempty"`
+	CreatedAt      time.Time                      `json:"created_at"`
+}
+
+func newDeliveryResponses(data []database.WebhookDelivery) []deliveryResponse {
+	res := []deliveryResponse{}
+	for _, d := range data {
+		dr := deliveryResponse{
+			ID:        d.ID,
+			WebhookID: d.SubscriptionID,
+			EventID:   d.EventID,
+			EventType: d.EventType,
+			Payload:   d.Payload,
+			Status:    d.Status,
+			Attempts:  d.Attempts,
+			LastError: d.LastError.String,
+			CreatedAt: d.CreatedAt,
+		}
+		if d.Status == database.WebhookDeliveryStatusPending {
+			dr.NextAttemptAt = &d.NextAttemptAt
+		}
+		if d.LastAttemptAt.Valid {
+			dr.LastAttemptAt = &d.LastAttemptAt.Time
+		}
+		if d.LastStatusCode.Valid {
+			dr.LastStatusCode = &d.LastStatusCode.Int32
+		}
+		res = append(res, dr)
+	}
+	return res
+}
+
+// handlerWebhookDeliveries is the delivery log of one webhook, newest
+// first.
+func (cfg *apiConfig) handlerWebhookDeliveries(w http.ResponseWriter, r *http.Request) error {
+	id, err := webhookIDParam(r)
+	if err != nil {
+		return err
+	}
+	data, err := cfg.db.ListWebhookDeliveries(r.Context(), database.ListWebhookDeliveriesParams{
+		SubscriptionID: id,
+		Limit:          deliveryLogLimit,
+	})
+	if err != nil {
+		return fmt.Errorf("listing webhook deliveries: %w", err)
+	}
+	return respondWithJSON(w, newDeliveryResponses(data), http.StatusOK)
+}
+
+// handlerDeadLetters lists the deliveries of every webhook that ran out
+// of attempts, newest first.
+func (cfg *apiConfig) handlerDeadLetters(w http.ResponseWriter, r *http.Request) error {
+	data, err := cfg.db.ListDeadWebhookDeliveries(r.Context(), deliveryLogLimit)
+	if err != nil {
+		return fmt.Errorf("listing dead webhook deliveries: %w", err)
+	}
+	return respondWithJSON(w, newDeliveryResponses(data), http.StatusOK)
+}
+
+// handlerRetryDelivery queues a dead delivery again with a fresh set of
+// attempts.
+func (cfg *apiConfig) handlerRetryDelivery(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.ParseInt(r.PathValue("delivery_id"), 10, 64)
+	if err != nil {
+		return newValidationError(fieldError{
+			Field:   "delivery_id",
+			Code:    "invalid",
+			Message: "Delivery ID must be an integer",
+		})
+	}
+	n, err := cfg.db.RetryWebhookDelivery(r.Context(), database.RetryWebhookDeliveryParams{
+		ID:            id,
+		NextAttemptAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("retrying webhook delivery: %w", err)
+	}
+	if n == 0 {
+		return newAPIError(codeDeliveryNotFound, "No dead delivery exists with this ID", nil)
+	}
+	w.WriteHeader(http.StatusAccepted)
+	return nil
+}
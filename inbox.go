@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+	"github.com/Vikuuu/synlabs-assignment/internal/outbox"
+)
+
+// Kinds of in-app notifications.
+const (
+	notificationStatusChanged = "application.status_changed"
+	notificationNewApplicant  = "application.received"
+	notificationResumeParsed  = "resume.parsed"
+
+	notificationInterviewScheduled     = "interview.scheduled"
+	notificationInterviewStatusChanged = "interview.status_changed"
+	notificationFeedbackSubmitted      = "feedback.submitted"
+)
+
+// subscribeInbox adds notifications to the inboxes of the users that
+// outbox events concern.
+func (cfg *apiConfig) subscribeInbox(events *outbox.Handlers) {
+	events.Subscribe(eventApplicationStatusChanged, cfg.notifyStatusChanged)
+	events.Subscribe(eventApplicationCreated, cfg.notifyNewApplicant)
+	events.Subscribe(eventResumeParsed, cfg.notifyResumeParsed)
+	events.Subscribe(eventInterviewScheduled, cfg.notifyInterviewScheduled)
+	events.Subscribe(eventInterviewStatusChanged, cfg.notifyInterviewStatusChanged)
+	events.Subscribe(eventFeedbackSubmitted, cfg.notifyFeedbackSubmitted)
+}
+
+// notify adds a notification for an event to the inbox of a user. The
+// data of the event is kept with it for clients to link to. A user that
+// no longer exists is skipped.
+func (cfg *apiConfig) notify(ctx context.Context, e outbox.Event, userID int32, kind, title, body string, data interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("encoding notification data: %w", err)
+	}
+	err = cfg.db.CreateNotification(ctx, database.CreateNotificationParams{
+		UserID:    userID,
+		Kind:      kind,
+		Title:     title,
+		Body:      body,
+		Data:      raw,
+		EventID:   e.ID,
+		CreatedAt: time.Now(),
+	})
+	if database.IsForeignKeyViolation(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("creating %s notification: %w", kind, err)
+	}
+	return nil
+}
+
+func (cfg *apiConfig) notifyStatusChanged(ctx context.Context, e outbox.Event) error {
+	data := applicationEvent{}
+	if err := decodeEventData(e.Payload, &data); err != nil {
+		return err
+	}
+	job, err := cfg.db.GetJob(ctx, data.JobID)
+	if err != nil {
+		return fmt.Errorf("getting job: %w", err)
+	}
+	return cfg.notify(ctx, e, data.ApplicantID, notificationStatusChanged,
+		fmt.Sprintf("Your application for %s is now %s", job.Title, data.Status),
+		fmt.Sprintf("The status of your application to %s at %s changed from %s to %s.",
+			job.Title, job.CompanyName, data.PreviousStatus, data.Status),
+		data)
+}
+
+func (cfg *apiConfig) notifyNewApplicant(ctx context.Context, e outbox.Event) error {
+	data := applicationEvent{}
+	if err := decodeEventData(e.Payload, &data); err != nil {
+		return err
+	}
+	job, err := cfg.db.GetJob(ctx, data.JobID)
+	if err != nil {
+		return fmt.Errorf("getting job: %w", err)
+	}
+	applicant, err := cfg.db.GetNotificationRecipient(ctx, data.ApplicantID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("getting applicant: %w", err)
+	}
+	return cfg.notify(ctx, e, job.PostedBy, notificationNewApplicant,
+		"New applicant for "+job.Title,
+		fmt.Sprintf("%s applied to %s.", applicant.Name, job.Title),
+		data)
+}
+
+func (cfg *apiConfig) notifyResumeParsed(ctx context.Context, e outbox.Event) error {
+	data := resumeParsedEvent{}
+	if err := decodeEventData(e.Payload, &data); err != nil {
+		return err
+	}
+	return cfg.notify(ctx, e, data.ApplicantID, notificationResumeParsed,
+		"Your resume was processed",
+		"The skills and education from your resume were added to your profile.",
+		data)
+}
+
+// interviewTime formats the start of an interview for notifications.
+func interviewTime(t time.Time) string {
+	return t.UTC().Format("Monday, January 2, 2006 15:04 MST")
+}
+
+// interviewContext loads the job and applicant of an interview event.
+// ok is false when the applicant no longer exists.
+func (cfg *apiConfig) interviewContext(ctx context.Context, data interviewEvent) (job database.GetJobRow, applicant database.GetNotificationRecipientRow, ok bool, err error) {
+	job, err = cfg.db.GetJob(ctx, data.JobID)
+	if err != nil {
+		return job, applicant, false, fmt.Errorf("getting job: %w", err)
+	}
+	applicant, err = cfg.db.GetNotificationRecipient(ctx, data.ApplicantID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return job, applicant, false, nil
+	}
+	if err != nil {
+		return job, applicant, false, fmt.Errorf("getting applicant: %w", err)
+	}
+	return job, applicant, true, nil
+}
+
+func (cfg *apiConfig) notifyInterviewScheduled(ctx context.Context, e outbox.Event) error {
+	data := interviewEvent{}
+	if err := decodeEventData(e.Payload, &data); err != nil {
+		return err
+	}
+	job, applicant, ok, err := cfg.interviewContext(ctx, data)
+	if err != nil || !ok {
+		return err
+	}
+
+	verb := "scheduled"
+	if data.PreviousStatus != "" {
+		verb = "moved"
+	}
+	err = cfg.notify(ctx, e, data.ApplicantID, notificationInterviewScheduled,
+		fmt.Sprintf("Interview %s for %s", verb, job.Title),
+		fmt.Sprintf("Your interview for %s at %s is on %s. Please confirm it or ask to reschedule.",
+			job.Title, job.CompanyName, interviewTime(data.StartsAt)),
+		data)
+	if err != nil {
+		return err
+	}
+	for _, id := range data.InterviewerIDs {
+		err := cfg.notify(ctx, e, id, notificationInterviewScheduled,
+			fmt.Sprintf("Interview with %s %s", applicant.Name, verb),
+			fmt.Sprintf("You interview %s for %s on %s.", applicant.Name, job.Title, interviewTime(data.StartsAt)),
+			data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyInterviewStatusChanged tells the interviewers how the applicant
+// answered, and the applicant when the interview is cancelled.
+func (cfg *apiConfig) notifyInterviewStatusChanged(ctx context.Context, e outbox.Event) error {
+	data := interviewEvent{}
+	if err := decodeEventData(e.Payload, &data); err != nil {
+		return err
+	}
+	job, applicant, ok, err := cfg.interviewContext(ctx, data)
+	if err != nil || !ok {
+		return err
+	}
+
+	var title, body string
+	switch data.Status {
+	case database.InterviewStatusConfirmed:
+		title = applicant.Name + " confirmed their interview"
+		body = fmt.Sprintf("%s confirmed the interview for %s on %s.", applicant.Name, job.Title, interviewTime(data.StartsAt))
+	case database.InterviewStatusRescheduleRequested:
+		title = applicant.Name + " asked to reschedule their interview"
+		body = fmt.Sprintf("%s cannot make the interview for %s on %s: %s",
+			applicant.Name, job.Title, interviewTime(data.StartsAt), data.RescheduleNote)
+	case database.InterviewStatusCancelled:
+		title = fmt.Sprintf("Interview with %s cancelled", applicant.Name)
+		body = fmt.Sprintf("The interview with %s for %s on %s was cancelled.", applicant.Name, job.Title, interviewTime(data.StartsAt))
+		err := cfg.notify(ctx, e, data.ApplicantID, notificationInterviewStatusChanged,
+			"Interview cancelled for "+job.Title,
+			fmt.Sprintf("Your interview for %s at %s on %s was cancelled.", job.Title, job.CompanyName, interviewTime(data.StartsAt)),
+			data)
+		if err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+	for _, id := range data.InterviewerIDs {
+		if err := cfg.notify(ctx, e, id, notificationInterviewStatusChanged, title, body, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyFeedbackSubmitted tells the admin that posted the job, unless
+// they gave the feedback themselves.
+func (cfg *apiConfig) notifyFeedbackSubmitted(ctx context.Context, e outbox.Event) error {
+	data := feedbackEvent{}
+	if err := decodeEventData(e.Payload, &data); err != nil {
+		return err
+	}
+	job, err := cfg.db.GetJob(ctx, data.JobID)
+	if err != nil {
+		return fmt.Errorf("getting job: %w", err)
+	}
+	if job.PostedBy == data.InterviewerID {
+		return nil
+	}
+	interviewer, err := cfg.db.GetNotificationRecipient(ctx, data.InterviewerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("getting interviewer: %w", err)
+	}
+	applicant, err := cfg.db.GetNotificationRecipient(ctx, data.ApplicantID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("getting applicant: %w", err)
+	}
+	return cfg.notify(ctx, e, job.PostedBy, notificationFeedbackSubmitted,
+		"New feedback on "+applicant.Name,
+		fmt.Sprintf("%s submitted their scorecard for %s, who applied to %s.", interviewer.Name, applicant.Name, job.Title),
+		data)
+}
@@ -0,0 +1,233 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Vikuuu/synlabs-assignment/sql/schema"
+)
+
+type client struct {
+	t       *testing.T
+	db      *testDB
+	baseURL string
+	token   string
+}
+
+// call sends body as JSON and decodes the response into out when not nil.
+func (c *client) call(method, path string, body interface{}, wantStatus int, out interface{}) {
+	c.t.Helper()
+	var r io.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, r)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer res.Body.Close()
+	data, _ := io.ReadAll(res.Body)
+	if res.StatusCode != wantStatus {
+		c.t.Fatalf("%s %s: status %d, want %d: %s", method, path, res.StatusCode, wantStatus, data)
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			c.t.Fatalf("%s %s: decoding %s: %s", method, path, data, err)
+		}
+	}
+}
+
+func (c *client) signupAndLogin(name, email, userType string) int32 {
+	c.t.Helper()
+	c.call("POST", "/signup", map[string]string{
+		"name":             name,
+		"email":            email,
+		"password":         "integration1",
+		"user_type":        userType,
+		"address":          "1 Test Street",
+		"profile_headline": name + " headline",
+	}, http.StatusCreated, nil)
+
+	login := struct {
+		AccessToken string `json:"access_token"`
+	}{}
+	c.call("POST", "/login", map[string]string{"email": email, "password": "integration1"}, http.StatusOK, &login)
+	c.token = login.AccessToken
+
+	var id int32
+	if err := c.db.QueryRow("SELECT id FROM users WHERE email = $1", email).Scan(&id); err != nil {
+		c.t.Fatal(err)
+	}
+	return id
+}
+
+func fakeParser(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("apiKey") != "integration" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"name":"Ada Applicant","email":"ada@example.com","phone":"+1 555 0100",
+			"skills":["Go","PostgreSQL"],"education":[{"name":"Open University"}]}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestApplicantAndAdminFlow(t *testing.T) {
+	parser := fakeParser(t)
+	db := newTestDB(t)
+	baseURL := startServer(t, db, "RESUME_PARSER_URL="+parser.URL) + "/v1"
+
+	admin := &client{t: t, db: db, baseURL: baseURL}
+	admin.signupAndLogin("Alan Admin", "alan@example.com", "admin")
+
+	ada := &client{t: t, db: db, baseURL: baseURL}
+	adaID := ada.signupAndLogin("Ada Applicant", "ada@example.com", "applicant")
+	bob := &client{t: t, db: db, baseURL: baseURL}
+	bobID := bob.signupAndLogin("Bob Applicant", "bob@example.com", "applicant")
+
+	// Every applicant points at their own profile, and admins at none.
+	rows, err := db.Query("SELECT id, profile_id FROM users ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		var id int32
+		var profileID *int32
+		rows.Scan(&id, &profileID)
+		switch {
+		case id == adaID || id == bobID:
+			if profileID == nil || *profileID != id {
+				t.Errorf("user %d has profile_id %v", id, profileID)
+			}
+		case profileID != nil:
+			t.Errorf("admin %d has profile_id %d", id, *profileID)
+		}
+	}
+	rows.Close()
+
+	resume := filepath.Join(t.TempDir(), "ada.pdf")
+	if err := os.WriteFile(resume, []byte("%PDF-1.4 ada"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	parsed := map[string]string{}
+	ada.call("POST", "/uploadResume", map[string]string{"file_address": resume}, http.StatusOK, &parsed)
+	if parsed["skills"] != "Go,PostgreSQL" {
+		t.Errorf("parsed resume = %v", parsed)
+	}
+
+	admin.call("POST", "/admin/job", map[string]string{
+		"title":        "Database Engineer",
+		"description":  "Keep Postgres happy",
+		"company_name": "Synlabs",
+	}, http.StatusCreated, nil)
+	var jobID int32
+	if err := db.QueryRow("SELECT id FROM job WHERE title = 'Database Engineer'").Scan(&jobID); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs := []map[string]interface{}{}
+	ada.call("GET", "/jobs", nil, http.StatusOK, &jobs)
+	if len(jobs) != 1 {
+		t.Fatalf("jobs = %v", jobs)
+	}
+	ada.call("GET", fmt.Sprintf("/jobs/apply?job_id=%d", jobID), nil, http.StatusOK, nil)
+	bob.call("GET", fmt.Sprintf("/jobs/apply?job_id=%d", jobID), nil, http.StatusOK, nil)
+	ada.call("GET", "/admin/applicants", nil, http.StatusForbidden, nil)
+
+	job := struct {
+		TotalApplications int32 `json:"total_applications"`
+	}{}
+	admin.call("GET", fmt.Sprintf("/admin/job/%d", jobID), nil, http.StatusOK, &job)
+	if job.TotalApplications != 2 {
+		t.Errorf("total_applications = %d, want 2", job.TotalApplications)
+	}
+
+	applicants := []map[string]string{}
+	admin.call("GET", "/admin/applicants", nil, http.StatusOK, &applicants)
+	if len(applicants) != 2 {
+		t.Errorf("applicants = %v", applicants)
+	}
+
+	profile := map[string]string{}
+	admin.call("GET", fmt.Sprintf("/admin/applicant/%d", adaID), nil, http.StatusOK, &profile)
+	if profile["resume"] != resume || profile["skills"] != "Go,PostgreSQL" || profile["education"] != "Open University" {
+		t.Errorf("profile = %v", profile)
+	}
+
+	// Bob never uploaded a resume but still has an (empty) profile.
+	admin.call("GET", fmt.Sprintf("/admin/applicant/%d", bobID), nil, http.StatusOK, &profile)
+	if profile["email"] != "bob@example.com" || profile["skills"] != "" {
+		t.Errorf("profile = %v", profile)
+	}
+}
+
+func TestDuplicateSignupIsRejectedByConstraint(t *testing.T) {
+	db := newTestDB(t)
+	c := &client{t: t, db: db, baseURL: startServer(t, db) + "/v1"}
+	body := map[string]string{"name": "Dup", "email": "dup@example.com", "password": "integration1"}
+	c.call("POST", "/signup", body, http.StatusCreated, nil)
+	c.call("POST", "/signup", body, http.StatusConflict, nil)
+}
+
+func TestEmailsAreUniqueIgnoringCase(t *testing.T) {
+	db := newTestDB(t)
+	c := &client{t: t, db: db, baseURL: startServer(t, db) + "/v1"}
+	c.call("POST", "/signup", map[string]string{"name": "Case", "email": "case@example.com", "password": "integration1"}, http.StatusCreated, nil)
+
+	_, err := db.Exec(`INSERT INTO users (name, email, address, password_hash, profile_headline)
+VALUES ('Case', 'Case@Example.com', '', 'x', '')`)
+	if err == nil {
+		t.Fatal("inserted a case variant of a registered email")
+	}
+}
+
+func TestRepairProfileIDs(t *testing.T) {
+	db := newTestDB(t)
+	baseURL := startServer(t, db) + "/v1"
+	admin := &client{t: t, db: db, baseURL: baseURL}
+	adminID := admin.signupAndLogin("Rita Admin", "rita@example.com", "admin")
+	applicant := &client{t: t, db: db, baseURL: baseURL}
+	applicantID := applicant.signupAndLogin("Ray Applicant", "ray@example.com", "applicant")
+
+	// What the unscoped AddProfileIDInUser left behind.
+	if _, err := db.Exec("UPDATE users SET profile_id = $1", applicantID); err != nil {
+		t.Fatal(err)
+	}
+	migration, err := schema.FS.ReadFile("012_repair_profile_ids.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	up, _, _ := strings.Cut(string(migration), "-- +goose Down")
+	if _, err := db.Exec(up); err != nil {
+		t.Fatal(err)
+	}
+
+	var profileID *int32
+	db.QueryRow("SELECT profile_id FROM users WHERE id = $1", applicantID).Scan(&profileID)
+	if profileID == nil || *profileID != applicantID {
+		t.Errorf("applicant has profile_id %v", profileID)
+	}
+	db.QueryRow("SELECT profile_id FROM users WHERE id = $1", adminID).Scan(&profileID)
+	if profileID != nil {
+		t.Errorf("admin has profile_id %d", *profileID)
+	}
+}
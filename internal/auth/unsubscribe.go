@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+
+func unsubscribeMAC(userID int32, list, tokenSecret string) []byte {
+	h := hmac.New(sha256.New, []byte(tokenSecret))
+	// The prefix keeps these signatures apart from any other use of the
+	// secret.
+	fmt.Fprintf(h, "unsubscribe:%d:%s", userID, list)
+	return h.Sum(nil)
+}
+
+// MakeUnsubscribeToken returns a token that lets its bearer turn off one
+// email list for a user. It does not expire, since it is put in emails.
+func MakeUnsubscribeToken(userID int32, list, tokenSecret string) string {
+	sig := base64.RawURLEncoding.EncodeToString(unsubscribeMAC(userID, list, tokenSecret))
+	return fmt.Sprintf("%d.%s.%s", userID, list, sig)
+}
+
+// ParseUnsubscribeToken returns the user and list of a token made by
+// MakeUnsubscribeToken.
+func ParseUnsubscribeToken(token, tokenSecret string) (int32, string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+	userID, err := strconv.ParseInt(parts[0], 10, 32)
+	if err != nil {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(sig, unsubscribeMAC(int32(userID), parts[1], tokenSecret)) {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+	return int32(userID), parts[1], nil
+}
@@ -0,0 +1,362 @@
+// Package config loads the service configuration from defaults, an
+// optional YAML file, a .env file and the environment, in increasing
+// order of precedence, and validates it before the server starts.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const minSecretLength = 32
+
+type Config struct {
+	Port     string `yaml:"port" env:"PORT"`
+	LogLevel string `yaml:"log_level" env:"LOG_LEVEL"`
+	DBURL    DSN    `yaml:"db_url" env:"DB_URL"`
+	// Secret signs our JWTs and OIDC login sessions.
+	Secret Secret `yaml:"secret" env:"SECRET"`
+	// MigrateOnStart applies pending migrations before serving.
+	MigrateOnStart bool `yaml:"migrate_on_start" env:"MIGRATE_ON_START"`
+
+	DB           DB           `yaml:"db"`
+	Server       Server       `yaml:"server"`
+	ResumeParser ResumeParser `yaml:"resume_parser"`
+	OIDC         OIDC         `yaml:"oidc"`
+	Tracing      Tracing      `yaml:"tracing"`
+	Webhooks     Webhooks     `yaml:"webhooks"`
+	Outbox       Outbox       `yaml:"outbox"`
+	Mail         Mail         `yaml:"mail"`
+}
+
+// DB tunes the connection pool and bounds how long queries may run.
+type DB struct {
+	MaxConns          int           `yaml:"max_conns" env:"DB_MAX_CONNS"`
+	MinConns          int           `yaml:"min_conns" env:"DB_MIN_CONNS"`
+	MaxConnLifetime   time.Duration `yaml:"max_conn_lifetime" env:"DB_MAX_CONN_LIFETIME"`
+	MaxConnIdleTime   time.Duration `yaml:"max_conn_idle_time" env:"DB_MAX_CONN_IDLE_TIME"`
+	HealthCheckPeriod time.Duration `yaml:"health_check_period" env:"DB_HEALTH_CHECK_PERIOD"`
+	// StatementTimeout applies to every query not listed in QueryTimeouts,
+	// which is keyed by sqlc query name. Zero disables the timeout.
+	StatementTimeout time.Duration            `yaml:"statement_timeout" env:"DB_STATEMENT_TIMEOUT"`
+	QueryTimeouts    map[string]time.Duration `yaml:"query_timeouts"`
+}
+
+type Server struct {
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"READ_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"IDLE_TIMEOUT"`
+	// ShutdownDelay keeps serving after readiness starts failing so load
+	// balancers notice before connections are closed.
+	ShutdownDelay   time.Duration `yaml:"shutdown_delay" env:"SHUTDOWN_DELAY"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+}
+
+type ResumeParser struct {
+	URL     string        `yaml:"url" env:"RESUME_PARSER_URL"`
+	APIKey  Secret        `yaml:"api_key" env:"RESUME_PARSER_API_KEY"`
+	Timeout time.Duration `yaml:"timeout" env:"RESUME_PARSER_TIMEOUT"`
+}
+
+type OIDC struct {
+	Issuer       string   `yaml:"issuer" env:"OIDC_ISSUER"`
+	ClientID     string   `yaml:"client_id" env:"OIDC_CLIENT_ID"`
+	ClientSecret Secret   `yaml:"client_secret" env:"OIDC_CLIENT_SECRET"`
+	RedirectURL  string   `yaml:"redirect_url" env:"OIDC_REDIRECT_URL"`
+	AdminDomains []string `yaml:"admin_domains" env:"OIDC_ADMIN_DOMAINS"`
+}
+
+func (o OIDC) Enabled() bool {
+	return o.Issuer != ""
+}
+
+type Tracing struct {
+	Enabled     bool    `yaml:"enabled" env:"TRACING_ENABLED"`
+	Endpoint    string  `yaml:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT"`
+	Insecure    bool    `yaml:"otlp_insecure" env:"TRACING_OTLP_INSECURE"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+// Webhooks controls the delivery of outbound webhook events.
+type Webhooks struct {
+	// MaxAttempts is how often a delivery is tried before it is dead.
+	MaxAttempts int `yaml:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS"`
+	// Backoff is the wait after the first failure, doubled after every
+	// further one up to MaxBackoff.
+	Backoff      time.Duration `yaml:"backoff" env:"WEBHOOK_BACKOFF"`
+	MaxBackoff   time.Duration `yaml:"max_backoff" env:"WEBHOOK_MAX_BACKOFF"`
+	Timeout      time.Duration `yaml:"timeout" env:"WEBHOOK_TIMEOUT"`
+	PollInterval time.Duration `yaml:"poll_interval" env:"WEBHOOK_POLL_INTERVAL"`
+}
+
+// Outbox controls where domain events are published. They always reach
+// the in-process handlers; Sinks adds "stdout" and "nats".
+type Outbox struct {
+	Sinks       []string `yaml:"sinks" env:"OUTBOX_SINKS"`
+	NATSURL     string   `yaml:"nats_url" env:"OUTBOX_NATS_URL"`
+	NATSToken   Secret   `yaml:"nats_token" env:"OUTBOX_NATS_TOKEN"`
+	NATSSubject string   `yaml:"nats_subject" env:"OUTBOX_NATS_SUBJECT"`
+	// Backoff is the wait after the first failure, doubled after every
+	// further one up to MaxBackoff.
+	Backoff      time.Duration `yaml:"backoff" env:"OUTBOX_BACKOFF"`
+	MaxBackoff   time.Duration `yaml:"max_backoff" env:"OUTBOX_MAX_BACKOFF"`
+	Timeout      time.Duration `yaml:"timeout" env:"OUTBOX_TIMEOUT"`
+	PollInterval time.Duration `yaml:"poll_interval" env:"OUTBOX_POLL_INTERVAL"`
+	// Retention is how long published events are kept.
+	Retention time.Duration `yaml:"retention" env:"OUTBOX_RETENTION"`
+}
+
+// Mail configures notification emails. Without an SMTP host they are
+// logged instead of sent.
+type Mail struct {
+	SMTPHost     string `yaml:"smtp_host" env:"SMTP_HOST"`
+	SMTPPort     int    `yaml:"smtp_port" env:"SMTP_PORT"`
+	SMTPUsername string `yaml:"smtp_username" env:"SMTP_USERNAME"`
+	SMTPPassword Secret `yaml:"smtp_password" env:"SMTP_PASSWORD"`
+	From         string `yaml:"from" env:"MAIL_FROM"`
+	// BaseURL is where users reach the server, for links in emails.
+	BaseURL string `yaml:"base_url" env:"MAIL_BASE_URL"`
+	// DigestInterval is how often admins get the new applicants digest.
+	DigestInterval time.Duration `yaml:"digest_interval" env:"MAIL_DIGEST_INTERVAL"`
+}
+
+func Default() Config {
+	return Config{
+		Port:     "8080",
+		LogLevel: "info",
+		DB: DB{
+			MaxConns:          10,
+			MaxConnLifetime:   time.Hour,
+			MaxConnIdleTime:   30 * time.Minute,
+			HealthCheckPeriod: time.Minute,
+			StatementTimeout:  5 * time.Second,
+		},
+		Server: Server{
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   20 * time.Second,
+		},
+		ResumeParser: ResumeParser{
+			URL:     "https://api.apilayer.com/resume_parser/upload",
+			Timeout: 30 * time.Second,
+		},
+		Tracing: Tracing{
+			SampleRatio: 1,
+		},
+		Webhooks: Webhooks{
+			MaxAttempts:  8,
+			Backoff:      30 * time.Second,
+			MaxBackoff:   time.Hour,
+			Timeout:      10 * time.Second,
+			PollInterval: 2 * time.Second,
+		},
+		Outbox: Outbox{
+			NATSSubject:  "synlabs.events",
+			Backoff:      time.Second,
+			MaxBackoff:   5 * time.Minute,
+			Timeout:      10 * time.Second,
+			PollInterval: time.Second,
+			Retention:    7 * 24 * time.Hour,
+		},
+		Mail: Mail{
+			SMTPPort:       587,
+			From:           "Synlabs Jobs <no-reply@localhost>",
+			BaseURL:        "http://localhost:8080",
+			DigestInterval: 24 * time.Hour,
+		},
+	}
+}
+
+// Load builds the configuration without validating it, since not every
+// command needs all of it. yamlPath may be empty; the .env file is
+// optional. Values read from the environment win over the YAML file.
+func Load(yamlPath string) (Config, error) {
+	cfg := Default()
+
+	if yamlPath != "" {
+		data, err := os.ReadFile(yamlPath)
+		if err != nil {
+			return cfg, fmt.Errorf("reading config file: %w", err)
+		}
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&cfg); err != nil {
+			return cfg, fmt.Errorf("parsing config file %s: %w", yamlPath, err)
+		}
+	}
+
+	godotenv.Load()
+	if err := loadEnv(&cfg, os.LookupEnv); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+// Validate reports every problem with the configuration at once.
+func (c Config) Validate() error {
+	var errs []error
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Port == "" {
+		add("port is required")
+	}
+	if err := c.DBURL.Validate(); err != nil {
+		add("db_url: %w", err)
+	}
+	if len(c.Secret) < minSecretLength {
+		add("secret must be at least %d characters", minSecretLength)
+	}
+
+	if c.DB.MaxConns < 1 {
+		add("db.max_conns must be positive")
+	}
+	if c.DB.MinConns < 0 || c.DB.MinConns > c.DB.MaxConns {
+		add("db.min_conns must be between 0 and db.max_conns")
+	}
+
+	if u, err := url.Parse(c.ResumeParser.URL); err != nil || u.Scheme == "" || u.Host == "" {
+		add("resume_parser.url must be an absolute URL")
+	}
+	if c.ResumeParser.APIKey == "" {
+		add("resume_parser.api_key is required")
+	}
+	if c.ResumeParser.Timeout == 0 {
+		add("resume_parser.timeout must be positive")
+	}
+
+	if c.OIDC.Enabled() {
+		if u, err := url.Parse(c.OIDC.Issuer); err != nil || u.Scheme == "" || u.Host == "" {
+			add("oidc.issuer must be an absolute URL")
+		}
+		if c.OIDC.ClientID == "" {
+			add("oidc.client_id is required when oidc.issuer is set")
+		}
+		if u, err := url.Parse(c.OIDC.RedirectURL); err != nil || u.Scheme == "" || u.Host == "" {
+			add("oidc.redirect_url must be an absolute URL")
+		}
+	}
+
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		add("tracing.sample_ratio must be between 0 and 1")
+	}
+
+	if c.Webhooks.MaxAttempts < 1 {
+		add("webhooks.max_attempts must be positive")
+	}
+	for name, d := range map[string]time.Duration{
+		"webhooks.backoff":       c.Webhooks.Backoff,
+		"webhooks.max_backoff":   c.Webhooks.MaxBackoff,
+		"webhooks.timeout":       c.Webhooks.Timeout,
+		"webhooks.poll_interval": c.Webhooks.PollInterval,
+	} {
+		if d <= 0 {
+			add("%s must be positive", name)
+		}
+	}
+
+	for _, sink := range c.Outbox.Sinks {
+		switch sink {
+		case "stdout":
+		case "nats":
+			if u, err := url.Parse(c.Outbox.NATSURL); err != nil || u.Scheme != "nats" || u.Host == "" {
+				add("outbox.nats_url must be a nats:// URL when the nats sink is enabled")
+			}
+			if c.Outbox.NATSSubject == "" {
+				add("outbox.nats_subject is required when the nats sink is enabled")
+			}
+		default:
+			add("outbox.sinks: unknown sink %q, want stdout or nats", sink)
+		}
+	}
+	for name, d := range map[string]time.Duration{
+		"outbox.backoff":       c.Outbox.Backoff,
+		"outbox.max_backoff":   c.Outbox.MaxBackoff,
+		"outbox.timeout":       c.Outbox.Timeout,
+		"outbox.poll_interval": c.Outbox.PollInterval,
+		"outbox.retention":     c.Outbox.Retention,
+	} {
+		if d <= 0 {
+			add("%s must be positive", name)
+		}
+	}
+
+	if _, err := mail.ParseAddress(c.Mail.From); err != nil {
+		add("mail.from must be an email address: %w", err)
+	}
+	if u, err := url.Parse(c.Mail.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		add("mail.base_url must be an absolute URL")
+	}
+	if c.Mail.SMTPHost != "" && (c.Mail.SMTPPort < 1 || c.Mail.SMTPPort > 65535) {
+		add("mail.smtp_port must be a TCP port")
+	}
+	if c.Mail.DigestInterval <= 0 {
+		add("mail.digest_interval must be positive")
+	}
+
+	durations := map[string]time.Duration{
+		"db.max_conn_lifetime":       c.DB.MaxConnLifetime,
+		"db.max_conn_idle_time":      c.DB.MaxConnIdleTime,
+		"db.health_check_period":     c.DB.HealthCheckPeriod,
+		"db.statement_timeout":       c.DB.StatementTimeout,
+		"server.read_header_timeout": c.Server.ReadHeaderTimeout,
+		"server.read_timeout":        c.Server.ReadTimeout,
+		"server.write_timeout":       c.Server.WriteTimeout,
+		"server.idle_timeout":        c.Server.IdleTimeout,
+		"server.shutdown_delay":      c.Server.ShutdownDelay,
+		"server.shutdown_timeout":    c.Server.ShutdownTimeout,
+		"resume_parser.timeout":      c.ResumeParser.Timeout,
+	}
+	for query, d := range c.DB.QueryTimeouts {
+		durations["db.query_timeouts."+query] = d
+	}
+	for name, d := range durations {
+		if d < 0 {
+			add("%s must not be negative", name)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Redacted flattens the configuration into dotted YAML keys with secrets
+// and the database password masked, for logging at startup.
+func (c Config) Redacted() map[string]string {
+	out := map[string]string{}
+	flatten(reflect.ValueOf(c), "", out)
+	return out
+}
+
+func flatten(v reflect.Value, prefix string, out map[string]string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := prefix + strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		fv := v.Field(i)
+		switch {
+		case fv.Kind() == reflect.Struct:
+			flatten(fv, name+".", out)
+		case fv.Kind() == reflect.Slice:
+			out[name] = strings.Join(fv.Interface().([]string), ",")
+		default:
+			out[name] = fmt.Sprint(fv.Interface())
+		}
+	}
+}
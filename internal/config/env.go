@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type lookupFunc func(key string) (string, bool)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// loadEnv overrides fields tagged `env:"NAME"` with NAME from the
+// environment. Secret fields may instead be read from the file named by
+// NAME_FILE.
+func loadEnv(cfg *Config, lookup lookupFunc) error {
+	return loadEnvStruct(reflect.ValueOf(cfg).Elem(), lookup)
+}
+
+func loadEnvStruct(v reflect.Value, lookup lookupFunc) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, fv := t.Field(i), v.Field(i)
+		key := field.Tag.Get("env")
+		if key == "" {
+			if fv.Kind() == reflect.Struct {
+				if err := loadEnvStruct(fv, lookup); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		raw, ok := lookup(key)
+		if fv.Type() == reflect.TypeOf(Secret("")) {
+			if path, fileOK := lookup(key + "_FILE"); fileOK && !ok {
+				secret, err := readSecretFile(path)
+				if err != nil {
+					return fmt.Errorf("%s_FILE: %w", key, err)
+				}
+				raw, ok = secret, true
+			}
+		}
+		if !ok {
+			continue
+		}
+		if err := setField(fv, raw); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func setField(fv reflect.Value, raw string) error {
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		fv.SetBool(b)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		fv.SetFloat(f)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		fv.SetInt(int64(n))
+	case reflect.Slice:
+		list := []string{}
+		for _, s := range strings.Split(raw, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				list = append(list, s)
+			}
+		}
+		fv.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
+}
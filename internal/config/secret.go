@@ -0,0 +1,104 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "[REDACTED]"
+
+// Secret holds a credential. It never prints its value, so a Config can
+// be logged as is.
+type Secret string
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Reveal returns the actual value.
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+// UnmarshalYAML accepts either a plain string or a mapping
+// {file: /path/to/secret} read from disk, e.g. a mounted Kubernetes secret.
+func (s *Secret) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*s = Secret(node.Value)
+		return nil
+	}
+	var ref struct {
+		File string `yaml:"file"`
+	}
+	if err := node.Decode(&ref); err != nil {
+		return err
+	}
+	v, err := readSecretFile(ref.File)
+	if err != nil {
+		return err
+	}
+	*s = Secret(v)
+	return nil
+}
+
+func readSecretFile(path string) (string, error) {
+	if path == "" {
+		return "", errors.New("secret file path is empty")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// DSN is a database connection URL whose password is hidden when printed.
+type DSN string
+
+func (d DSN) String() string {
+	u, err := url.Parse(string(d))
+	if err != nil {
+		if d == "" {
+			return ""
+		}
+		return redacted
+	}
+	return u.Redacted()
+}
+
+func (d DSN) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// Validate checks d is a postgres:// URL naming a host and database.
+func (d DSN) Validate() error {
+	if d == "" {
+		return errors.New("is required")
+	}
+	u, err := url.Parse(string(d))
+	if err != nil {
+		return errors.New("is not a valid URL")
+	}
+	if u.Scheme != "postgres" && u.Scheme != "postgresql" {
+		return fmt.Errorf("scheme must be postgres, got %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return errors.New("host is missing")
+	}
+	if strings.TrimPrefix(u.Path, "/") == "" {
+		return errors.New("database name is missing")
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: export.sql
+
+package database
+
+import (
+	"context"
+)
+
+const exportApplications = `-- name: ExportApplications :many
+SELECT applicant_id, job_id, id, status, applied_at
+FROM apply_jobs
+ORDER BY job_id, applicant_id
+`
+
+func (q *Queries) ExportApplications(ctx context.Context) ([]ApplyJob, error) {
+	rows, err := q.db.Query(ctx, exportApplications)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApplyJob
+	for rows.Next() {
+		var i ApplyJob
+		if err := rows.Scan(
+			&i.ApplicantID,
+			&i.JobID,
+			&i.ID,
+			&i.Status,
+			&i.AppliedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportJobs = `-- name: ExportJobs :many
+SELECT id, title, description, posted_on, total_applications, company_name, posted_by, closed_at
+FROM job
+ORDER BY id
+`
+
+func (q *Queries) ExportJobs(ctx context.Context) ([]Job, error) {
+	rows, err := q.db.Query(ctx, exportJobs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Job
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.PostedOn,
+			&i.TotalApplications,
+			&i.CompanyName,
+			&i.PostedBy,
+			&i.ClosedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportProfiles = `-- name: ExportProfiles :many
+SELECT applicant, resume_file_address, skills, education, name, email, phone
+FROM profile
+ORDER BY applicant
+`
+
+func (q *Queries) ExportProfiles(ctx context.Context) ([]Profile, error) {
+	rows, err := q.db.Query(ctx, exportProfiles)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Profile
+	for rows.Next() {
+		var i Profile
+		if err := rows.Scan(
+			&i.Applicant,
+			&i.ResumeFileAddress,
+			&i.Skills,
+			&i.Education,
+			&i.Name,
+			&i.Email,
+			&i.Phone,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportUsers = `-- name: ExportUsers :many
+SELECT id, name, email, address, user_type, profile_headline
+FROM users
+ORDER BY id
+`
+
+type ExportUsersRow struct {
+	ID              int32
+	Name            string
+	Email           string
+	Address         string
+	UserType        UserType
+	ProfileHeadline string
+}
+
+func (q *Queries) ExportUsers(ctx context.Context) ([]ExportUsersRow, error) {
+	rows, err := q.db.Query(ctx, exportUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExportUsersRow
+	for rows.Next() {
+		var i ExportUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.Address,
+			&i.UserType,
+			&i.ProfileHeadline,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// QueryHook runs before every query. It may replace the context the query
+// runs with and returns a function called once the query is finished,
+// with its error if any. For Query that is when the rows are closed, for
+// QueryRow when the row is scanned, as pgx reports most errors only then.
+type QueryHook func(ctx context.Context, query string) (context.Context, func(err error))
+
+type hookedDB struct {
+	db   DBTX
+	hook QueryHook
+}
+
+// WithHook wraps db so hook sees every query sent through it.
+func WithHook(db DBTX, hook QueryHook) DBTX {
+	return &hookedDB{db: db, hook: hook}
+}
+
+func (h *hookedDB) Exec(ctx context.Context, query string, args ...interface{}) (pgconn.CommandTag, error) {
+	ctx, done := h.hook(ctx, query)
+	tag, err := h.db.Exec(ctx, query, args...)
+	done(err)
+	return tag, err
+}
+
+func (h *hookedDB) Query(ctx context.Context, query string, args ...interface{}) (pgx.Rows, error) {
+	ctx, done := h.hook(ctx, query)
+	rows, err := h.db.Query(ctx, query, args...)
+	if err != nil {
+		done(err)
+		return rows, err
+	}
+	return &hookedRows{Rows: rows, done: done}, nil
+}
+
+func (h *hookedDB) QueryRow(ctx context.Context, query string, args ...interface{}) pgx.Row {
+	ctx, done := h.hook(ctx, query)
+	return &hookedRow{row: h.db.QueryRow(ctx, query, args...), done: done}
+}
+
+type hookedRows struct {
+	pgx.Rows
+	done   func(error)
+	closed bool
+}
+
+func (r *hookedRows) Close() {
+	r.Rows.Close()
+	if !r.closed {
+		r.closed = true
+		r.done(r.Rows.Err())
+	}
+}
+
+type hookedRow struct {
+	row  pgx.Row
+	done func(error)
+}
+
+func (r *hookedRow) Scan(dest ...interface{}) error {
+	err := r.row.Scan(dest...)
+	r.done(err)
+	return err
+}
+
+// WithTimeouts bounds every query by a deadline so a slow statement
+// cannot hold a connection forever. Queries named in perQuery (by their
+// sqlc name) use that timeout instead of def. A zero timeout disables it.
+func WithTimeouts(db DBTX, def time.Duration, perQuery map[string]time.Duration) DBTX {
+	return WithHook(db, func(ctx context.Context, query string) (context.Context, func(error)) {
+		timeout, ok := perQuery[QueryName(query)]
+		if !ok {
+			timeout = def
+		}
+		if timeout <= 0 {
+			return ctx, func(error) {}
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		return ctx, func(error) { cancel() }
+	})
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// deadlineDB records the deadline each query was sent with.
+type deadlineDB struct {
+	left time.Duration
+}
+
+func (d *deadlineDB) Exec(ctx context.Context, query string, args ...interface{}) (pgconn.CommandTag, error) {
+	d.left = 0
+	if deadline, ok := ctx.Deadline(); ok {
+		d.left = time.Until(deadline)
+	}
+	return pgconn.CommandTag{}, ctx.Err()
+}
+
+func (d *deadlineDB) Query(context.Context, string, ...interface{}) (pgx.Rows, error) {
+	return nil, nil
+}
+
+func (d *deadlineDB) QueryRow(context.Context, string, ...interface{}) pgx.Row {
+	return nil
+}
+
+func TestWithTimeouts(t *testing.T) {
+	inner := &deadlineDB{}
+	db := WithTimeouts(inner, time.Second, map[string]time.Duration{
+		"ExportJobs": time.Minute,
+		"ListJobs":   0,
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		query    string
+		min, max time.Duration
+	}{
+		{"-- name: GetUser :one\nSELECT 1", time.Second / 2, time.Second},
+		{"-- name: ExportJobs :many\nSELECT 1", 59 * time.Second, time.Minute},
+		{"-- name: ListJobs :many\nSELECT 1", 0, 0},
+	}
+	for _, tt := range tests {
+		if _, err := db.Exec(ctx, tt.query); err != nil {
+			t.Fatal(err)
+		}
+		if inner.left < tt.min || inner.left > tt.max {
+			t.Errorf("%s ran with %s left, want between %s and %s", QueryName(tt.query), inner.left, tt.min, tt.max)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: inbox.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const countUnreadNotifications = `-- name: CountUnreadNotifications :one
+SELECT COUNT(*)
+FROM notifications
+WHERE user_id = $1 AND read_at IS NULL
+`
+
+func (q *Queries) CountUnreadNotifications(ctx context.Context, userID int32) (int64, error) {
+	row := q.db.QueryRow(ctx, countUnreadNotifications, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createNotification = `-- name: CreateNotification :exec
+INSERT INTO notifications (user_id, kind, title, body, data, event_id, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (user_id, event_id) DO NOTHING
+`
+
+type CreateNotificationParams struct {
+	UserID    int32
+	Kind      string
+	Title     string
+	Body      string
+	Data      []byte
+	EventID   string
+	CreatedAt time.Time
+}
+
+func (q *Queries) CreateNotification(ctx context.Context, arg CreateNotificationParams) error {
+	_, err := q.db.Exec(ctx, createNotification,
+		arg.UserID,
+		arg.Kind,
+		arg.Title,
+		arg.Body,
+		arg.Data,
+		arg.EventID,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const listNotifications = `-- name: ListNotifications :many
+SELECT id, kind, title, body, data, created_at, read_at
+FROM notifications
+WHERE user_id = $1 AND ($2::bigint = 0 OR id < $2)
+ORDER BY id DESC
+LIMIT $3
+`
+
+type ListNotificationsParams struct {
+	UserID           int32
+	Before           int64
+	MaxNotifications int32
+}
+
+type ListNotificationsRow struct {
+	ID        int64
+	Kind      string
+	Title     string
+	Body      string
+	Data      []byte
+	CreatedAt time.Time
+	ReadAt    pgtype.Timestamp
+}
+
+// Newest first. A before of 0 starts from the newest.
+func (q *Queries) ListNotifications(ctx context.Context, arg ListNotificationsParams) ([]ListNotificationsRow, error) {
+	rows, err := q.db.Query(ctx, listNotifications, arg.UserID, arg.Before, arg.MaxNotifications)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListNotificationsRow
+	for rows.Next() {
+		var i ListNotificationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.Title,
+			&i.Body,
+			&i.Data,
+			&i.CreatedAt,
+			&i.ReadAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markAllNotificationsRead = `-- name: MarkAllNotificationsRead :execrows
+UPDATE notifications
+SET read_at = $1
+WHERE user_id = $2 AND read_at IS NULL
+`
+
+type MarkAllNotificationsReadParams struct {
+	ReadAt pgtype.Timestamp
+	UserID int32
+}
+
+func (q *Queries) MarkAllNotificationsRead(ctx context.Context, arg MarkAllNotificationsReadParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markAllNotificationsRead, arg.ReadAt, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const markNotificationsRead = `-- name: MarkNotificationsRead :execrows
+UPDATE notifications
+SET read_at = $1
+WHERE user_id = $2 AND id = ANY($3::bigint[]) AND read_at IS NULL
+`
+
+type MarkNotificationsReadParams struct {
+	ReadAt pgtype.Timestamp
+	UserID int32
+	Ids    []int64
+}
+
+func (q *Queries) MarkNotificationsRead(ctx context.Context, arg MarkNotificationsReadParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markNotificationsRead, arg.ReadAt, arg.UserID, arg.Ids)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
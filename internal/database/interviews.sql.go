@@ -0,0 +1,389 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: interviews.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const addInterviewer = `-- name: AddInterviewer :exec
+INSERT INTO interview_interviewers (interview_id, user_id)
+VALUES ($1, $2)
+`
+
+type AddInterviewerParams struct {
+	InterviewID int32
+	UserID      int32
+}
+
+func (q *Queries) AddInterviewer(ctx context.Context, arg AddInterviewerParams) error {
+	_, err := q.db.Exec(ctx, addInterviewer, arg.InterviewID, arg.UserID)
+	return err
+}
+
+const createInterview = `-- name: CreateInterview :one
+INSERT INTO interviews (application_id, starts_at, ends_at, location, video_url, created_by, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+RETURNING id, application_id, starts_at, ends_at, location, video_url, status, reschedule_note, sequence, created_by, created_at, updated_at
+`
+
+type CreateInterviewParams struct {
+	ApplicationID int32
+	StartsAt      time.Time
+	EndsAt        time.Time
+	Location      string
+	VideoUrl      string
+	CreatedBy     int32
+	CreatedAt     time.Time
+}
+
+func (q *Queries) CreateInterview(ctx context.Context, arg CreateInterviewParams) (Interview, error) {
+	row := q.db.QueryRow(ctx, createInterview,
+		arg.ApplicationID,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.Location,
+		arg.VideoUrl,
+		arg.CreatedBy,
+		arg.CreatedAt,
+	)
+	var i Interview
+	err := row.Scan(
+		&i.ID,
+		&i.ApplicationID,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Location,
+		&i.VideoUrl,
+		&i.Status,
+		&i.RescheduleNote,
+		&i.Sequence,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const findInterviewConflicts = `-- name: FindInterviewConflicts :many
+SELECT i.id, ii.user_id, i.starts_at, i.ends_at
+FROM interviews i
+JOIN interview_interviewers ii ON ii.interview_id = i.id
+WHERE ii.user_id = ANY($1::int[])
+  AND i.status <> 'cancelled'
+  AND i.id <> $2
+  AND i.starts_at < $3
+  AND i.ends_at > $4
+ORDER BY ii.user_id, i.starts_at
+`
+
+type FindInterviewConflictsParams struct {
+	InterviewerIds []int32
+	ExcludeID      int32
+	EndsAt         time.Time
+	StartsAt       time.Time
+}
+
+type FindInterviewConflictsRow struct {
+	ID       int32
+	UserID   int32
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+// Interviews that are not cancelled, overlap the slot and share one of
+// the interviewers, other than the interview being moved.
+func (q *Queries) FindInterviewConflicts(ctx context.Context, arg FindInterviewConflictsParams) ([]FindInterviewConflictsRow, error) {
+	rows, err := q.db.Query(ctx, findInterviewConflicts,
+		arg.InterviewerIds,
+		arg.ExcludeID,
+		arg.EndsAt,
+		arg.StartsAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindInterviewConflictsRow
+	for rows.Next() {
+		var i FindInterviewConflictsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.StartsAt,
+			&i.EndsAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getInterview = `-- name: GetInterview :one
+SELECT i.id, i.application_id, i.starts_at, i.ends_at, i.location, i.video_url, i.status, i.reschedule_note, i.sequence, i.created_by, i.created_at, i.updated_at, a.applicant_id, a.job_id
+FROM interviews i
+JOIN apply_jobs a ON a.id = i.application_id
+WHERE i.id = $1
+`
+
+type GetInterviewRow struct {
+	Interview   Interview
+	ApplicantID pgtype.Int4
+	JobID       pgtype.Int4
+}
+
+func (q *Queries) GetInterview(ctx context.Context, id int32) (GetInterviewRow, error) {
+	row := q.db.QueryRow(ctx, getInterview, id)
+	var i GetInterviewRow
+	err := row.Scan(
+		&i.Interview.ID,
+		&i.Interview.ApplicationID,
+		&i.Interview.StartsAt,
+		&i.Interview.EndsAt,
+		&i.Interview.Location,
+		&i.Interview.VideoUrl,
+		&i.Interview.Status,
+		&i.Interview.RescheduleNote,
+		&i.Interview.Sequence,
+		&i.Interview.CreatedBy,
+		&i.Interview.CreatedAt,
+		&i.Interview.UpdatedAt,
+		&i.ApplicantID,
+		&i.JobID,
+	)
+	return i, err
+}
+
+const listInterviewers = `-- name: ListInterviewers :many
+SELECT u.id, u.name, u.email
+FROM interview_interviewers ii
+JOIN users u ON u.id = ii.user_id
+WHERE ii.interview_id = $1
+ORDER BY u.id
+`
+
+type ListInterviewersRow struct {
+	ID    int32
+	Name  string
+	Email string
+}
+
+func (q *Queries) ListInterviewers(ctx context.Context, interviewID int32) ([]ListInterviewersRow, error) {
+	rows, err := q.db.Query(ctx, listInterviewers, interviewID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListInterviewersRow
+	for rows.Next() {
+		var i ListInterviewersRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.Email); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInterviewsForApplicant = `-- name: ListInterviewsForApplicant :many
+SELECT i.id, i.application_id, i.starts_at, i.ends_at, i.location, i.video_url, i.status, i.reschedule_note, i.sequence, i.created_by, i.created_at, i.updated_at, a.job_id
+FROM interviews i
+JOIN apply_jobs a ON a.id = i.application_id
+WHERE a.applicant_id = $1
+ORDER BY i.starts_at, i.id
+`
+
+type ListInterviewsForApplicantRow struct {
+	Interview Interview
+	JobID     pgtype.Int4
+}
+
+func (q *Queries) ListInterviewsForApplicant(ctx context.Context, applicantID pgtype.Int4) ([]ListInterviewsForApplicantRow, error) {
+	rows, err := q.db.Query(ctx, listInterviewsForApplicant, applicantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListInterviewsForApplicantRow
+	for rows.Next() {
+		var i ListInterviewsForApplicantRow
+		if err := rows.Scan(
+			&i.Interview.ID,
+			&i.Interview.ApplicationID,
+			&i.Interview.StartsAt,
+			&i.Interview.EndsAt,
+			&i.Interview.Location,
+			&i.Interview.VideoUrl,
+			&i.Interview.Status,
+			&i.Interview.RescheduleNote,
+			&i.Interview.Sequence,
+			&i.Interview.CreatedBy,
+			&i.Interview.CreatedAt,
+			&i.Interview.UpdatedAt,
+			&i.JobID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInterviewsForApplication = `-- name: ListInterviewsForApplication :many
+SELECT id, application_id, starts_at, ends_at, location, video_url, status, reschedule_note, sequence, created_by, created_at, updated_at
+FROM interviews
+WHERE application_id = $1
+ORDER BY starts_at, id
+`
+
+func (q *Queries) ListInterviewsForApplication(ctx context.Context, applicationID int32) ([]Interview, error) {
+	rows, err := q.db.Query(ctx, listInterviewsForApplication, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Interview
+	for rows.Next() {
+		var i Interview
+		if err := rows.Scan(
+			&i.ID,
+			&i.ApplicationID,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.Location,
+			&i.VideoUrl,
+			&i.Status,
+			&i.RescheduleNote,
+			&i.Sequence,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeInterviewers = `-- name: RemoveInterviewers :exec
+DELETE FROM interview_interviewers
+WHERE interview_id = $1
+`
+
+func (q *Queries) RemoveInterviewers(ctx context.Context, interviewID int32) error {
+	_, err := q.db.Exec(ctx, removeInterviewers, interviewID)
+	return err
+}
+
+const rescheduleInterview = `-- name: RescheduleInterview :one
+UPDATE interviews
+SET starts_at = $2,
+    ends_at = $3,
+    location = $4,
+    video_url = $5,
+    status = 'scheduled',
+    reschedule_note = '',
+    sequence = sequence + 1,
+    updated_at = $6
+WHERE id = $1
+RETURNING id, application_id, starts_at, ends_at, location, video_url, status, reschedule_note, sequence, created_by, created_at, updated_at
+`
+
+type RescheduleInterviewParams struct {
+	ID        int32
+	StartsAt  time.Time
+	EndsAt    time.Time
+	Location  string
+	VideoUrl  string
+	UpdatedAt time.Time
+}
+
+// Moves an interview, which the applicant then has to confirm again.
+func (q *Queries) RescheduleInterview(ctx context.Context, arg RescheduleInterviewParams) (Interview, error) {
+	row := q.db.QueryRow(ctx, rescheduleInterview,
+		arg.ID,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.Location,
+		arg.VideoUrl,
+		arg.UpdatedAt,
+	)
+	var i Interview
+	err := row.Scan(
+		&i.ID,
+		&i.ApplicationID,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Location,
+		&i.VideoUrl,
+		&i.Status,
+		&i.RescheduleNote,
+		&i.Sequence,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateInterviewStatus = `-- name: UpdateInterviewStatus :one
+UPDATE interviews
+SET status = $2,
+    reschedule_note = $3,
+    sequence = sequence + 1,
+    updated_at = $4
+WHERE id = $1
+RETURNING id, application_id, starts_at, ends_at, location, video_url, status, reschedule_note, sequence, created_by, created_at, updated_at
+`
+
+type UpdateInterviewStatusParams struct {
+	ID             int32
+	Status         InterviewStatus
+	RescheduleNote string
+	UpdatedAt      time.Time
+}
+
+func (q *Queries) UpdateInterviewStatus(ctx context.Context, arg UpdateInterviewStatusParams) (Interview, error) {
+	row := q.db.QueryRow(ctx, updateInterviewStatus,
+		arg.ID,
+		arg.Status,
+		arg.RescheduleNote,
+		arg.UpdatedAt,
+	)
+	var i Interview
+	err := row.Scan(
+		&i.ID,
+		&i.ApplicationID,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Location,
+		&i.VideoUrl,
+		&i.Status,
+		&i.RescheduleNote,
+		&i.Sequence,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
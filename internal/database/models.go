@@ -5,12 +5,147 @@
 package database
 
 import (
-	"database/sql"
 	"database/sql/driver"
 	"fmt"
 	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type ApplicationStatus string
+
+const (
+	ApplicationStatusApplied      ApplicationStatus = "applied"
+	ApplicationStatusReviewing    ApplicationStatus = "reviewing"
+	ApplicationStatusInterviewing ApplicationStatus = "interviewing"
+	ApplicationStatusOffered      ApplicationStatus = "offered"
+	ApplicationStatusHired        ApplicationStatus = "hired"
+	ApplicationStatusRejected     ApplicationStatus = "rejected"
 )
 
+func (e *ApplicationStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ApplicationStatus(s)
+	case string:
+		*e = ApplicationStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ApplicationStatus: %T", src)
+	}
+	return nil
+}
+
+type NullApplicationStatus struct {
+	ApplicationStatus ApplicationStatus
+	Valid             bool // Valid is true if ApplicationStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullApplicationStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ApplicationStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ApplicationStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullApplicationStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ApplicationStatus), nil
+}
+
+type InterviewStatus string
+
+const (
+	InterviewStatusScheduled           InterviewStatus = "scheduled"
+	InterviewStatusConfirmed           InterviewStatus = "confirmed"
+	InterviewStatusRescheduleRequested InterviewStatus = "reschedule_requested"
+	InterviewStatusCancelled           InterviewStatus = "cancelled"
+)
+
+func (e *InterviewStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = InterviewStatus(s)
+	case string:
+		*e = InterviewStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for InterviewStatus: %T", src)
+	}
+	return nil
+}
+
+type NullInterviewStatus struct {
+	InterviewStatus InterviewStatus
+	Valid           bool // Valid is true if InterviewStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullInterviewStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.InterviewStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.InterviewStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullInterviewStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.InterviewStatus), nil
+}
+
+type Recommendation string
+
+const (
+	RecommendationStrongNo  Recommendation = "strong_no"
+	RecommendationNo        Recommendation = "no"
+	RecommendationYes       Recommendation = "yes"
+	RecommendationStrongYes Recommendation = "strong_yes"
+)
+
+func (e *Recommendation) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = Recommendation(s)
+	case string:
+		*e = Recommendation(s)
+	default:
+		return fmt.Errorf("unsupported scan type for Recommendation: %T", src)
+	}
+	return nil
+}
+
+type NullRecommendation struct {
+	Recommendation Recommendation
+	Valid          bool // Valid is true if Recommendation is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullRecommendation) Scan(value interface{}) error {
+	if value == nil {
+		ns.Recommendation, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.Recommendation.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullRecommendation) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.Recommendation), nil
+}
+
 type UserType string
 
 const (
@@ -53,9 +188,75 @@ func (ns NullUserType) Value() (driver.Value, error) {
 	return string(ns.UserType), nil
 }
 
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryStatusDead      WebhookDeliveryStatus = "dead"
+)
+
+func (e *WebhookDeliveryStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WebhookDeliveryStatus(s)
+	case string:
+		*e = WebhookDeliveryStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WebhookDeliveryStatus: %T", src)
+	}
+	return nil
+}
+
+type NullWebhookDeliveryStatus struct {
+	WebhookDeliveryStatus WebhookDeliveryStatus
+	Valid                 bool // Valid is true if WebhookDeliveryStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWebhookDeliveryStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.WebhookDeliveryStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WebhookDeliveryStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWebhookDeliveryStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WebhookDeliveryStatus), nil
+}
+
 type ApplyJob struct {
-	ApplicantID sql.NullInt32
-	JobID       sql.NullInt32
+	ApplicantID pgtype.Int4
+	JobID       pgtype.Int4
+	ID          int32
+	Status      ApplicationStatus
+	AppliedAt   time.Time
+}
+
+type Interview struct {
+	ID             int32
+	ApplicationID  int32
+	StartsAt       time.Time
+	EndsAt         time.Time
+	Location       string
+	VideoUrl       string
+	Status         InterviewStatus
+	RescheduleNote string
+	Sequence       int32
+	CreatedBy      int32
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type InterviewInterviewer struct {
+	InterviewID int32
+	UserID      int32
 }
 
 type Job struct {
@@ -63,19 +264,77 @@ type Job struct {
 	Title             string
 	Description       string
 	PostedOn          time.Time
-	TotalApplications sql.NullInt32
+	TotalApplications pgtype.Int4
 	CompanyName       string
 	PostedBy          int32
+	ClosedAt          pgtype.Timestamp
+}
+
+type Notification struct {
+	ID        int64
+	UserID    int32
+	Kind      string
+	Title     string
+	Body      string
+	Data      []byte
+	EventID   string
+	CreatedAt time.Time
+	ReadAt    pgtype.Timestamp
+}
+
+type NotificationPreference struct {
+	UserID             int32
+	ApplicationUpdates bool
+	ApplicantDigest    bool
+	DigestSentAt       pgtype.Timestamp
+}
+
+type Outbox struct {
+	ID            int64
+	EventID       string
+	EventType     string
+	Payload       []byte
+	CreatedAt     time.Time
+	PublishedAt   pgtype.Timestamp
+	Attempts      int32
+	NextAttemptAt time.Time
+	LastError     pgtype.Text
 }
 
 type Profile struct {
 	Applicant         int32
-	ResumeFileAddress sql.NullString
-	Skills            sql.NullString
-	Education         sql.NullString
-	Name              sql.NullString
-	Email             sql.NullString
-	Phone             sql.NullString
+	ResumeFileAddress pgtype.Text
+	Skills            pgtype.Text
+	Education         pgtype.Text
+	Name              pgtype.Text
+	Email             pgtype.Text
+	Phone             pgtype.Text
+}
+
+type ScorecardFeedback struct {
+	ID             int32
+	ApplicationID  int32
+	InterviewerID  int32
+	Ratings        []byte
+	Recommendation NullRecommendation
+	Notes          string
+	SubmittedAt    pgtype.Timestamp
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type ScorecardTemplate struct {
+	JobID     int32
+	Criteria  []byte
+	UpdatedBy int32
+	UpdatedAt time.Time
+}
+
+type SentEmail struct {
+	UserID  int32
+	Kind    string
+	EventID string
+	SentAt  time.Time
 }
 
 type User struct {
@@ -86,5 +345,29 @@ type User struct {
 	UserType        UserType
 	PasswordHash    string
 	ProfileHeadline string
-	ProfileID       sql.NullInt32
+	ProfileID       pgtype.Int4
+}
+
+type WebhookDelivery struct {
+	ID             int64
+	SubscriptionID int32
+	EventID        string
+	EventType      string
+	Payload        []byte
+	Status         WebhookDeliveryStatus
+	Attempts       int32
+	NextAttemptAt  time.Time
+	LastAttemptAt  pgtype.Timestamp
+	LastStatusCode pgtype.Int4
+	LastError      pgtype.Text
+	CreatedAt      time.Time
+}
+
+type WebhookSubscription struct {
+	ID        int32
+	Url       string
+	Secret    string
+	Events    []string
+	CreatedBy int32
+	CreatedAt time.Time
 }
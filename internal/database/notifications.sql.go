@@ -0,0 +1,243 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: notifications.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const claimDigest = `-- name: ClaimDigest :execrows
+INSERT INTO notification_preferences (user_id, digest_sent_at)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET digest_sent_at = EXCLUDED.digest_sent_at
+WHERE notification_preferences.digest_sent_at IS NULL
+   OR notification_preferences.digest_sent_at <= $3
+`
+
+type ClaimDigestParams struct {
+	UserID       int32
+	DigestSentAt pgtype.Timestamp
+	DueBefore    pgtype.Timestamp
+}
+
+// Marks the digest of a user as sent at digest_sent_at unless another
+// process did since due_before.
+func (q *Queries) ClaimDigest(ctx context.Context, arg ClaimDigestParams) (int64, error) {
+	result, err := q.db.Exec(ctx, claimDigest, arg.UserID, arg.DigestSentAt, arg.DueBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const emailSent = `-- name: EmailSent :one
+SELECT EXISTS (
+    SELECT 1 FROM sent_emails
+    WHERE user_id = $1 AND kind = $2 AND event_id = $3
+)
+`
+
+type EmailSentParams struct {
+	UserID  int32
+	Kind    string
+	EventID string
+}
+
+func (q *Queries) EmailSent(ctx context.Context, arg EmailSentParams) (bool, error) {
+	row := q.db.QueryRow(ctx, emailSent, arg.UserID, arg.Kind, arg.EventID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const getNotificationRecipient = `-- name: GetNotificationRecipient :one
+SELECT u.id, u.name, u.email, u.user_type,
+       COALESCE(p.application_updates, TRUE)::boolean AS application_updates,
+       COALESCE(p.applicant_digest, TRUE)::boolean AS applicant_digest
+FROM users u
+LEFT JOIN notification_preferences p ON p.user_id = u.id
+WHERE u.id = $1
+`
+
+type GetNotificationRecipientRow struct {
+	ID                 int32
+	Name               string
+	Email              string
+	UserType           UserType
+	ApplicationUpdates bool
+	ApplicantDigest    bool
+}
+
+func (q *Queries) GetNotificationRecipient(ctx context.Context, id int32) (GetNotificationRecipientRow, error) {
+	row := q.db.QueryRow(ctx, getNotificationRecipient, id)
+	var i GetNotificationRecipientRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.UserType,
+		&i.ApplicationUpdates,
+		&i.ApplicantDigest,
+	)
+	return i, err
+}
+
+const listApplicationsForPoster = `-- name: ListApplicationsForPoster :many
+SELECT a.id, j.title AS job_title, u.name AS applicant_name,
+       u.email AS applicant_email, a.applied_at
+FROM apply_jobs a
+JOIN job j ON j.id = a.job_id
+JOIN users u ON u.id = a.applicant_id
+WHERE j.posted_by = $1
+  AND a.applied_at > $2 AND a.applied_at <= $3
+ORDER BY a.applied_at, a.id
+`
+
+type ListApplicationsForPosterParams struct {
+	PostedBy int32
+	Since    time.Time
+	Until    time.Time
+}
+
+type ListApplicationsForPosterRow struct {
+	ID             int32
+	JobTitle       string
+	ApplicantName  string
+	ApplicantEmail string
+	AppliedAt      time.Time
+}
+
+func (q *Queries) ListApplicationsForPoster(ctx context.Context, arg ListApplicationsForPosterParams) ([]ListApplicationsForPosterRow, error) {
+	rows, err := q.db.Query(ctx, listApplicationsForPoster, arg.PostedBy, arg.Since, arg.Until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListApplicationsForPosterRow
+	for rows.Next() {
+		var i ListApplicationsForPosterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobTitle,
+			&i.ApplicantName,
+			&i.ApplicantEmail,
+			&i.AppliedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDigestRecipients = `-- name: ListDigestRecipients :many
+SELECT u.id, u.name, u.email, p.digest_sent_at
+FROM users u
+LEFT JOIN notification_preferences p ON p.user_id = u.id
+WHERE u.user_type = 'admin'
+  AND COALESCE(p.applicant_digest, TRUE)
+  AND (p.digest_sent_at IS NULL OR p.digest_sent_at <= $1)
+ORDER BY u.id
+`
+
+type ListDigestRecipientsRow struct {
+	ID           int32
+	Name         string
+	Email        string
+	DigestSentAt pgtype.Timestamp
+}
+
+// Admins who want the digest and have not had one since due_before.
+func (q *Queries) ListDigestRecipients(ctx context.Context, dueBefore pgtype.Timestamp) ([]ListDigestRecipientsRow, error) {
+	rows, err := q.db.Query(ctx, listDigestRecipients, dueBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDigestRecipientsRow
+	for rows.Next() {
+		var i ListDigestRecipientsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.DigestSentAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordSentEmail = `-- name: RecordSentEmail :exec
+INSERT INTO sent_emails (user_id, kind, event_id, sent_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT DO NOTHING
+`
+
+type RecordSentEmailParams struct {
+	UserID  int32
+	Kind    string
+	EventID string
+	SentAt  time.Time
+}
+
+func (q *Queries) RecordSentEmail(ctx context.Context, arg RecordSentEmailParams) error {
+	_, err := q.db.Exec(ctx, recordSentEmail,
+		arg.UserID,
+		arg.Kind,
+		arg.EventID,
+		arg.SentAt,
+	)
+	return err
+}
+
+const setDigestSentAt = `-- name: SetDigestSentAt :exec
+INSERT INTO notification_preferences (user_id, digest_sent_at)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET digest_sent_at = EXCLUDED.digest_sent_at
+`
+
+type SetDigestSentAtParams struct {
+	UserID       int32
+	DigestSentAt pgtype.Timestamp
+}
+
+func (q *Queries) SetDigestSentAt(ctx context.Context, arg SetDigestSentAtParams) error {
+	_, err := q.db.Exec(ctx, setDigestSentAt, arg.UserID, arg.DigestSentAt)
+	return err
+}
+
+const setNotificationPreferences = `-- name: SetNotificationPreferences :exec
+INSERT INTO notification_preferences (user_id, application_updates, applicant_digest)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id) DO UPDATE
+SET application_updates = EXCLUDED.application_updates,
+    applicant_digest = EXCLUDED.applicant_digest
+`
+
+type SetNotificationPreferencesParams struct {
+	UserID             int32
+	ApplicationUpdates bool
+	ApplicantDigest    bool
+}
+
+func (q *Queries) SetNotificationPreferences(ctx context.Context, arg SetNotificationPreferencesParams) error {
+	_, err := q.db.Exec(ctx, setNotificationPreferences, arg.UserID, arg.ApplicationUpdates, arg.ApplicantDigest)
+	return err
+}
@@ -0,0 +1,157 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: outbox.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const claimOutboxEvents = `-- name: ClaimOutboxEvents :many
+UPDATE outbox
+SET next_attempt_at = $1
+WHERE id IN (
+    SELECT o.id FROM outbox o
+    WHERE o.published_at IS NULL AND o.next_attempt_at <= $2
+    ORDER BY o.id
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, event_id, event_type, payload, attempts, created_at
+`
+
+type ClaimOutboxEventsParams struct {
+	LeaseUntil time.Time
+	Now        time.Time
+	MaxEvents  int32
+}
+
+type ClaimOutboxEventsRow struct {
+	ID        int64
+	EventID   string
+	EventType string
+	Payload   []byte
+	Attempts  int32
+	CreatedAt time.Time
+}
+
+// Leases unpublished events until lease_until so concurrent dispatchers
+// skip them.
+func (q *Queries) ClaimOutboxEvents(ctx context.Context, arg ClaimOutboxEventsParams) ([]ClaimOutboxEventsRow, error) {
+	rows, err := q.db.Query(ctx, claimOutboxEvents, arg.LeaseUntil, arg.Now, arg.MaxEvents)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClaimOutboxEventsRow
+	for rows.Next() {
+		var i ClaimOutboxEventsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Attempts,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createOutboxEvent = `-- name: CreateOutboxEvent :exec
+INSERT INTO outbox (event_id, event_type, payload, created_at, next_attempt_at)
+VALUES ($1, $2, $3, $4, $4)
+`
+
+type CreateOutboxEventParams struct {
+	EventID   string
+	EventType string
+	Payload   []byte
+	CreatedAt time.Time
+}
+
+func (q *Queries) CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) error {
+	_, err := q.db.Exec(ctx, createOutboxEvent,
+		arg.EventID,
+		arg.EventType,
+		arg.Payload,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const deletePublishedOutboxEvents = `-- name: DeletePublishedOutboxEvents :execrows
+DELETE FROM outbox
+WHERE published_at < $1
+`
+
+func (q *Queries) DeletePublishedOutboxEvents(ctx context.Context, publishedAt pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, deletePublishedOutboxEvents, publishedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const markOutboxPublished = `-- name: MarkOutboxPublished :exec
+UPDATE outbox
+SET published_at = $2,
+    attempts = attempts + 1,
+    last_error = NULL
+WHERE id = $1
+`
+
+type MarkOutboxPublishedParams struct {
+	ID          int64
+	PublishedAt pgtype.Timestamp
+}
+
+func (q *Queries) MarkOutboxPublished(ctx context.Context, arg MarkOutboxPublishedParams) error {
+	_, err := q.db.Exec(ctx, markOutboxPublished, arg.ID, arg.PublishedAt)
+	return err
+}
+
+const notifyEvent = `-- name: NotifyEvent :exec
+SELECT pg_notify($1::text, $2::text)
+`
+
+type NotifyEventParams struct {
+	Channel string
+	Payload string
+}
+
+// Delivered to listeners when the transaction commits, or not at all.
+func (q *Queries) NotifyEvent(ctx context.Context, arg NotifyEventParams) error {
+	_, err := q.db.Exec(ctx, notifyEvent, arg.Channel, arg.Payload)
+	return err
+}
+
+const recordOutboxFailure = `-- name: RecordOutboxFailure :exec
+UPDATE outbox
+SET attempts = attempts + 1,
+    next_attempt_at = $2,
+    last_error = $3
+WHERE id = $1
+`
+
+type RecordOutboxFailureParams struct {
+	ID            int64
+	NextAttemptAt time.Time
+	LastError     pgtype.Text
+}
+
+func (q *Queries) RecordOutboxFailure(ctx context.Context, arg RecordOutboxFailureParams) error {
+	_, err := q.db.Exec(ctx, recordOutboxFailure, arg.ID, arg.NextAttemptAt, arg.LastError)
+	return err
+}
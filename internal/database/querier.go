@@ -0,0 +1,104 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type Querier interface {
+	AddInterviewer(ctx context.Context, arg AddInterviewerParams) error
+	AddProfileIDInUser(ctx context.Context, arg AddProfileIDInUserParams) error
+	ApplyJob(ctx context.Context, arg ApplyJobParams) (int32, error)
+	// Marks the digest of a user as sent at digest_sent_at unless another
+	// process did since due_before.
+	ClaimDigest(ctx context.Context, arg ClaimDigestParams) (int64, error)
+	// Leases unpublished events until lease_until so concurrent dispatchers
+	// skip them.
+	ClaimOutboxEvents(ctx context.Context, arg ClaimOutboxEventsParams) ([]ClaimOutboxEventsRow, error)
+	// Leases due deliveries until lease_until so concurrent dispatchers skip
+	// them, and returns what is needed to send them.
+	ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]ClaimWebhookDeliveriesRow, error)
+	CloseJob(ctx context.Context, arg CloseJobParams) (int64, error)
+	CountUnreadNotifications(ctx context.Context, userID int32) (int64, error)
+	CountUsers(ctx context.Context) (int64, error)
+	CreateApplicantProfile(ctx context.Context, applicant int32) (int32, error)
+	CreateInterview(ctx context.Context, arg CreateInterviewParams) (Interview, error)
+	CreateJob(ctx context.Context, arg CreateJobParams) (CreateJobRow, error)
+	CreateNotification(ctx context.Context, arg CreateNotificationParams) error
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) error
+	CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error)
+	CreateWebhook(ctx context.Context, arg CreateWebhookParams) (CreateWebhookRow, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error
+	DeletePublishedOutboxEvents(ctx context.Context, publishedAt pgtype.Timestamp) (int64, error)
+	DeleteWebhook(ctx context.Context, id int32) (int64, error)
+	EmailSent(ctx context.Context, arg EmailSentParams) (bool, error)
+	ExportApplications(ctx context.Context) ([]ApplyJob, error)
+	ExportJobs(ctx context.Context) ([]Job, error)
+	ExportProfiles(ctx context.Context) ([]Profile, error)
+	ExportUsers(ctx context.Context) ([]ExportUsersRow, error)
+	// Interviews that are not cancelled, overlap the slot and share one of
+	// the interviewers, other than the interview being moved.
+	FindInterviewConflicts(ctx context.Context, arg FindInterviewConflictsParams) ([]FindInterviewConflictsRow, error)
+	GetApplicant(ctx context.Context, id int32) (GetApplicantRow, error)
+	GetApplicants(ctx context.Context) ([]GetApplicantsRow, error)
+	GetApplication(ctx context.Context, id int32) (GetApplicationRow, error)
+	GetInterview(ctx context.Context, id int32) (GetInterviewRow, error)
+	GetJob(ctx context.Context, id int32) (GetJobRow, error)
+	GetJobsApplicant(ctx context.Context) ([]GetJobsApplicantRow, error)
+	GetNotificationRecipient(ctx context.Context, id int32) (GetNotificationRecipientRow, error)
+	GetResumeFileAddress(ctx context.Context, applicant int32) (pgtype.Text, error)
+	GetScorecardTemplate(ctx context.Context, jobID int32) (ScorecardTemplate, error)
+	GetUser(ctx context.Context, email string) (GetUserRow, error)
+	GetUserFromID(ctx context.Context, id int32) (UserType, error)
+	HasProfile(ctx context.Context, applicant int32) (bool, error)
+	// Whether a user interviews for an application, in an interview that
+	// was not cancelled.
+	IsInterviewer(ctx context.Context, arg IsInterviewerParams) (bool, error)
+	ListApplicationsForApplicant(ctx context.Context, applicantID pgtype.Int4) ([]ListApplicationsForApplicantRow, error)
+	ListApplicationsForPoster(ctx context.Context, arg ListApplicationsForPosterParams) ([]ListApplicationsForPosterRow, error)
+	ListDeadWebhookDeliveries(ctx context.Context, limit int32) ([]WebhookDelivery, error)
+	// Admins who want the digest and have not had one since due_before.
+	ListDigestRecipients(ctx context.Context, dueBefore pgtype.Timestamp) ([]ListDigestRecipientsRow, error)
+	ListFeedbackForApplicant(ctx context.Context, applicantID pgtype.Int4) ([]ListFeedbackForApplicantRow, error)
+	ListFeedbackForApplication(ctx context.Context, applicationID int32) ([]ListFeedbackForApplicationRow, error)
+	ListInterviewers(ctx context.Context, interviewID int32) ([]ListInterviewersRow, error)
+	ListInterviewsForApplicant(ctx context.Context, applicantID pgtype.Int4) ([]ListInterviewsForApplicantRow, error)
+	ListInterviewsForApplication(ctx context.Context, applicationID int32) ([]Interview, error)
+	ListJobs(ctx context.Context) ([]ListJobsRow, error)
+	// Newest first. A before of 0 starts from the newest.
+	ListNotifications(ctx context.Context, arg ListNotificationsParams) ([]ListNotificationsRow, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhooks(ctx context.Context) ([]ListWebhooksRow, error)
+	ListWebhooksForEvent(ctx context.Context, eventType string) ([]int32, error)
+	MarkAllNotificationsRead(ctx context.Context, arg MarkAllNotificationsReadParams) (int64, error)
+	MarkNotificationsRead(ctx context.Context, arg MarkNotificationsReadParams) (int64, error)
+	MarkOutboxPublished(ctx context.Context, arg MarkOutboxPublishedParams) error
+	// Delivered to listeners when the transaction commits, or not at all.
+	NotifyEvent(ctx context.Context, arg NotifyEventParams) error
+	RecordOutboxFailure(ctx context.Context, arg RecordOutboxFailureParams) error
+	RecordSentEmail(ctx context.Context, arg RecordSentEmailParams) error
+	RecordWebhookAttempt(ctx context.Context, arg RecordWebhookAttemptParams) error
+	RemoveInterviewers(ctx context.Context, interviewID int32) error
+	// Moves an interview, which the applicant then has to confirm again.
+	RescheduleInterview(ctx context.Context, arg RescheduleInterviewParams) (Interview, error)
+	RetryWebhookDelivery(ctx context.Context, arg RetryWebhookDeliveryParams) (int64, error)
+	// Creates or replaces the feedback of an interviewer, unless it was
+	// already submitted.
+	SaveFeedback(ctx context.Context, arg SaveFeedbackParams) (ScorecardFeedback, error)
+	SetDigestSentAt(ctx context.Context, arg SetDigestSentAtParams) error
+	SetNotificationPreferences(ctx context.Context, arg SetNotificationPreferencesParams) error
+	SetScorecardTemplate(ctx context.Context, arg SetScorecardTemplateParams) (ScorecardTemplate, error)
+	SetUserType(ctx context.Context, arg SetUserTypeParams) (SetUserTypeRow, error)
+	UpdateApplicationStatus(ctx context.Context, arg UpdateApplicationStatusParams) (int64, error)
+	UpdateInterviewStatus(ctx context.Context, arg UpdateInterviewStatusParams) (Interview, error)
+	UpdatePasswordHash(ctx context.Context, arg UpdatePasswordHashParams) (int64, error)
+	UpdateProfile(ctx context.Context, arg UpdateProfileParams) (UpdateProfileRow, error)
+	UpdateTotalApplications(ctx context.Context, id int32) error
+}
+
+var _ Querier = (*Queries)(nil)
@@ -0,0 +1,17 @@
+package database
+
+import "strings"
+
+// QueryName extracts the name from the "-- name: X :kind" header sqlc
+// puts at the start of every generated query.
+func QueryName(query string) string {
+	rest, ok := strings.CutPrefix(query, "-- name: ")
+	if !ok {
+		return "unknown"
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return "unknown"
+	}
+	return fields[0]
+}
@@ -0,0 +1,17 @@
+package database
+
+import "testing"
+
+func TestQueryName(t *testing.T) {
+	tests := map[string]string{
+		"-- name: GetUser :one\nSELECT 1":      "GetUser",
+		"-- name: ApplyJob :exec\nINSERT INTO": "ApplyJob",
+		"SELECT 1":                             "unknown",
+		"-- name: ":                            "unknown",
+	}
+	for query, want := range tests {
+		if got := QueryName(query); got != want {
+			t.Errorf("QueryName(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: scorecards.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const getScorecardTemplate = `-- name: GetScorecardTemplate :one
+SELECT job_id, criteria, updated_by, updated_at
+FROM scorecard_templates
+WHERE job_id = $1
+`
+
+func (q *Queries) GetScorecardTemplate(ctx context.Context, jobID int32) (ScorecardTemplate, error) {
+	row := q.db.QueryRow(ctx, getScorecardTemplate, jobID)
+	var i ScorecardTemplate
+	err := row.Scan(
+		&i.JobID,
+		&i.Criteria,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const isInterviewer = `-- name: IsInterviewer :one
+SELECT EXISTS (
+    SELECT 1
+    FROM interviews i
+    JOIN interview_interviewers ii ON ii.interview_id = i.id
+    WHERE i.application_id = $1
+      AND ii.user_id = $2
+      AND i.status <> 'cancelled'
+)::boolean
+`
+
+type IsInterviewerParams struct {
+	ApplicationID int32
+	UserID        int32
+}
+
+// Whether a user interviews for an application, in an interview that
+// was not cancelled.
+func (q *Queries) IsInterviewer(ctx context.Context, arg IsInterviewerParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isInterviewer, arg.ApplicationID, arg.UserID)
+	var column_1 bool
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const listFeedbackForApplicant = `-- name: ListFeedbackForApplicant :many
+SELECT f.id, f.application_id, f.interviewer_id, f.ratings, f.recommendation, f.notes, f.submitted_at, f.created_at, f.updated_at, u.name AS interviewer_name
+FROM scorecard_feedback f
+JOIN users u ON u.id = f.interviewer_id
+JOIN apply_jobs a ON a.id = f.application_id
+WHERE a.applicant_id = $1
+ORDER BY f.application_id, f.submitted_at NULLS LAST, f.id
+`
+
+type ListFeedbackForApplicantRow struct {
+	ScorecardFeedback ScorecardFeedback
+	InterviewerName   string
+}
+
+func (q *Queries) ListFeedbackForApplicant(ctx context.Context, applicantID pgtype.Int4) ([]ListFeedbackForApplicantRow, error) {
+	rows, err := q.db.Query(ctx, listFeedbackForApplicant, applicantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFeedbackForApplicantRow
+	for rows.Next() {
+		var i ListFeedbackForApplicantRow
+		if err := rows.Scan(
+			&i.ScorecardFeedback.ID,
+			&i.ScorecardFeedback.ApplicationID,
+			&i.ScorecardFeedback.InterviewerID,
+			&i.ScorecardFeedback.Ratings,
+			&i.ScorecardFeedback.Recommendation,
+			&i.ScorecardFeedback.Notes,
+			&i.ScorecardFeedback.SubmittedAt,
+			&i.ScorecardFeedback.CreatedAt,
+			&i.ScorecardFeedback.UpdatedAt,
+			&i.InterviewerName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFeedbackForApplication = `-- name: ListFeedbackForApplication :many
+SELECT f.id, f.application_id, f.interviewer_id, f.ratings, f.recommendation, f.notes, f.submitted_at, f.created_at, f.updated_at, u.name AS interviewer_name
+FROM scorecard_feedback f
+JOIN users u ON u.id = f.interviewer_id
+WHERE f.application_id = $1
+ORDER BY f.submitted_at NULLS LAST, f.id
+`
+
+type ListFeedbackForApplicationRow struct {
+	ScorecardFeedback ScorecardFeedback
+	InterviewerName   string
+}
+
+func (q *Queries) ListFeedbackForApplication(ctx context.Context, applicationID int32) ([]ListFeedbackForApplicationRow, error) {
+	rows, err := q.db.Query(ctx, listFeedbackForApplication, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFeedbackForApplicationRow
+	for rows.Next() {
+		var i ListFeedbackForApplicationRow
+		if err := rows.Scan(
+			&i.ScorecardFeedback.ID,
+			&i.ScorecardFeedback.ApplicationID,
+			&i.ScorecardFeedback.InterviewerID,
+			&i.ScorecardFeedback.Ratings,
+			&i.ScorecardFeedback.Recommendation,
+			&i.ScorecardFeedback.Notes,
+			&i.ScorecardFeedback.SubmittedAt,
+			&i.ScorecardFeedback.CreatedAt,
+			&i.ScorecardFeedback.UpdatedAt,
+			&i.InterviewerName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const saveFeedback = `-- name: SaveFeedback :one
+INSERT INTO scorecard_feedback (application_id, interviewer_id, ratings, recommendation, notes, submitted_at, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+ON CONFLICT (application_id, interviewer_id) DO UPDATE
+SET ratings = EXCLUDED.ratings,
+    recommendation = EXCLUDED.recommendation,
+    notes = EXCLUDED.notes,
+    submitted_at = EXCLUDED.submitted_at,
+    updated_at = EXCLUDED.updated_at
+WHERE scorecard_feedback.submitted_at IS NULL
+RETURNING id, application_id, interviewer_id, ratings, recommendation, notes, submitted_at, created_at, updated_at
+`
+
+type SaveFeedbackParams struct {
+	ApplicationID  int32
+	InterviewerID  int32
+	Ratings        []byte
+	Recommendation NullRecommendation
+	Notes          string
+	SubmittedAt    pgtype.Timestamp
+	Now            time.Time
+}
+
+// Creates or replaces the feedback of an interviewer, unless it was
+// already submitted.
+func (q *Queries) SaveFeedback(ctx context.Context, arg SaveFeedbackParams) (ScorecardFeedback, error) {
+	row := q.db.QueryRow(ctx, saveFeedback,
+		arg.ApplicationID,
+		arg.InterviewerID,
+		arg.Ratings,
+		arg.Recommendation,
+		arg.Notes,
+		arg.SubmittedAt,
+		arg.Now,
+	)
+	var i ScorecardFeedback
+	err := row.Scan(
+		&i.ID,
+		&i.ApplicationID,
+		&i.InterviewerID,
+		&i.Ratings,
+		&i.Recommendation,
+		&i.Notes,
+		&i.SubmittedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const setScorecardTemplate = `-- name: SetScorecardTemplate :one
+INSERT INTO scorecard_templates (job_id, criteria, updated_by, updated_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (job_id) DO UPDATE
+SET criteria = EXCLUDED.criteria,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = EXCLUDED.updated_at
+RETURNING job_id, criteria, updated_by, updated_at
+`
+
+type SetScorecardTemplateParams struct {
+	JobID     int32
+	Criteria  []byte
+	UpdatedBy int32
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetScorecardTemplate(ctx context.Context, arg SetScorecardTemplateParams) (ScorecardTemplate, error) {
+	row := q.db.QueryRow(ctx, setScorecardTemplate,
+		arg.JobID,
+		arg.Criteria,
+		arg.UpdatedBy,
+		arg.UpdatedAt,
+	)
+	var i ScorecardTemplate
+	err := row.Scan(
+		&i.JobID,
+		&i.Criteria,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
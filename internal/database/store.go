@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Store is the persistence layer the handlers depend on. PostgresStore
+// implements it on Postgres; memstore implements it in memory for tests.
+type Store interface {
+	Querier
+	// WithTx runs fn in a transaction that commits only if fn returns
+	// nil. fn may run more than once, so it must not have side effects
+	// outside the database.
+	WithTx(ctx context.Context, fn func(q Querier) error) error
+}
+
+// TxBeginner is a DBTX that can also start transactions, such as
+// *pgxpool.Pool.
+type TxBeginner interface {
+	DBTX
+	BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error)
+}
+
+// maxTxAttempts bounds how often WithTx retries after a serialization
+// failure before giving up.
+const maxTxAttempts = 3
+
+type PostgresStore struct {
+	*Queries
+	db   TxBeginner
+	wrap func(DBTX) DBTX
+}
+
+var _ Store = (*PostgresStore)(nil)
+
+// NewStore builds a Store on db. wrap, when not nil, instruments every
+// connection queries run on, inside transactions too.
+func NewStore(db TxBeginner, wrap func(DBTX) DBTX) *PostgresStore {
+	if wrap == nil {
+		wrap = func(db DBTX) DBTX { return db }
+	}
+	return &PostgresStore{Queries: New(wrap(db)), db: db, wrap: wrap}
+}
+
+// WithTx runs fn in a serializable transaction and retries it with a
+// short backoff when Postgres aborts it with a serialization failure or
+// a deadlock.
+func (s *PostgresStore) WithTx(ctx context.Context, fn func(q Querier) error) error {
+	backoff := 10 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		err := s.runTx(ctx, fn)
+		if err == nil || !isRetryable(err) || attempt == maxTxAttempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (s *PostgresStore) runTx(ctx context.Context, fn func(q Querier) error) error {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	// Rollback after a successful Commit is a no-op.
+	defer tx.Rollback(context.WithoutCancel(ctx))
+
+	if err := fn(s.Queries.WithTx(wrappedTx{Tx: tx, db: s.wrap(tx)})); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
+
+// wrappedTx sends the queries of a transaction through the same wrappers
+// as queries outside of one.
+type wrappedTx struct {
+	pgx.Tx
+	db DBTX
+}
+
+func (t wrappedTx) Exec(ctx context.Context, query string, args ...interface{}) (pgconn.CommandTag, error) {
+	return t.db.Exec(ctx, query, args...)
+}
+
+func (t wrappedTx) Query(ctx context.Context, query string, args ...interface{}) (pgx.Rows, error) {
+	return t.db.Query(ctx, query, args...)
+}
+
+func (t wrappedTx) QueryRow(ctx context.Context, query string, args ...interface{}) pgx.Row {
+	return t.db.QueryRow(ctx, query, args...)
+}
+
+func isRetryable(err error) bool {
+	pgErr := &pgconn.PgError{}
+	// 40001 serialization_failure, 40P01 deadlock_detected.
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+}
+
+// IsUniqueViolation reports whether err is a unique constraint violation,
+// e.g. signing up with an email that is already taken.
+func IsUniqueViolation(err error) bool {
+	pgErr := &pgconn.PgError{}
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// IsForeignKeyViolation reports whether err is a foreign key violation,
+// e.g. referencing a user that was deleted.
+func IsForeignKeyViolation(err error) bool {
+	pgErr := &pgconn.PgError{}
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeTx fails its first commits with the given errors.
+type fakeTx struct {
+	pgx.Tx
+	db *fakeBeginner
+}
+
+func (t fakeTx) Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error) {
+	t.db.execs++
+	return pgconn.CommandTag{}, nil
+}
+
+func (t fakeTx) Commit(context.Context) error {
+	if len(t.db.commitErrs) > 0 {
+		err := t.db.commitErrs[0]
+		t.db.commitErrs = t.db.commitErrs[1:]
+		return err
+	}
+	t.db.commits++
+	return nil
+}
+
+func (t fakeTx) Rollback(context.Context) error {
+	return nil
+}
+
+type fakeBeginner struct {
+	DBTX
+	begins, execs, commits int
+	commitErrs             []error
+}
+
+func (b *fakeBeginner) BeginTx(context.Context, pgx.TxOptions) (pgx.Tx, error) {
+	b.begins++
+	return fakeTx{db: b}, nil
+}
+
+func applyOnce(ctx context.Context, q Querier) error {
+	return q.UpdateTotalApplications(ctx, 1)
+}
+
+func TestWithTxRetriesSerializationFailures(t *testing.T) {
+	conflict := &pgconn.PgError{Code: "40001"}
+	db := &fakeBeginner{commitErrs: []error{conflict}}
+	wrapped := 0
+	store := NewStore(db, func(db DBTX) DBTX { wrapped++; return db })
+
+	ctx := context.Background()
+	if err := store.WithTx(ctx, func(q Querier) error { return applyOnce(ctx, q) }); err != nil {
+		t.Fatal(err)
+	}
+	if db.begins != 2 || db.execs != 2 || db.commits != 1 {
+		t.Errorf("begins=%d execs=%d commits=%d, want 2, 2, 1", db.begins, db.execs, db.commits)
+	}
+	// Once for the store itself and once per transaction.
+	if wrapped != 3 {
+		t.Errorf("wrap called %d times, want 3", wrapped)
+	}
+
+	db = &fakeBeginner{commitErrs: []error{conflict, conflict, conflict}}
+	store = NewStore(db, nil)
+	err := store.WithTx(ctx, func(q Querier) error { return applyOnce(ctx, q) })
+	if !errors.As(err, new(*pgconn.PgError)) || db.begins != maxTxAttempts {
+		t.Errorf("err=%v after %d attempts, want the conflict after %d", err, db.begins, maxTxAttempts)
+	}
+}
+
+func TestWithTxDoesNotRetryOtherErrors(t *testing.T) {
+	db := &fakeBeginner{}
+	store := NewStore(db, nil)
+	boom := errors.New("boom")
+
+	err := store.WithTx(context.Background(), func(Querier) error { return boom })
+	if err != boom || db.begins != 1 || db.commits != 0 {
+		t.Errorf("err=%v begins=%d commits=%d", err, db.begins, db.commits)
+	}
+}
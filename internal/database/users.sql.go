@@ -7,33 +7,74 @@ package database
 
 import (
 	"context"
-	"database/sql"
 	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const addProfileIDInUser = `-- name: AddProfileIDInUser :exec
 UPDATE users
 SET profile_id = $1
+WHERE id = $2
 `
 
-func (q *Queries) AddProfileIDInUser(ctx context.Context, profileID sql.NullInt32) error {
-	_, err := q.db.ExecContext(ctx, addProfileIDInUser, profileID)
+type AddProfileIDInUserParams struct {
+	ProfileID pgtype.Int4
+	ID        int32
+}
+
+func (q *Queries) AddProfileIDInUser(ctx context.Context, arg AddProfileIDInUserParams) error {
+	_, err := q.db.Exec(ctx, addProfileIDInUser, arg.ProfileID, arg.ID)
 	return err
 }
 
-const applyJob = `-- name: ApplyJob :exec
-INSERT INTO apply_jobs (applicant_id, job_id)
-VALUES ($1, $2)
+const applyJob = `-- name: ApplyJob :one
+INSERT INTO apply_jobs (applicant_id, job_id, applied_at)
+VALUES ($1, $2, $3)
+RETURNING id
 `
 
 type ApplyJobParams struct {
-	ApplicantID sql.NullInt32
-	JobID       sql.NullInt32
+	ApplicantID pgtype.Int4
+	JobID       pgtype.Int4
+	AppliedAt   time.Time
 }
 
-func (q *Queries) ApplyJob(ctx context.Context, arg ApplyJobParams) error {
-	_, err := q.db.ExecContext(ctx, applyJob, arg.ApplicantID, arg.JobID)
-	return err
+func (q *Queries) ApplyJob(ctx context.Context, arg ApplyJobParams) (int32, error) {
+	row := q.db.QueryRow(ctx, applyJob, arg.ApplicantID, arg.JobID, arg.AppliedAt)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const closeJob = `-- name: CloseJob :execrows
+UPDATE job
+SET closed_at = $2
+WHERE id = $1 AND closed_at IS NULL
+`
+
+type CloseJobParams struct {
+	ID       int32
+	ClosedAt pgtype.Timestamp
+}
+
+func (q *Queries) CloseJob(ctx context.Context, arg CloseJobParams) (int64, error) {
+	result, err := q.db.Exec(ctx, closeJob, arg.ID, arg.ClosedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const countUsers = `-- name: CountUsers :one
+SELECT COUNT(*) FROM users
+`
+
+func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countUsers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
 }
 
 const createApplicantProfile = `-- name: CreateApplicantProfile :one
@@ -43,7 +84,7 @@ RETURNING applicant
 `
 
 func (q *Queries) CreateApplicantProfile(ctx context.Context, applicant int32) (int32, error) {
-	row := q.db.QueryRowContext(ctx, createApplicantProfile, applicant)
+	row := q.db.QueryRow(ctx, createApplicantProfile, applicant)
 	err := row.Scan(&applicant)
 	return applicant, err
 }
@@ -72,7 +113,7 @@ type CreateJobRow struct {
 }
 
 func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (CreateJobRow, error) {
-	row := q.db.QueryRowContext(ctx, createJob,
+	row := q.db.QueryRow(ctx, createJob,
 		arg.Title,
 		arg.Description,
 		arg.PostedOn,
@@ -114,7 +155,7 @@ type CreateUserRow struct {
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error) {
-	row := q.db.QueryRowContext(ctx, createUser,
+	row := q.db.QueryRow(ctx, createUser,
 		arg.Name,
 		arg.Email,
 		arg.Address,
@@ -144,14 +185,14 @@ type GetApplicantRow struct {
 	Email             string
 	Address           string
 	ProfileHeadline   string
-	ResumeFileAddress sql.NullString
-	Skills            sql.NullString
-	Education         sql.NullString
-	Phone             sql.NullString
+	ResumeFileAddress pgtype.Text
+	Skills            pgtype.Text
+	Education         pgtype.Text
+	Phone             pgtype.Text
 }
 
 func (q *Queries) GetApplicant(ctx context.Context, id int32) (GetApplicantRow, error) {
-	row := q.db.QueryRowContext(ctx, getApplicant, id)
+	row := q.db.QueryRow(ctx, getApplicant, id)
 	var i GetApplicantRow
 	err := row.Scan(
 		&i.Name,
@@ -180,7 +221,7 @@ type GetApplicantsRow struct {
 }
 
 func (q *Queries) GetApplicants(ctx context.Context) ([]GetApplicantsRow, error) {
-	rows, err := q.db.QueryContext(ctx, getApplicants)
+	rows, err := q.db.Query(ctx, getApplicants)
 	if err != nil {
 		return nil, err
 	}
@@ -198,17 +239,41 @@ func (q *Queries) GetApplicants(ctx context.Context) ([]GetApplicantsRow, error)
 		}
 		items = append(items, i)
 	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 	return items, nil
 }
 
+const getApplication = `-- name: GetApplication :one
+SELECT id, applicant_id, job_id, status, applied_at
+FROM apply_jobs
+WHERE id = $1
+`
+
+type GetApplicationRow struct {
+	ID          int32
+	ApplicantID pgtype.Int4
+	JobID       pgtype.Int4
+	Status      ApplicationStatus
+	AppliedAt   time.Time
+}
+
+func (q *Queries) GetApplication(ctx context.Context, id int32) (GetApplicationRow, error) {
+	row := q.db.QueryRow(ctx, getApplication, id)
+	var i GetApplicationRow
+	err := row.Scan(
+		&i.ID,
+		&i.ApplicantID,
+		&i.JobID,
+		&i.Status,
+		&i.AppliedAt,
+	)
+	return i, err
+}
+
 const getJob = `-- name: GetJob :one
-SELECT title, description, posted_on, company_name, posted_by, total_applications
+SELECT title, description, posted_on, company_name, posted_by, total_applications, closed_at
 FROM job
 WHERE id = $1
 `
@@ -219,11 +284,12 @@ type GetJobRow struct {
 	PostedOn          time.Time
 	CompanyName       string
 	PostedBy          int32
-	TotalApplications sql.NullInt32
+	TotalApplications pgtype.Int4
+	ClosedAt          pgtype.Timestamp
 }
 
 func (q *Queries) GetJob(ctx context.Context, id int32) (GetJobRow, error) {
-	row := q.db.QueryRowContext(ctx, getJob, id)
+	row := q.db.QueryRow(ctx, getJob, id)
 	var i GetJobRow
 	err := row.Scan(
 		&i.Title,
@@ -232,6 +298,7 @@ func (q *Queries) GetJob(ctx context.Context, id int32) (GetJobRow, error) {
 		&i.CompanyName,
 		&i.PostedBy,
 		&i.TotalApplications,
+		&i.ClosedAt,
 	)
 	return i, err
 }
@@ -239,19 +306,20 @@ func (q *Queries) GetJob(ctx context.Context, id int32) (GetJobRow, error) {
 const getJobsApplicant = `-- name: GetJobsApplicant :many
 SELECT title, description, posted_on, total_applications, company_name, posted_by
 FROM job
+WHERE closed_at IS NULL
 `
 
 type GetJobsApplicantRow struct {
 	Title             string
 	Description       string
 	PostedOn          time.Time
-	TotalApplications sql.NullInt32
+	TotalApplications pgtype.Int4
 	CompanyName       string
 	PostedBy          int32
 }
 
 func (q *Queries) GetJobsApplicant(ctx context.Context) ([]GetJobsApplicantRow, error) {
-	rows, err := q.db.QueryContext(ctx, getJobsApplicant)
+	rows, err := q.db.Query(ctx, getJobsApplicant)
 	if err != nil {
 		return nil, err
 	}
@@ -271,18 +339,27 @@ func (q *Queries) GetJobsApplicant(ctx context.Context) ([]GetJobsApplicantRow,
 		}
 		items = append(items, i)
 	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 	return items, nil
 }
 
+const getResumeFileAddress = `-- name: GetResumeFileAddress :one
+SELECT resume_file_address FROM profile
+WHERE applicant = $1
+`
+
+func (q *Queries) GetResumeFileAddress(ctx context.Context, applicant int32) (pgtype.Text, error) {
+	row := q.db.QueryRow(ctx, getResumeFileAddress, applicant)
+	var resume_file_address pgtype.Text
+	err := row.Scan(&resume_file_address)
+	return resume_file_address, err
+}
+
 const getUser = `-- name: GetUser :one
 SELECT id, password_hash, user_type FROM users
-WHERE email = $1
+WHERE lower(email) = lower($1)
 `
 
 type GetUserRow struct {
@@ -292,7 +369,7 @@ type GetUserRow struct {
 }
 
 func (q *Queries) GetUser(ctx context.Context, email string) (GetUserRow, error) {
-	row := q.db.QueryRowContext(ctx, getUser, email)
+	row := q.db.QueryRow(ctx, getUser, email)
 	var i GetUserRow
 	err := row.Scan(&i.ID, &i.PasswordHash, &i.UserType)
 	return i, err
@@ -304,12 +381,169 @@ WHERE id = $1
 `
 
 func (q *Queries) GetUserFromID(ctx context.Context, id int32) (UserType, error) {
-	row := q.db.QueryRowContext(ctx, getUserFromID, id)
+	row := q.db.QueryRow(ctx, getUserFromID, id)
 	var user_type UserType
 	err := row.Scan(&user_type)
 	return user_type, err
 }
 
+const hasProfile = `-- name: HasProfile :one
+SELECT EXISTS (SELECT 1 FROM profile WHERE applicant = $1)
+`
+
+func (q *Queries) HasProfile(ctx context.Context, applicant int32) (bool, error) {
+	row := q.db.QueryRow(ctx, hasProfile, applicant)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listApplicationsForApplicant = `-- name: ListApplicationsForApplicant :many
+SELECT a.id, a.job_id, j.title, a.status, a.applied_at
+FROM apply_jobs a
+JOIN job j ON j.id = a.job_id
+WHERE a.applicant_id = $1
+ORDER BY a.id
+`
+
+type ListApplicationsForApplicantRow struct {
+	ID        int32
+	JobID     pgtype.Int4
+	Title     string
+	Status    ApplicationStatus
+	AppliedAt time.Time
+}
+
+func (q *Queries) ListApplicationsForApplicant(ctx context.Context, applicantID pgtype.Int4) ([]ListApplicationsForApplicantRow, error) {
+	rows, err := q.db.Query(ctx, listApplicationsForApplicant, applicantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListApplicationsForApplicantRow
+	for rows.Next() {
+		var i ListApplicationsForApplicantRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.Title,
+			&i.Status,
+			&i.AppliedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobs = `-- name: ListJobs :many
+SELECT id, title, company_name, posted_on, total_applications, closed_at
+FROM job
+ORDER BY id
+`
+
+type ListJobsRow struct {
+	ID                int32
+	Title             string
+	CompanyName       string
+	PostedOn          time.Time
+	TotalApplications pgtype.Int4
+	ClosedAt          pgtype.Timestamp
+}
+
+func (q *Queries) ListJobs(ctx context.Context) ([]ListJobsRow, error) {
+	rows, err := q.db.Query(ctx, listJobs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListJobsRow
+	for rows.Next() {
+		var i ListJobsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.CompanyName,
+			&i.PostedOn,
+			&i.TotalApplications,
+			&i.ClosedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setUserType = `-- name: SetUserType :one
+UPDATE users
+SET user_type = $1
+WHERE lower(email) = lower($2)
+RETURNING id, user_type
+`
+
+type SetUserTypeParams struct {
+	UserType UserType
+	Email    string
+}
+
+type SetUserTypeRow struct {
+	ID       int32
+	UserType UserType
+}
+
+func (q *Queries) SetUserType(ctx context.Context, arg SetUserTypeParams) (SetUserTypeRow, error) {
+	row := q.db.QueryRow(ctx, setUserType, arg.UserType, arg.Email)
+	var i SetUserTypeRow
+	err := row.Scan(&i.ID, &i.UserType)
+	return i, err
+}
+
+const updateApplicationStatus = `-- name: UpdateApplicationStatus :execrows
+UPDATE apply_jobs
+SET status = $2
+WHERE id = $1
+`
+
+type UpdateApplicationStatusParams struct {
+	ID     int32
+	Status ApplicationStatus
+}
+
+func (q *Queries) UpdateApplicationStatus(ctx context.Context, arg UpdateApplicationStatusParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateApplicationStatus, arg.ID, arg.Status)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updatePasswordHash = `-- name: UpdatePasswordHash :execrows
+UPDATE users
+SET password_hash = $1
+WHERE lower(email) = lower($2)
+`
+
+type UpdatePasswordHashParams struct {
+	PasswordHash string
+	Email        string
+}
+
+func (q *Queries) UpdatePasswordHash(ctx context.Context, arg UpdatePasswordHashParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updatePasswordHash, arg.PasswordHash, arg.Email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateProfile = `-- name: UpdateProfile :one
 UPDATE profile
 SET name = $1, email = $2, phone=$3, skills = $4, education = $5, resume_file_address = $6
@@ -318,25 +552,25 @@ RETURNING name, email, phone, skills, education
 `
 
 type UpdateProfileParams struct {
-	Name              sql.NullString
-	Email             sql.NullString
-	Phone             sql.NullString
-	Skills            sql.NullString
-	Education         sql.NullString
-	ResumeFileAddress sql.NullString
+	Name              pgtype.Text
+	Email             pgtype.Text
+	Phone             pgtype.Text
+	Skills            pgtype.Text
+	Education         pgtype.Text
+	ResumeFileAddress pgtype.Text
 	Applicant         int32
 }
 
 type UpdateProfileRow struct {
-	Name      sql.NullString
-	Email     sql.NullString
-	Phone     sql.NullString
-	Skills    sql.NullString
-	Education sql.NullString
+	Name      pgtype.Text
+	Email     pgtype.Text
+	Phone     pgtype.Text
+	Skills    pgtype.Text
+	Education pgtype.Text
 }
 
 func (q *Queries) UpdateProfile(ctx context.Context, arg UpdateProfileParams) (UpdateProfileRow, error) {
-	row := q.db.QueryRowContext(ctx, updateProfile,
+	row := q.db.QueryRow(ctx, updateProfile,
 		arg.Name,
 		arg.Email,
 		arg.Phone,
@@ -363,6 +597,6 @@ WHERE id = $1
 `
 
 func (q *Queries) UpdateTotalApplications(ctx context.Context, id int32) error {
-	_, err := q.db.ExecContext(ctx, updateTotalApplications, id)
+	_, err := q.db.Exec(ctx, updateTotalApplications, id)
 	return err
 }
@@ -0,0 +1,358 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: webhooks.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const claimWebhookDeliveries = `-- name: ClaimWebhookDeliveries :many
+UPDATE webhook_deliveries d
+SET next_attempt_at = $1
+FROM webhook_subscriptions s
+WHERE s.id = d.subscription_id AND d.id IN (
+    SELECT w.id FROM webhook_deliveries w
+    WHERE w.status = 'pending' AND w.next_attempt_at <= $2
+    ORDER BY w.next_attempt_at
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING d.id, d.event_id, d.event_type, d.payload, d.attempts, s.url, s.secret
+`
+
+type ClaimWebhookDeliveriesParams struct {
+	LeaseUntil    time.Time
+	Now           time.Time
+	MaxDeliveries int32
+}
+
+type ClaimWebhookDeliveriesRow struct {
+	ID        int64
+	EventID   string
+	EventType string
+	Payload   []byte
+	Attempts  int32
+	Url       string
+	Secret    string
+}
+
+// Leases due deliveries until lease_until so concurrent dispatchers skip
+// them, and returns what is needed to send them.
+func (q *Queries) ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]ClaimWebhookDeliveriesRow, error) {
+	rows, err := q.db.Query(ctx, claimWebhookDeliveries, arg.LeaseUntil, arg.Now, arg.MaxDeliveries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClaimWebhookDeliveriesRow
+	for rows.Next() {
+		var i ClaimWebhookDeliveriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Attempts,
+			&i.Url,
+			&i.Secret,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createWebhook = `-- name: CreateWebhook :one
+INSERT INTO webhook_subscriptions (url, secret, events, created_by, created_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, url, events, created_by, created_at
+`
+
+type CreateWebhookParams struct {
+	Url       string
+	Secret    string
+	Events    []string
+	CreatedBy int32
+	CreatedAt time.Time
+}
+
+type CreateWebhookRow struct {
+	ID        int32
+	Url       string
+	Events    []string
+	CreatedBy int32
+	CreatedAt time.Time
+}
+
+func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (CreateWebhookRow, error) {
+	row := q.db.QueryRow(ctx, createWebhook,
+		arg.Url,
+		arg.Secret,
+		arg.Events,
+		arg.CreatedBy,
+		arg.CreatedAt,
+	)
+	var i CreateWebhookRow
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.Events,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, next_attempt_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $5)
+`
+
+type CreateWebhookDeliveryParams struct {
+	SubscriptionID int32
+	EventID        string
+	EventType      string
+	Payload        []byte
+	NextAttemptAt  time.Time
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error {
+	_, err := q.db.Exec(ctx, createWebhookDelivery,
+		arg.SubscriptionID,
+		arg.EventID,
+		arg.EventType,
+		arg.Payload,
+		arg.NextAttemptAt,
+	)
+	return err
+}
+
+const deleteWebhook = `-- name: DeleteWebhook :execrows
+DELETE FROM webhook_subscriptions
+WHERE id = $1
+`
+
+func (q *Queries) DeleteWebhook(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteWebhook, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const listDeadWebhookDeliveries = `-- name: ListDeadWebhookDeliveries :many
+SELECT id, subscription_id, event_id, event_type, payload, status, attempts,
+       next_attempt_at, last_attempt_at, last_status_code, last_error, created_at
+FROM webhook_deliveries
+WHERE status = 'dead'
+ORDER BY id DESC
+LIMIT $1
+`
+
+func (q *Queries) ListDeadWebhookDeliveries(ctx context.Context, limit int32) ([]WebhookDelivery, error) {
+	rows, err := q.db.Query(ctx, listDeadWebhookDeliveries, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastAttemptAt,
+			&i.LastStatusCode,
+			&i.LastError,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookDeliveries = `-- name: ListWebhookDeliveries :many
+SELECT id, subscription_id, event_id, event_type, payload, status, attempts,
+       next_attempt_at, last_attempt_at, last_status_code, last_error, created_at
+FROM webhook_deliveries
+WHERE subscription_id = $1
+ORDER BY id DESC
+LIMIT $2
+`
+
+type ListWebhookDeliveriesParams struct {
+	SubscriptionID int32
+	Limit          int32
+}
+
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.Query(ctx, listWebhookDeliveries, arg.SubscriptionID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastAttemptAt,
+			&i.LastStatusCode,
+			&i.LastError,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhooks = `-- name: ListWebhooks :many
+SELECT id, url, events, created_by, created_at
+FROM webhook_subscriptions
+ORDER BY id
+`
+
+type ListWebhooksRow struct {
+	ID        int32
+	Url       string
+	Events    []string
+	CreatedBy int32
+	CreatedAt time.Time
+}
+
+func (q *Queries) ListWebhooks(ctx context.Context) ([]ListWebhooksRow, error) {
+	rows, err := q.db.Query(ctx, listWebhooks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWebhooksRow
+	for rows.Next() {
+		var i ListWebhooksRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Events,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhooksForEvent = `-- name: ListWebhooksForEvent :many
+SELECT id
+FROM webhook_subscriptions
+WHERE $1::text = ANY(events)
+ORDER BY id
+`
+
+func (q *Queries) ListWebhooksForEvent(ctx context.Context, eventType string) ([]int32, error) {
+	rows, err := q.db.Query(ctx, listWebhooksForEvent, eventType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordWebhookAttempt = `-- name: RecordWebhookAttempt :exec
+UPDATE webhook_deliveries
+SET status = $2,
+    attempts = attempts + 1,
+    next_attempt_at = $3,
+    last_attempt_at = $4,
+    last_status_code = $5,
+    last_error = $6
+WHERE id = $1
+`
+
+type RecordWebhookAttemptParams struct {
+	ID             int64
+	Status         WebhookDeliveryStatus
+	NextAttemptAt  time.Time
+	LastAttemptAt  pgtype.Timestamp
+	LastStatusCode pgtype.Int4
+	LastError      pgtype.Text
+}
+
+func (q *Queries) RecordWebhookAttempt(ctx context.Context, arg RecordWebhookAttemptParams) error {
+	_, err := q.db.Exec(ctx, recordWebhookAttempt,
+		arg.ID,
+		arg.Status,
+		arg.NextAttemptAt,
+		arg.LastAttemptAt,
+		arg.LastStatusCode,
+		arg.LastError,
+	)
+	return err
+}
+
+const retryWebhookDelivery = `-- name: RetryWebhookDelivery :execrows
+UPDATE webhook_deliveries
+SET status = 'pending', attempts = 0, next_attempt_at = $2
+WHERE id = $1 AND status = 'dead'
+`
+
+type RetryWebhookDeliveryParams struct {
+	ID            int64
+	NextAttemptAt time.Time
+}
+
+func (q *Queries) RetryWebhookDelivery(ctx context.Context, arg RetryWebhookDeliveryParams) (int64, error) {
+	result, err := q.db.Exec(ctx, retryWebhookDelivery, arg.ID, arg.NextAttemptAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
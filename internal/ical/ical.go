@@ -0,0 +1,141 @@
+// Package ical writes iCalendar (RFC 5545) invites for single events, as
+// sent by an organizer to its attendees (RFC 5546).
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Methods of an invite.
+const (
+	MethodRequest = "REQUEST"
+	MethodCancel  = "CANCEL"
+)
+
+// Statuses of an event.
+const (
+	StatusTentative = "TENTATIVE"
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+// Participation statuses of an attendee.
+const (
+	PartStatNeedsAction = "NEEDS-ACTION"
+	PartStatAccepted    = "ACCEPTED"
+)
+
+type Person struct {
+	Name  string
+	Email string
+}
+
+type Attendee struct {
+	Person
+	PartStat string
+}
+
+// Event is one occurrence. UID stays the same across updates, which
+// carry a higher Sequence.
+type Event struct {
+	UID         string
+	Sequence    int
+	Stamp       time.Time
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Status      string
+	Organizer   Person
+	Attendees   []Attendee
+}
+
+// Encode returns an iCalendar object with the event. Times are written
+// in UTC.
+func Encode(method string, e Event) []byte {
+	w := &writer{}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", "-//Synlabs//Jobs//EN")
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", method)
+	w.line("BEGIN", "VEVENT")
+	w.line("UID", e.UID)
+	w.line("SEQUENCE", fmt.Sprint(e.Sequence))
+	w.line("DTSTAMP", utc(e.Stamp))
+	w.line("DTSTART", utc(e.Start))
+	w.line("DTEND", utc(e.End))
+	w.line("SUMMARY", text(e.Summary))
+	if e.Description != "" {
+		w.line("DESCRIPTION", text(e.Description))
+	}
+	if e.Location != "" {
+		w.line("LOCATION", text(e.Location))
+	}
+	if e.URL != "" {
+		w.line("URL", e.URL)
+	}
+	if e.Status != "" {
+		w.line("STATUS", e.Status)
+	}
+	w.line("ORGANIZER"+cn(e.Organizer.Name), "mailto:"+e.Organizer.Email)
+	for _, a := range e.Attendees {
+		params := ";ROLE=REQ-PARTICIPANT;PARTSTAT=" + a.PartStat
+		if a.PartStat == PartStatNeedsAction {
+			params += ";RSVP=TRUE"
+		}
+		w.line("ATTENDEE"+cn(a.Name)+params, "mailto:"+a.Email)
+	}
+	w.line("END", "VEVENT")
+	w.line("END", "VCALENDAR")
+	return w.buf.Bytes()
+}
+
+func utc(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// text escapes a TEXT value.
+func text(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// cn returns the common name parameter for a name, quoted since names
+// may hold commas or colons. Quotes cannot be escaped, so they are
+// dropped.
+func cn(name string) string {
+	if name == "" {
+		return ""
+	}
+	return `;CN="` + strings.ReplaceAll(name, `"`, "") + `"`
+}
+
+type writer struct {
+	buf bytes.Buffer
+}
+
+// line writes a content line folded to 75 octets, without splitting a
+// UTF-8 sequence.
+func (w *writer) line(name, value string) {
+	s := name + ":" + value
+	limit := 75
+	for len(s) > limit {
+		i := limit
+		for i > 0 && !utf8.RuneStart(s[i]) {
+			i--
+		}
+		w.buf.WriteString(s[:i])
+		w.buf.WriteString("\r\n ")
+		s = s[i:]
+		// The leading space of a continuation counts towards its length.
+		limit = 74
+	}
+	w.buf.WriteString(s)
+	w.buf.WriteString("\r\n")
+}
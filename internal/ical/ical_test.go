@@ -0,0 +1,77 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncode(t *testing.T) {
+	start := time.Date(2026, 10, 20, 15, 30, 0, 0, time.FixedZone("IST", 5*3600+1800))
+	got := string(Encode(MethodRequest, Event{
+		UID:         "interview-7@jobs.example.com",
+		Sequence:    2,
+		Stamp:       time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC),
+		Start:       start,
+		End:         start.Add(45 * time.Minute),
+		Summary:     "Interview: Backend, Acme",
+		Description: "Line one\nLine two; with \\ and ,",
+		Location:    "Room 4",
+		Status:      StatusTentative,
+		Organizer:   Person{Name: "Ann Admin", Email: "ann@example.com"},
+		Attendees: []Attendee{
+			{Person: Person{Name: `Bo "B" Applicant`, Email: "bo@example.com"}, PartStat: PartStatNeedsAction},
+			{Person: Person{Email: "cy@example.com"}, PartStat: PartStatAccepted},
+		},
+	}))
+
+	want := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Synlabs//Jobs//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:REQUEST",
+		"BEGIN:VEVENT",
+		"UID:interview-7@jobs.example.com",
+		"SEQUENCE:2",
+		"DTSTAMP:20261019T090000Z",
+		"DTSTART:20261020T100000Z",
+		"DTEND:20261020T104500Z",
+		`SUMMARY:Interview: Backend\, Acme`,
+		`DESCRIPTION:Line one\nLine two\; with \\ and \,`,
+		"LOCATION:Room 4",
+		"STATUS:TENTATIVE",
+		`ORGANIZER;CN="Ann Admin":mailto:ann@example.com`,
+		`ATTENDEE;CN="Bo B Applicant";ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSV`,
+		" P=TRUE:mailto:bo@example.com",
+		"ATTENDEE;ROLE=REQ-PARTICIPANT;PARTSTAT=ACCEPTED:mailto:cy@example.com",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+	if got != want {
+		t.Errorf("Encode =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestLinesAreFolded(t *testing.T) {
+	w := &writer{}
+	w.line("SUMMARY", strings.Repeat("é", 80))
+	lines := strings.Split(strings.TrimSuffix(w.buf.String(), "\r\n"), "\r\n")
+	if len(lines) != 3 {
+		t.Fatalf("lines = %q", lines)
+	}
+	unfolded := ""
+	for i, l := range lines {
+		if len(l) > 75 {
+			t.Errorf("line %d is %d octets", i, len(l))
+		}
+		if i > 0 {
+			l = strings.TrimPrefix(l, " ")
+		}
+		unfolded += l
+	}
+	if unfolded != "SUMMARY:"+strings.Repeat("é", 80) {
+		t.Errorf("unfolded = %q", unfolded)
+	}
+}
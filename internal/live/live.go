@@ -0,0 +1,166 @@
+// Package live fans events out to the server-sent event streams open on
+// this replica. Events arrive through Postgres LISTEN/NOTIFY, so every
+// replica sees the events committed by any of them.
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Channel is the Postgres channel events are sent on.
+const Channel = "synlabs_events"
+
+// MaxPayload is the largest payload Postgres accepts with NOTIFY, less
+// some room for the channel name.
+const MaxPayload = 7900
+
+// Event is an event envelope as sent with NOTIFY.
+type Event struct {
+	ID      string
+	Type    string
+	Payload []byte
+}
+
+// ParseEvent reads the ID and type of an event envelope.
+func ParseEvent(payload string) (Event, error) {
+	envelope := struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+	}{}
+	if err := json.Unmarshal([]byte(payload), &envelope); err != nil {
+		return Event{}, fmt.Errorf("decoding event: %w", err)
+	}
+	return Event{ID: envelope.ID, Type: envelope.Type, Payload: []byte(payload)}, nil
+}
+
+// subscriptionBuffer is how many events a subscriber may fall behind
+// before it is dropped.
+const subscriptionBuffer = 64
+
+// Broker hands every event published to all current subscribers.
+type Broker struct {
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+func NewBroker() *Broker {
+	return &Broker{subs: map[*Subscription]struct{}{}}
+}
+
+// Subscription receives events on C until it is closed, the broker is
+// closed or it falls too far behind; C is closed then.
+type Subscription struct {
+	C <-chan Event
+
+	c chan Event
+	b *Broker
+}
+
+func (b *Broker) Subscribe() *Subscription {
+	c := make(chan Event, subscriptionBuffer)
+	s := &Subscription{C: c, c: c, b: b}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(c)
+		return s
+	}
+	b.subs[s] = struct{}{}
+	return s
+}
+
+func (s *Subscription) Close() {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	s.b.remove(s)
+}
+
+func (b *Broker) remove(s *Subscription) {
+	if _, ok := b.subs[s]; ok {
+		delete(b.subs, s)
+		close(s.c)
+	}
+}
+
+// Publish never blocks. A subscriber whose buffer is full is dropped
+// instead, and its client reconnects.
+func (b *Broker) Publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subs {
+		select {
+		case s.c <- e:
+		default:
+			b.remove(s)
+		}
+	}
+}
+
+// Close ends every subscription, e.g. so that streams do not hold up a
+// shutdown. Later subscriptions end right away.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for s := range b.subs {
+		b.remove(s)
+	}
+}
+
+// Listen publishes the events sent on Channel to b until ctx is
+// cancelled. It holds one connection of pool, and reconnects with a
+// growing delay when it is lost. Events sent while it is disconnected
+// are missed.
+func Listen(ctx context.Context, pool *pgxpool.Pool, b *Broker, logger *slog.Logger) {
+	const maxBackoff = 30 * time.Second
+	backoff := time.Second
+	for {
+		err := listen(ctx, pool, b, logger, func() { backoff = time.Second })
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Error("listening for events", "error", err, "retry_in", backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxBackoff)
+	}
+}
+
+func listen(ctx context.Context, pool *pgxpool.Pool, b *Broker, logger *slog.Logger, connected func()) error {
+	pooled, err := pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// The connection keeps listening, so it must not go back to the pool.
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return err
+	}
+	connected()
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		e, err := ParseEvent(n.Payload)
+		if err != nil {
+			logger.Warn("skipping event", "error", err)
+			continue
+		}
+		b.Publish(e)
+	}
+}
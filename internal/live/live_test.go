@@ -0,0 +1,41 @@
+package live
+
+import "testing"
+
+func TestBroker(t *testing.T) {
+	b := NewBroker()
+	a, slow := b.Subscribe(), b.Subscribe()
+
+	e, err := ParseEvent(`{"id":"evt_1","type":"application.created","data":{}}`)
+	if err != nil || e.ID != "evt_1" || e.Type != "application.created" {
+		t.Fatalf("ParseEvent = %+v, %v", e, err)
+	}
+	b.Publish(e)
+	if got := <-a.C; got.ID != "evt_1" {
+		t.Errorf("got %+v", got)
+	}
+
+	// A subscriber that does not keep up is dropped, the others are not.
+	for i := 0; i < subscriptionBuffer; i++ {
+		b.Publish(e)
+		<-a.C
+	}
+	n := 0
+	for range slow.C {
+		n++
+	}
+	if n != subscriptionBuffer {
+		t.Errorf("slow subscriber got %d events before being dropped", n)
+	}
+
+	a.Close()
+	a.Close()
+	if _, ok := <-a.C; ok {
+		t.Error("closed subscription still open")
+	}
+
+	b.Close()
+	if _, ok := <-b.Subscribe().C; ok {
+		t.Error("subscribed to a closed broker")
+	}
+}
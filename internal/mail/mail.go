@@ -0,0 +1,210 @@
+// Package mail sends multipart emails with a plain text and an HTML
+// body and any attachments, over SMTP or to a log when no server is
+// configured.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	// Headers are added to the standard ones, e.g. List-Unsubscribe.
+	Headers     map[string]string
+	Attachments []Attachment
+}
+
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Encode renders msg as an RFC 5322 message from from, with the text
+// and HTML bodies as multipart/alternative parts. Attachments, if any,
+// follow them in a multipart/mixed body.
+func Encode(from string, msg Message, now time.Time) ([]byte, error) {
+	contentType, body, err := encodeBody(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	domain := "localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if i := strings.LastIndex(addr.Address, "@"); i >= 0 {
+			domain = addr.Address[i+1:]
+		}
+	}
+	headers := map[string]string{
+		"From":         from,
+		"To":           msg.To,
+		"Subject":      mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date":         now.Format(time.RFC1123Z),
+		"Message-ID":   "<" + hex.EncodeToString(id) + "@" + domain + ">",
+		"MIME-Version": "1.0",
+		"Content-Type": contentType,
+	}
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var out bytes.Buffer
+	for _, k := range keys {
+		fmt.Fprintf(&out, "%s: %s\r\n", k, headers[k])
+	}
+	out.WriteString("\r\n")
+	out.Write(body)
+	return out.Bytes(), nil
+}
+
+// encodeBody returns the content type and body of msg.
+func encodeBody(msg Message) (string, []byte, error) {
+	var alt bytes.Buffer
+	w := multipart.NewWriter(&alt)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		pw, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		qp := quotedprintable.NewWriter(pw)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return "", nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return "", nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return "", nil, err
+	}
+	altType := "multipart/alternative; boundary=" + strconv.Quote(w.Boundary())
+	if len(msg.Attachments) == 0 {
+		return altType, alt.Bytes(), nil
+	}
+
+	var mixed bytes.Buffer
+	mw := multipart.NewWriter(&mixed)
+	pw, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {altType}})
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err := pw.Write(alt.Bytes()); err != nil {
+		return "", nil, err
+	}
+	for _, a := range msg.Attachments {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {a.ContentType},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		// Encoded lines are at most 76 characters (RFC 2045).
+		encoded := base64.StdEncoding.EncodeToString(a.Data)
+		for len(encoded) > 76 {
+			fmt.Fprintf(pw, "%s\r\n", encoded[:76])
+			encoded = encoded[76:]
+		}
+		fmt.Fprintf(pw, "%s\r\n", encoded)
+	}
+	if err := mw.Close(); err != nil {
+		return "", nil, err
+	}
+	return "multipart/mixed; boundary=" + strconv.Quote(mw.Boundary()), mixed.Bytes(), nil
+}
+
+// SMTP sends through a server, upgrading to TLS when it offers STARTTLS.
+type SMTP struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTP returns a sender for host:port. It authenticates when
+// username is not empty.
+func NewSMTP(host string, port int, username, password, from string) *SMTP {
+	s := &SMTP{addr: net.JoinHostPort(host, strconv.Itoa(port)), from: from}
+	if username != "" {
+		s.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return s
+}
+
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	from, err := mail.ParseAddress(s.from)
+	if err != nil {
+		return fmt.Errorf("parsing sender: %w", err)
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("parsing recipient: %w", err)
+	}
+	data, err := Encode(s.from, msg, time.Now())
+	if err != nil {
+		return fmt.Errorf("encoding message: %w", err)
+	}
+
+	// smtp.SendMail takes no context, so it runs aside and is abandoned
+	// when ctx ends first.
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(s.addr, s.auth, from.Address, []string{to.Address}, data)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Log logs emails instead of sending them, for development.
+type Log struct {
+	Logger *slog.Logger
+}
+
+func (l Log) Send(ctx context.Context, msg Message) error {
+	l.Logger.InfoContext(ctx, "email not sent, no SMTP server configured",
+		"to", msg.To, "subject", msg.Subject, "text", msg.Text)
+	return nil
+}
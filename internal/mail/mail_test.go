@@ -0,0 +1,117 @@
+package mail
+
+import (
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncode(t *testing.T) {
+	data, err := Encode("Synlabs <no-reply@example.com>", Message{
+		To:      "ann@example.com",
+		Subject: "Your application — received",
+		Text:    "Hi Ann,\nthanks for applying.",
+		HTML:    "<p>Hi Ann, thanks for applying.</p>",
+		Headers: map[string]string{"List-Unsubscribe": "<https://example.com/unsubscribe>"},
+	}, time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "Your application — received" {
+		t.Errorf("subject = %q, %v", subject, err)
+	}
+	if got := msg.Header.Get("List-Unsubscribe"); got != "<https://example.com/unsubscribe>" {
+		t.Errorf("List-Unsubscribe = %q", got)
+	}
+	if got := msg.Header.Get("Message-Id"); !strings.HasSuffix(got, "@example.com>") {
+		t.Errorf("Message-ID = %q", got)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, %v", mediaType, err)
+	}
+	parts := []string{}
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := r.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(quotedprintable.NewReader(p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts = append(parts, p.Header.Get("Content-Type")+": "+string(body))
+	}
+	want := []string{
+		"text/plain; charset=utf-8: Hi Ann,\r\nthanks for applying.",
+		"text/html; charset=utf-8: <p>Hi Ann, thanks for applying.</p>",
+	}
+	if strings.Join(parts, "|") != strings.Join(want, "|") {
+		t.Errorf("parts = %q", parts)
+	}
+}
+
+func TestEncodeAttachments(t *testing.T) {
+	invite := []byte(strings.Repeat("BEGIN:VCALENDAR\r\n", 10))
+	data, err := Encode("no-reply@example.com", Message{
+		To:          "ann@example.com",
+		Subject:     "Interview",
+		Text:        "See the invite.",
+		HTML:        "<p>See the invite.</p>",
+		Attachments: []Attachment{{Filename: "invite.ics", ContentType: "text/calendar; method=REQUEST", Data: invite}},
+	}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type = %q, %v", mediaType, err)
+	}
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	alt, err := r.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(alt.Header.Get("Content-Type")); mediaType != "multipart/alternative" {
+		t.Errorf("first part = %q", alt.Header.Get("Content-Type"))
+	}
+	att, err := r.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if att.FileName() != "invite.ics" || att.Header.Get("Content-Type") != "text/calendar; method=REQUEST" {
+		t.Errorf("attachment = %q, %q", att.FileName(), att.Header.Get("Content-Type"))
+	}
+	body, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, att))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != string(invite) {
+		t.Errorf("attachment body = %q", body)
+	}
+	if _, err := r.NextPart(); err != io.EOF {
+		t.Errorf("want two parts, got err %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+)
+
+func (s *Store) CreateNotification(ctx context.Context, arg database.CreateNotificationParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.UserID]; !ok {
+		return foreignKeyViolation("notifications_user_id_fkey")
+	}
+	for _, n := range s.notifications {
+		if n.UserID == arg.UserID && n.EventID == arg.EventID {
+			return nil
+		}
+	}
+	s.nextNotificationID++
+	s.notifications[s.nextNotificationID] = database.Notification{
+		ID:        s.nextNotificationID,
+		UserID:    arg.UserID,
+		Kind:      arg.Kind,
+		Title:     arg.Title,
+		Body:      arg.Body,
+		Data:      arg.Data,
+		EventID:   arg.EventID,
+		CreatedAt: arg.CreatedAt,
+	}
+	return nil
+}
+
+func (s *Store) ListNotifications(ctx context.Context, arg database.ListNotificationsParams) ([]database.ListNotificationsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := sortedKeys(s.notifications)
+	rows := []database.ListNotificationsRow{}
+	for i := len(ids) - 1; i >= 0 && len(rows) < int(arg.MaxNotifications); i-- {
+		n := s.notifications[ids[i]]
+		if n.UserID != arg.UserID || (arg.Before != 0 && n.ID >= arg.Before) {
+			continue
+		}
+		rows = append(rows, database.ListNotificationsRow{
+			ID:        n.ID,
+			Kind:      n.Kind,
+			Title:     n.Title,
+			Body:      n.Body,
+			Data:      n.Data,
+			CreatedAt: n.CreatedAt,
+			ReadAt:    n.ReadAt,
+		})
+	}
+	return rows, nil
+}
+
+func (s *Store) CountUnreadNotifications(ctx context.Context, userID int32) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count int64
+	for _, n := range s.notifications {
+		if n.UserID == userID && !n.ReadAt.Valid {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// markRead marks the unread notifications of a user matching keep as
+// read.
+func (s *Store) markRead(userID int32, readAt pgtype.Timestamp, keep func(int64) bool) int64 {
+	var n int64
+	for id, notification := range s.notifications {
+		if notification.UserID != userID || notification.ReadAt.Valid || !keep(id) {
+			continue
+		}
+		notification.ReadAt = readAt
+		s.notifications[id] = notification
+		n++
+	}
+	return n
+}
+
+func (s *Store) MarkNotificationsRead(ctx context.Context, arg database.MarkNotificationsReadParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.markRead(arg.UserID, arg.ReadAt, func(id int64) bool {
+		return slices.Contains(arg.Ids, id)
+	}), nil
+}
+
+func (s *Store) MarkAllNotificationsRead(ctx context.Context, arg database.MarkAllNotificationsReadParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.markRead(arg.UserID, arg.ReadAt, func(int64) bool { return true }), nil
+}
@@ -0,0 +1,193 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+)
+
+func (s *Store) application(id int32) (database.ApplyJob, bool) {
+	for _, a := range s.applications {
+		if a.ID == id {
+			return a, true
+		}
+	}
+	return database.ApplyJob{}, false
+}
+
+func (s *Store) CreateInterview(ctx context.Context, arg database.CreateInterviewParams) (database.Interview, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.application(arg.ApplicationID); !ok {
+		return database.Interview{}, foreignKeyViolation("interviews_application_id_fkey")
+	}
+	if _, ok := s.users[arg.CreatedBy]; !ok {
+		return database.Interview{}, foreignKeyViolation("interviews_created_by_fkey")
+	}
+	s.nextInterviewID++
+	i := database.Interview{
+		ID:            s.nextInterviewID,
+		ApplicationID: arg.ApplicationID,
+		StartsAt:      arg.StartsAt,
+		EndsAt:        arg.EndsAt,
+		Location:      arg.Location,
+		VideoUrl:      arg.VideoUrl,
+		Status:        database.InterviewStatusScheduled,
+		CreatedBy:     arg.CreatedBy,
+		CreatedAt:     arg.CreatedAt,
+		UpdatedAt:     arg.CreatedAt,
+	}
+	s.interviews[i.ID] = i
+	return i, nil
+}
+
+func (s *Store) AddInterviewer(ctx context.Context, arg database.AddInterviewerParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.interviews[arg.InterviewID]; !ok {
+		return foreignKeyViolation("interview_interviewers_interview_id_fkey")
+	}
+	if _, ok := s.users[arg.UserID]; !ok {
+		return foreignKeyViolation("interview_interviewers_user_id_fkey")
+	}
+	key := database.InterviewInterviewer{InterviewID: arg.InterviewID, UserID: arg.UserID}
+	if s.interviewers[key] {
+		return uniqueViolation("interview_interviewers_pkey")
+	}
+	s.interviewers[key] = true
+	return nil
+}
+
+func (s *Store) RemoveInterviewers(ctx context.Context, interviewID int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range s.interviewers {
+		if key.InterviewID == interviewID {
+			delete(s.interviewers, key)
+		}
+	}
+	return nil
+}
+
+func (s *Store) ListInterviewers(ctx context.Context, interviewID int32) ([]database.ListInterviewersRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := []database.ListInterviewersRow{}
+	for _, id := range sortedKeys(s.users) {
+		if s.interviewers[database.InterviewInterviewer{InterviewID: interviewID, UserID: id}] {
+			u := s.users[id]
+			rows = append(rows, database.ListInterviewersRow{ID: u.ID, Name: u.Name, Email: u.Email})
+		}
+	}
+	return rows, nil
+}
+
+func (s *Store) GetInterview(ctx context.Context, id int32) (database.GetInterviewRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.interviews[id]
+	if !ok {
+		return database.GetInterviewRow{}, pgx.ErrNoRows
+	}
+	a, _ := s.application(i.ApplicationID)
+	return database.GetInterviewRow{Interview: i, ApplicantID: a.ApplicantID, JobID: a.JobID}, nil
+}
+
+// sortedInterviews returns the interviews keep accepts by start time.
+func (s *Store) sortedInterviews(keep func(database.Interview) bool) []database.Interview {
+	rows := []database.Interview{}
+	for _, id := range sortedKeys(s.interviews) {
+		if i := s.interviews[id]; keep(i) {
+			rows = append(rows, i)
+		}
+	}
+	slices.SortStableFunc(rows, func(a, b database.Interview) int {
+		return a.StartsAt.Compare(b.StartsAt)
+	})
+	return rows
+}
+
+func (s *Store) ListInterviewsForApplication(ctx context.Context, applicationID int32) ([]database.Interview, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sortedInterviews(func(i database.Interview) bool {
+		return i.ApplicationID == applicationID
+	}), nil
+}
+
+func (s *Store) ListInterviewsForApplicant(ctx context.Context, applicantID pgtype.Int4) ([]database.ListInterviewsForApplicantRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := []database.ListInterviewsForApplicantRow{}
+	for _, i := range s.sortedInterviews(func(database.Interview) bool { return true }) {
+		a, _ := s.application(i.ApplicationID)
+		if a.ApplicantID.Valid && applicantID.Valid && a.ApplicantID.Int32 == applicantID.Int32 {
+			rows = append(rows, database.ListInterviewsForApplicantRow{Interview: i, JobID: a.JobID})
+		}
+	}
+	return rows, nil
+}
+
+func (s *Store) FindInterviewConflicts(ctx context.Context, arg database.FindInterviewConflictsParams) ([]database.FindInterviewConflictsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := []database.FindInterviewConflictsRow{}
+	for _, userID := range slices.Sorted(slices.Values(arg.InterviewerIds)) {
+		for _, i := range s.sortedInterviews(func(i database.Interview) bool {
+			return i.Status != database.InterviewStatusCancelled && i.ID != arg.ExcludeID &&
+				i.StartsAt.Before(arg.EndsAt) && i.EndsAt.After(arg.StartsAt) &&
+				s.interviewers[database.InterviewInterviewer{InterviewID: i.ID, UserID: userID}]
+		}) {
+			rows = append(rows, database.FindInterviewConflictsRow{ID: i.ID, UserID: userID, StartsAt: i.StartsAt, EndsAt: i.EndsAt})
+		}
+	}
+	return rows, nil
+}
+
+func (s *Store) RescheduleInterview(ctx context.Context, arg database.RescheduleInterviewParams) (database.Interview, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.interviews[arg.ID]
+	if !ok {
+		return database.Interview{}, pgx.ErrNoRows
+	}
+	i.StartsAt = arg.StartsAt
+	i.EndsAt = arg.EndsAt
+	i.Location = arg.Location
+	i.VideoUrl = arg.VideoUrl
+	i.Status = database.InterviewStatusScheduled
+	i.RescheduleNote = ""
+	i.Sequence++
+	i.UpdatedAt = arg.UpdatedAt
+	s.interviews[i.ID] = i
+	return i, nil
+}
+
+func (s *Store) UpdateInterviewStatus(ctx context.Context, arg database.UpdateInterviewStatusParams) (database.Interview, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.interviews[arg.ID]
+	if !ok {
+		return database.Interview{}, pgx.ErrNoRows
+	}
+	i.Status = arg.Status
+	i.RescheduleNote = arg.RescheduleNote
+	i.Sequence++
+	i.UpdatedAt = arg.UpdatedAt
+	s.interviews[i.ID] = i
+	return i, nil
+}
@@ -0,0 +1,617 @@
+// Package memstore is an in-memory database.Store for tests. It mirrors
+// the behaviour of the SQL queries closely enough that handlers cannot
+// tell the difference: missing rows return pgx.ErrNoRows, duplicate keys
+// and dangling references return the same errors Postgres would.
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+)
+
+type Store struct {
+	mu   sync.Mutex
+	txMu sync.Mutex
+	state
+
+	// listener receives what NotifyEvent sends, like LISTEN would.
+	listener func(channel, payload string)
+}
+
+// state is everything a failed transaction has to roll back.
+type state struct {
+	users              map[int32]database.User
+	profiles           map[int32]database.Profile
+	jobs               map[int32]database.Job
+	applications       []database.ApplyJob
+	webhooks           map[int32]database.WebhookSubscription
+	deliveries         map[int64]database.WebhookDelivery
+	outbox             map[int64]database.Outbox
+	preferences        map[int32]database.NotificationPreference
+	sentEmails         map[sentEmailKey]bool
+	notifications      map[int64]database.Notification
+	interviews         map[int32]database.Interview
+	interviewers       map[database.InterviewInterviewer]bool
+	scorecards         map[int32]database.ScorecardTemplate
+	feedback           map[int32]database.ScorecardFeedback
+	nextUserID         int32
+	nextJobID          int32
+	nextApplicationID  int32
+	nextWebhookID      int32
+	nextDeliveryID     int64
+	nextOutboxID       int64
+	nextNotificationID int64
+	nextInterviewID    int32
+	nextFeedbackID     int32
+}
+
+var _ database.Store = (*Store)(nil)
+
+func New() *Store {
+	return &Store{state: state{
+		users:         map[int32]database.User{},
+		profiles:      map[int32]database.Profile{},
+		jobs:          map[int32]database.Job{},
+		webhooks:      map[int32]database.WebhookSubscription{},
+		deliveries:    map[int64]database.WebhookDelivery{},
+		outbox:        map[int64]database.Outbox{},
+		preferences:   map[int32]database.NotificationPreference{},
+		sentEmails:    map[sentEmailKey]bool{},
+		notifications: map[int64]database.Notification{},
+		interviews:    map[int32]database.Interview{},
+		interviewers:  map[database.InterviewInterviewer]bool{},
+		scorecards:    map[int32]database.ScorecardTemplate{},
+		feedback:      map[int32]database.ScorecardFeedback{},
+	}}
+}
+
+func (st state) clone() state {
+	st.users = maps.Clone(st.users)
+	st.profiles = maps.Clone(st.profiles)
+	st.jobs = maps.Clone(st.jobs)
+	st.applications = slices.Clone(st.applications)
+	st.webhooks = maps.Clone(st.webhooks)
+	st.deliveries = maps.Clone(st.deliveries)
+	st.outbox = maps.Clone(st.outbox)
+	st.preferences = maps.Clone(st.preferences)
+	st.sentEmails = maps.Clone(st.sentEmails)
+	st.notifications = maps.Clone(st.notifications)
+	st.interviews = maps.Clone(st.interviews)
+	st.interviewers = maps.Clone(st.interviewers)
+	st.scorecards = maps.Clone(st.scorecards)
+	st.feedback = maps.Clone(st.feedback)
+	return st
+}
+
+// WithTx runs fn against the store and undoes all of its changes when it
+// fails. Transactions run one at a time, but queries outside of one may
+// interleave with it.
+func (s *Store) WithTx(ctx context.Context, fn func(q database.Querier) error) error {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	s.mu.Lock()
+	saved := s.state.clone()
+	s.mu.Unlock()
+
+	tx := &txStore{Store: s}
+	if err := fn(tx); err != nil {
+		s.mu.Lock()
+		s.state = saved
+		s.mu.Unlock()
+		return err
+	}
+	for _, n := range tx.notifies {
+		s.notify(n)
+	}
+	return nil
+}
+
+// txStore holds back notifications until the transaction commits.
+type txStore struct {
+	*Store
+	notifies []database.NotifyEventParams
+}
+
+func (tx *txStore) NotifyEvent(ctx context.Context, arg database.NotifyEventParams) error {
+	tx.notifies = append(tx.notifies, arg)
+	return nil
+}
+
+func uniqueViolation(constraint string) error {
+	return &pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint", ConstraintName: constraint}
+}
+
+func foreignKeyViolation(constraint string) error {
+	return &pgconn.PgError{Code: "23503", Message: "insert or update violates foreign key constraint", ConstraintName: constraint}
+}
+
+func sortedKeys[K int32 | int64, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+func (s *Store) userByEmail(email string) (database.User, bool) {
+	for _, u := range s.users {
+		if strings.EqualFold(u.Email, email) {
+			return u, true
+		}
+	}
+	return database.User{}, false
+}
+
+func (s *Store) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.CreateUserRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.userByEmail(arg.Email); ok {
+		return database.CreateUserRow{}, uniqueViolation("users_email_lower_key")
+	}
+	s.nextUserID++
+	u := database.User{
+		ID:              s.nextUserID,
+		Name:            arg.Name,
+		Email:           arg.Email,
+		Address:         arg.Address,
+		UserType:        arg.UserType,
+		PasswordHash:    arg.PasswordHash,
+		ProfileHeadline: arg.ProfileHeadline,
+	}
+	s.users[u.ID] = u
+	return database.CreateUserRow{ID: u.ID, Name: u.Name, Email: u.Email, UserType: u.UserType}, nil
+}
+
+func (s *Store) GetUser(ctx context.Context, email string) (database.GetUserRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.userByEmail(email)
+	if !ok {
+		return database.GetUserRow{}, pgx.ErrNoRows
+	}
+	return database.GetUserRow{ID: u.ID, PasswordHash: u.PasswordHash, UserType: u.UserType}, nil
+}
+
+func (s *Store) GetUserFromID(ctx context.Context, id int32) (database.UserType, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[id]
+	if !ok {
+		return "", pgx.ErrNoRows
+	}
+	return u.UserType, nil
+}
+
+func (s *Store) SetUserType(ctx context.Context, arg database.SetUserTypeParams) (database.SetUserTypeRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.userByEmail(arg.Email)
+	if !ok {
+		return database.SetUserTypeRow{}, pgx.ErrNoRows
+	}
+	u.UserType = arg.UserType
+	s.users[u.ID] = u
+	return database.SetUserTypeRow{ID: u.ID, UserType: u.UserType}, nil
+}
+
+func (s *Store) UpdatePasswordHash(ctx context.Context, arg database.UpdatePasswordHashParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.userByEmail(arg.Email)
+	if !ok {
+		return 0, nil
+	}
+	u.PasswordHash = arg.PasswordHash
+	s.users[u.ID] = u
+	return 1, nil
+}
+
+func (s *Store) CountUsers(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(len(s.users)), nil
+}
+
+func (s *Store) CreateApplicantProfile(ctx context.Context, applicant int32) (int32, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[applicant]; !ok {
+		return 0, foreignKeyViolation("profile_applicant_fkey")
+	}
+	if _, ok := s.profiles[applicant]; ok {
+		return 0, uniqueViolation("profile_pkey")
+	}
+	s.profiles[applicant] = database.Profile{Applicant: applicant}
+	return applicant, nil
+}
+
+func (s *Store) HasProfile(ctx context.Context, applicant int32) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.profiles[applicant]
+	return ok, nil
+}
+
+func (s *Store) AddProfileIDInUser(ctx context.Context, arg database.AddProfileIDInUserParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if arg.ProfileID.Valid {
+		if _, ok := s.profiles[arg.ProfileID.Int32]; !ok {
+			return foreignKeyViolation("fk_profile")
+		}
+	}
+	u, ok := s.users[arg.ID]
+	if !ok {
+		return nil
+	}
+	u.ProfileID = arg.ProfileID
+	s.users[arg.ID] = u
+	return nil
+}
+
+func (s *Store) UpdateProfile(ctx context.Context, arg database.UpdateProfileParams) (database.UpdateProfileRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.profiles[arg.Applicant]
+	if !ok {
+		return database.UpdateProfileRow{}, pgx.ErrNoRows
+	}
+	p.Name = arg.Name
+	p.Email = arg.Email
+	p.Phone = arg.Phone
+	p.Skills = arg.Skills
+	p.Education = arg.Education
+	p.ResumeFileAddress = arg.ResumeFileAddress
+	s.profiles[p.Applicant] = p
+	return database.UpdateProfileRow{
+		Name:      p.Name,
+		Email:     p.Email,
+		Phone:     p.Phone,
+		Skills:    p.Skills,
+		Education: p.Education,
+	}, nil
+}
+
+func (s *Store) GetResumeFileAddress(ctx context.Context, applicant int32) (pgtype.Text, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.profiles[applicant]
+	if !ok {
+		return pgtype.Text{}, pgx.ErrNoRows
+	}
+	return p.ResumeFileAddress, nil
+}
+
+func (s *Store) GetApplicants(ctx context.Context) ([]database.GetApplicantsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := []database.GetApplicantsRow{}
+	for _, id := range sortedKeys(s.users) {
+		u := s.users[id]
+		if u.UserType != database.UserTypeApplicant {
+			continue
+		}
+		rows = append(rows, database.GetApplicantsRow{
+			Name:            u.Name,
+			Email:           u.Email,
+			Address:         u.Address,
+			ProfileHeadline: u.ProfileHeadline,
+		})
+	}
+	return rows, nil
+}
+
+func (s *Store) GetApplicant(ctx context.Context, id int32) (database.GetApplicantRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[id]
+	if !ok {
+		return database.GetApplicantRow{}, pgx.ErrNoRows
+	}
+	p, ok := s.profiles[id]
+	if !ok {
+		return database.GetApplicantRow{}, pgx.ErrNoRows
+	}
+	return database.GetApplicantRow{
+		Name:              u.Name,
+		Email:             u.Email,
+		Address:           u.Address,
+		ProfileHeadline:   u.ProfileHeadline,
+		ResumeFileAddress: p.ResumeFileAddress,
+		Skills:            p.Skills,
+		Education:         p.Education,
+		Phone:             p.Phone,
+	}, nil
+}
+
+func (s *Store) CreateJob(ctx context.Context, arg database.CreateJobParams) (database.CreateJobRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.PostedBy]; !ok {
+		return database.CreateJobRow{}, foreignKeyViolation("fk_posted_by")
+	}
+	s.nextJobID++
+	j := database.Job{
+		ID:                s.nextJobID,
+		Title:             arg.Title,
+		Description:       arg.Description,
+		PostedOn:          arg.PostedOn,
+		TotalApplications: pgtype.Int4{Int32: 0, Valid: true},
+		CompanyName:       arg.CompanyName,
+		PostedBy:          arg.PostedBy,
+	}
+	s.jobs[j.ID] = j
+	return database.CreateJobRow{
+		ID:          j.ID,
+		Title:       j.Title,
+		Description: j.Description,
+		PostedOn:    j.PostedOn,
+		CompanyName: j.CompanyName,
+		PostedBy:    j.PostedBy,
+	}, nil
+}
+
+func (s *Store) GetJob(ctx context.Context, id int32) (database.GetJobRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[id]
+	if !ok {
+		return database.GetJobRow{}, pgx.ErrNoRows
+	}
+	return database.GetJobRow{
+		Title:             j.Title,
+		Description:       j.Description,
+		PostedOn:          j.PostedOn,
+		CompanyName:       j.CompanyName,
+		PostedBy:          j.PostedBy,
+		TotalApplications: j.TotalApplications,
+		ClosedAt:          j.ClosedAt,
+	}, nil
+}
+
+func (s *Store) GetJobsApplicant(ctx context.Context) ([]database.GetJobsApplicantRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := []database.GetJobsApplicantRow{}
+	for _, id := range sortedKeys(s.jobs) {
+		j := s.jobs[id]
+		if j.ClosedAt.Valid {
+			continue
+		}
+		rows = append(rows, database.GetJobsApplicantRow{
+			Title:             j.Title,
+			Description:       j.Description,
+			PostedOn:          j.PostedOn,
+			TotalApplications: j.TotalApplications,
+			CompanyName:       j.CompanyName,
+			PostedBy:          j.PostedBy,
+		})
+	}
+	return rows, nil
+}
+
+func (s *Store) ListJobs(ctx context.Context) ([]database.ListJobsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := []database.ListJobsRow{}
+	for _, id := range sortedKeys(s.jobs) {
+		j := s.jobs[id]
+		rows = append(rows, database.ListJobsRow{
+			ID:                j.ID,
+			Title:             j.Title,
+			CompanyName:       j.CompanyName,
+			PostedOn:          j.PostedOn,
+			TotalApplications: j.TotalApplications,
+			ClosedAt:          j.ClosedAt,
+		})
+	}
+	return rows, nil
+}
+
+func (s *Store) CloseJob(ctx context.Context, arg database.CloseJobParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[arg.ID]
+	if !ok || j.ClosedAt.Valid {
+		return 0, nil
+	}
+	j.ClosedAt = arg.ClosedAt
+	s.jobs[j.ID] = j
+	return 1, nil
+}
+
+func (s *Store) ApplyJob(ctx context.Context, arg database.ApplyJobParams) (int32, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.ApplicantID.Int32]; arg.ApplicantID.Valid && !ok {
+		return 0, foreignKeyViolation("apply_jobs_applicant_id_fkey")
+	}
+	if _, ok := s.jobs[arg.JobID.Int32]; arg.JobID.Valid && !ok {
+		return 0, foreignKeyViolation("apply_jobs_job_id_fkey")
+	}
+	s.nextApplicationID++
+	s.applications = append(s.applications, database.ApplyJob{
+		ApplicantID: arg.ApplicantID,
+		JobID:       arg.JobID,
+		ID:          s.nextApplicationID,
+		Status:      database.ApplicationStatusApplied,
+		AppliedAt:   arg.AppliedAt,
+	})
+	return s.nextApplicationID, nil
+}
+
+func (s *Store) GetApplication(ctx context.Context, id int32) (database.GetApplicationRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, a := range s.applications {
+		if a.ID == id {
+			return database.GetApplicationRow{
+				ID:          a.ID,
+				ApplicantID: a.ApplicantID,
+				JobID:       a.JobID,
+				Status:      a.Status,
+				AppliedAt:   a.AppliedAt,
+			}, nil
+		}
+	}
+	return database.GetApplicationRow{}, pgx.ErrNoRows
+}
+
+func (s *Store) UpdateApplicationStatus(ctx context.Context, arg database.UpdateApplicationStatusParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, a := range s.applications {
+		if a.ID == arg.ID {
+			s.applications[i].Status = arg.Status
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (s *Store) ListApplicationsForApplicant(ctx context.Context, applicantID pgtype.Int4) ([]database.ListApplicationsForApplicantRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := []database.ListApplicationsForApplicantRow{}
+	for _, a := range s.applications {
+		j, ok := s.jobs[a.JobID.Int32]
+		if !ok || !a.ApplicantID.Valid || a.ApplicantID != applicantID {
+			continue
+		}
+		rows = append(rows, database.ListApplicationsForApplicantRow{
+			ID:        a.ID,
+			JobID:     a.JobID,
+			Title:     j.Title,
+			Status:    a.Status,
+			AppliedAt: a.AppliedAt,
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].ID < rows[j].ID })
+	return rows, nil
+}
+
+func (s *Store) UpdateTotalApplications(ctx context.Context, id int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[id]
+	if !ok {
+		return nil
+	}
+	// total_applications + 1 stays NULL when it was NULL.
+	if j.TotalApplications.Valid {
+		j.TotalApplications.Int32++
+	}
+	s.jobs[id] = j
+	return nil
+}
+
+func (s *Store) ExportUsers(ctx context.Context) ([]database.ExportUsersRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := []database.ExportUsersRow{}
+	for _, id := range sortedKeys(s.users) {
+		u := s.users[id]
+		rows = append(rows, database.ExportUsersRow{
+			ID:              u.ID,
+			Name:            u.Name,
+			Email:           u.Email,
+			Address:         u.Address,
+			UserType:        u.UserType,
+			ProfileHeadline: u.ProfileHeadline,
+		})
+	}
+	return rows, nil
+}
+
+func (s *Store) ExportProfiles(ctx context.Context) ([]database.Profile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := []database.Profile{}
+	for _, id := range sortedKeys(s.profiles) {
+		rows = append(rows, s.profiles[id])
+	}
+	return rows, nil
+}
+
+func (s *Store) ExportJobs(ctx context.Context) ([]database.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := []database.Job{}
+	for _, id := range sortedKeys(s.jobs) {
+		rows = append(rows, s.jobs[id])
+	}
+	return rows, nil
+}
+
+func (s *Store) ExportApplications(ctx context.Context) ([]database.ApplyJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := append([]database.ApplyJob{}, s.applications...)
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].JobID.Int32 != rows[j].JobID.Int32 {
+			return rows[i].JobID.Int32 < rows[j].JobID.Int32
+		}
+		return rows[i].ApplicantID.Int32 < rows[j].ApplicantID.Int32
+	})
+	return rows, nil
+}
+
+// Applications returns a copy of the stored applications, for assertions.
+func (s *Store) Applications() []database.ApplyJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]database.ApplyJob{}, s.applications...)
+}
+
+// User returns the stored user with id, for assertions.
+func (s *Store) User(id int32) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[id]
+	if !ok {
+		return u, fmt.Errorf("no user %d", id)
+	}
+	return u, nil
+}
@@ -0,0 +1,46 @@
+package memstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+)
+
+func TestWithTxRollsBack(t *testing.T) {
+	s := New()
+	ctx := context.Background()
+	boom := errors.New("boom")
+
+	err := s.WithTx(ctx, func(q database.Querier) error {
+		user, err := q.CreateUser(ctx, database.CreateUserParams{Email: "ada@example.com", UserType: database.UserTypeApplicant})
+		if err != nil {
+			return err
+		}
+		if _, err := q.CreateApplicantProfile(ctx, user.ID); err != nil {
+			return err
+		}
+		return boom
+	})
+	if err != boom {
+		t.Fatalf("err = %v", err)
+	}
+	if n, _ := s.CountUsers(ctx); n != 0 {
+		t.Errorf("%d users left after rollback", n)
+	}
+	if profiles, _ := s.ExportProfiles(ctx); len(profiles) != 0 {
+		t.Errorf("%d profiles left after rollback", len(profiles))
+	}
+
+	err = s.WithTx(ctx, func(q database.Querier) error {
+		_, err := q.CreateUser(ctx, database.CreateUserParams{Email: "ada@example.com"})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := s.CountUsers(ctx); n != 1 {
+		t.Errorf("%d users after commit, want 1", n)
+	}
+}
@@ -0,0 +1,160 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+)
+
+type sentEmailKey struct {
+	userID  int32
+	kind    string
+	eventID string
+}
+
+// preference returns the stored preferences of a user or the defaults.
+func (s *Store) preference(userID int32) database.NotificationPreference {
+	if p, ok := s.preferences[userID]; ok {
+		return p
+	}
+	return database.NotificationPreference{UserID: userID, ApplicationUpdates: true, ApplicantDigest: true}
+}
+
+func (s *Store) GetNotificationRecipient(ctx context.Context, id int32) (database.GetNotificationRecipientRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[id]
+	if !ok {
+		return database.GetNotificationRecipientRow{}, pgx.ErrNoRows
+	}
+	p := s.preference(id)
+	return database.GetNotificationRecipientRow{
+		ID:                 u.ID,
+		Name:               u.Name,
+		Email:              u.Email,
+		UserType:           u.UserType,
+		ApplicationUpdates: p.ApplicationUpdates,
+		ApplicantDigest:    p.ApplicantDigest,
+	}, nil
+}
+
+func (s *Store) SetNotificationPreferences(ctx context.Context, arg database.SetNotificationPreferencesParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.UserID]; !ok {
+		return foreignKeyViolation("notification_preferences_user_id_fkey")
+	}
+	p := s.preference(arg.UserID)
+	p.ApplicationUpdates = arg.ApplicationUpdates
+	p.ApplicantDigest = arg.ApplicantDigest
+	s.preferences[arg.UserID] = p
+	return nil
+}
+
+func (s *Store) ListDigestRecipients(ctx context.Context, dueBefore pgtype.Timestamp) ([]database.ListDigestRecipientsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := []database.ListDigestRecipientsRow{}
+	for _, id := range sortedKeys(s.users) {
+		u := s.users[id]
+		p := s.preference(id)
+		if u.UserType != database.UserTypeAdmin || !p.ApplicantDigest {
+			continue
+		}
+		if p.DigestSentAt.Valid && p.DigestSentAt.Time.After(dueBefore.Time) {
+			continue
+		}
+		rows = append(rows, database.ListDigestRecipientsRow{
+			ID:           u.ID,
+			Name:         u.Name,
+			Email:        u.Email,
+			DigestSentAt: p.DigestSentAt,
+		})
+	}
+	return rows, nil
+}
+
+func (s *Store) SetDigestSentAt(ctx context.Context, arg database.SetDigestSentAtParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.UserID]; !ok {
+		return foreignKeyViolation("notification_preferences_user_id_fkey")
+	}
+	p := s.preference(arg.UserID)
+	p.DigestSentAt = arg.DigestSentAt
+	s.preferences[arg.UserID] = p
+	return nil
+}
+
+func (s *Store) ListApplicationsForPoster(ctx context.Context, arg database.ListApplicationsForPosterParams) ([]database.ListApplicationsForPosterRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := []database.ListApplicationsForPosterRow{}
+	for _, a := range s.applications {
+		j, ok := s.jobs[a.JobID.Int32]
+		if !ok || j.PostedBy != arg.PostedBy || !a.AppliedAt.After(arg.Since) || a.AppliedAt.After(arg.Until) {
+			continue
+		}
+		u, ok := s.users[a.ApplicantID.Int32]
+		if !ok {
+			continue
+		}
+		rows = append(rows, database.ListApplicationsForPosterRow{
+			ID:             a.ID,
+			JobTitle:       j.Title,
+			ApplicantName:  u.Name,
+			ApplicantEmail: u.Email,
+			AppliedAt:      a.AppliedAt,
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].AppliedAt.Equal(rows[j].AppliedAt) {
+			return rows[i].AppliedAt.Before(rows[j].AppliedAt)
+		}
+		return rows[i].ID < rows[j].ID
+	})
+	return rows, nil
+}
+
+func (s *Store) EmailSent(ctx context.Context, arg database.EmailSentParams) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sentEmails[sentEmailKey{arg.UserID, arg.Kind, arg.EventID}], nil
+}
+
+func (s *Store) RecordSentEmail(ctx context.Context, arg database.RecordSentEmailParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.UserID]; !ok {
+		return foreignKeyViolation("sent_emails_user_id_fkey")
+	}
+	s.sentEmails[sentEmailKey{arg.UserID, arg.Kind, arg.EventID}] = true
+	return nil
+}
+
+func (s *Store) ClaimDigest(ctx context.Context, arg database.ClaimDigestParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.UserID]; !ok {
+		return 0, foreignKeyViolation("notification_preferences_user_id_fkey")
+	}
+	p := s.preference(arg.UserID)
+	if p.DigestSentAt.Valid && p.DigestSentAt.Time.After(arg.DueBefore.Time) {
+		return 0, nil
+	}
+	p.DigestSentAt = arg.DigestSentAt
+	s.preferences[arg.UserID] = p
+	return 1, nil
+}
@@ -0,0 +1,131 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+)
+
+func (s *Store) CreateOutboxEvent(ctx context.Context, arg database.CreateOutboxEventParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.outbox {
+		if e.EventID == arg.EventID {
+			return uniqueViolation("outbox_event_id_key")
+		}
+	}
+	s.nextOutboxID++
+	s.outbox[s.nextOutboxID] = database.Outbox{
+		ID:            s.nextOutboxID,
+		EventID:       arg.EventID,
+		EventType:     arg.EventType,
+		Payload:       slices.Clone(arg.Payload),
+		CreatedAt:     arg.CreatedAt,
+		NextAttemptAt: arg.CreatedAt,
+	}
+	return nil
+}
+
+func (s *Store) ClaimOutboxEvents(ctx context.Context, arg database.ClaimOutboxEventsParams) ([]database.ClaimOutboxEventsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := []database.ClaimOutboxEventsRow{}
+	for _, id := range sortedKeys(s.outbox) {
+		if len(rows) == int(arg.MaxEvents) {
+			break
+		}
+		e := s.outbox[id]
+		if e.PublishedAt.Valid || e.NextAttemptAt.After(arg.Now) {
+			continue
+		}
+		e.NextAttemptAt = arg.LeaseUntil
+		s.outbox[id] = e
+		rows = append(rows, database.ClaimOutboxEventsRow{
+			ID:        e.ID,
+			EventID:   e.EventID,
+			EventType: e.EventType,
+			Payload:   e.Payload,
+			Attempts:  e.Attempts,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+	return rows, nil
+}
+
+func (s *Store) MarkOutboxPublished(ctx context.Context, arg database.MarkOutboxPublishedParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.outbox[arg.ID]; ok {
+		e.PublishedAt = arg.PublishedAt
+		e.Attempts++
+		e.LastError.Valid = false
+		s.outbox[arg.ID] = e
+	}
+	return nil
+}
+
+func (s *Store) RecordOutboxFailure(ctx context.Context, arg database.RecordOutboxFailureParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.outbox[arg.ID]; ok {
+		e.Attempts++
+		e.NextAttemptAt = arg.NextAttemptAt
+		e.LastError = arg.LastError
+		s.outbox[arg.ID] = e
+	}
+	return nil
+}
+
+func (s *Store) DeletePublishedOutboxEvents(ctx context.Context, publishedAt pgtype.Timestamp) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for id, e := range s.outbox {
+		if e.PublishedAt.Valid && e.PublishedAt.Time.Before(publishedAt.Time) {
+			delete(s.outbox, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Outbox returns the stored outbox events in order, for assertions.
+func (s *Store) Outbox() []database.Outbox {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := []database.Outbox{}
+	for _, id := range sortedKeys(s.outbox) {
+		events = append(events, s.outbox[id])
+	}
+	return events
+}
+
+func (s *Store) NotifyEvent(ctx context.Context, arg database.NotifyEventParams) error {
+	s.notify(arg)
+	return nil
+}
+
+func (s *Store) notify(arg database.NotifyEventParams) {
+	s.mu.Lock()
+	listener := s.listener
+	s.mu.Unlock()
+	if listener != nil {
+		listener(arg.Channel, arg.Payload)
+	}
+}
+
+// Listen calls fn with every notification sent once it is committed.
+func (s *Store) Listen(fn func(channel, payload string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listener = fn
+}
@@ -0,0 +1,149 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+)
+
+func (s *Store) GetScorecardTemplate(ctx context.Context, jobID int32) (database.ScorecardTemplate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.scorecards[jobID]
+	if !ok {
+		return database.ScorecardTemplate{}, pgx.ErrNoRows
+	}
+	return t, nil
+}
+
+func (s *Store) SetScorecardTemplate(ctx context.Context, arg database.SetScorecardTemplateParams) (database.ScorecardTemplate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[arg.JobID]; !ok {
+		return database.ScorecardTemplate{}, foreignKeyViolation("scorecard_templates_job_id_fkey")
+	}
+	if _, ok := s.users[arg.UpdatedBy]; !ok {
+		return database.ScorecardTemplate{}, foreignKeyViolation("scorecard_templates_updated_by_fkey")
+	}
+	t := database.ScorecardTemplate{
+		JobID:     arg.JobID,
+		Criteria:  arg.Criteria,
+		UpdatedBy: arg.UpdatedBy,
+		UpdatedAt: arg.UpdatedAt,
+	}
+	s.scorecards[arg.JobID] = t
+	return t, nil
+}
+
+func (s *Store) SaveFeedback(ctx context.Context, arg database.SaveFeedbackParams) (database.ScorecardFeedback, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.application(arg.ApplicationID); !ok {
+		return database.ScorecardFeedback{}, foreignKeyViolation("scorecard_feedback_application_id_fkey")
+	}
+	if _, ok := s.users[arg.InterviewerID]; !ok {
+		return database.ScorecardFeedback{}, foreignKeyViolation("scorecard_feedback_interviewer_id_fkey")
+	}
+	f := database.ScorecardFeedback{
+		ApplicationID: arg.ApplicationID,
+		InterviewerID: arg.InterviewerID,
+		CreatedAt:     arg.Now,
+	}
+	for _, existing := range s.feedback {
+		if existing.ApplicationID == arg.ApplicationID && existing.InterviewerID == arg.InterviewerID {
+			if existing.SubmittedAt.Valid {
+				return database.ScorecardFeedback{}, pgx.ErrNoRows
+			}
+			f = existing
+		}
+	}
+	if f.ID == 0 {
+		s.nextFeedbackID++
+		f.ID = s.nextFeedbackID
+	}
+	f.Ratings = arg.Ratings
+	f.Recommendation = arg.Recommendation
+	f.Notes = arg.Notes
+	f.SubmittedAt = arg.SubmittedAt
+	f.UpdatedAt = arg.Now
+	s.feedback[f.ID] = f
+	return f, nil
+}
+
+// sortedFeedback returns the feedback keep accepts, submitted first in
+// the order it was submitted.
+func (s *Store) sortedFeedback(keep func(database.ScorecardFeedback) bool) []database.ScorecardFeedback {
+	rows := []database.ScorecardFeedback{}
+	for _, id := range sortedKeys(s.feedback) {
+		if f := s.feedback[id]; keep(f) {
+			rows = append(rows, f)
+		}
+	}
+	slices.SortStableFunc(rows, func(a, b database.ScorecardFeedback) int {
+		switch {
+		case a.SubmittedAt.Valid && b.SubmittedAt.Valid:
+			return a.SubmittedAt.Time.Compare(b.SubmittedAt.Time)
+		case a.SubmittedAt.Valid:
+			return -1
+		case b.SubmittedAt.Valid:
+			return 1
+		}
+		return 0
+	})
+	return rows
+}
+
+func (s *Store) ListFeedbackForApplication(ctx context.Context, applicationID int32) ([]database.ListFeedbackForApplicationRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := []database.ListFeedbackForApplicationRow{}
+	for _, f := range s.sortedFeedback(func(f database.ScorecardFeedback) bool { return f.ApplicationID == applicationID }) {
+		rows = append(rows, database.ListFeedbackForApplicationRow{
+			ScorecardFeedback: f,
+			InterviewerName:   s.users[f.InterviewerID].Name,
+		})
+	}
+	return rows, nil
+}
+
+func (s *Store) ListFeedbackForApplicant(ctx context.Context, applicantID pgtype.Int4) ([]database.ListFeedbackForApplicantRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	feedback := s.sortedFeedback(func(f database.ScorecardFeedback) bool {
+		a, _ := s.application(f.ApplicationID)
+		return a.ApplicantID.Valid && a.ApplicantID == applicantID
+	})
+	slices.SortStableFunc(feedback, func(a, b database.ScorecardFeedback) int {
+		return int(a.ApplicationID - b.ApplicationID)
+	})
+	rows := []database.ListFeedbackForApplicantRow{}
+	for _, f := range feedback {
+		rows = append(rows, database.ListFeedbackForApplicantRow{
+			ScorecardFeedback: f,
+			InterviewerName:   s.users[f.InterviewerID].Name,
+		})
+	}
+	return rows, nil
+}
+
+func (s *Store) IsInterviewer(ctx context.Context, arg database.IsInterviewerParams) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range s.interviewers {
+		i := s.interviews[key.InterviewID]
+		if key.UserID == arg.UserID && i.ApplicationID == arg.ApplicationID && i.Status != database.InterviewStatusCancelled {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,205 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+)
+
+func (s *Store) CreateWebhook(ctx context.Context, arg database.CreateWebhookParams) (database.CreateWebhookRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.CreatedBy]; !ok {
+		return database.CreateWebhookRow{}, foreignKeyViolation("webhook_subscriptions_created_by_fkey")
+	}
+	s.nextWebhookID++
+	w := database.WebhookSubscription{
+		ID:        s.nextWebhookID,
+		Url:       arg.Url,
+		Secret:    arg.Secret,
+		Events:    slices.Clone(arg.Events),
+		CreatedBy: arg.CreatedBy,
+		CreatedAt: arg.CreatedAt,
+	}
+	s.webhooks[w.ID] = w
+	return database.CreateWebhookRow{
+		ID:        w.ID,
+		Url:       w.Url,
+		Events:    w.Events,
+		CreatedBy: w.CreatedBy,
+		CreatedAt: w.CreatedAt,
+	}, nil
+}
+
+func (s *Store) ListWebhooks(ctx context.Context) ([]database.ListWebhooksRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := []database.ListWebhooksRow{}
+	for _, id := range sortedKeys(s.webhooks) {
+		w := s.webhooks[id]
+		rows = append(rows, database.ListWebhooksRow{
+			ID:        w.ID,
+			Url:       w.Url,
+			Events:    w.Events,
+			CreatedBy: w.CreatedBy,
+			CreatedAt: w.CreatedAt,
+		})
+	}
+	return rows, nil
+}
+
+func (s *Store) DeleteWebhook(ctx context.Context, id int32) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.webhooks[id]; !ok {
+		return 0, nil
+	}
+	delete(s.webhooks, id)
+	for did, d := range s.deliveries {
+		if d.SubscriptionID == id {
+			delete(s.deliveries, did)
+		}
+	}
+	return 1, nil
+}
+
+func (s *Store) ListWebhooksForEvent(ctx context.Context, eventType string) ([]int32, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := []int32{}
+	for _, id := range sortedKeys(s.webhooks) {
+		if slices.Contains(s.webhooks[id].Events, eventType) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (s *Store) CreateWebhookDelivery(ctx context.Context, arg database.CreateWebhookDeliveryParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.webhooks[arg.SubscriptionID]; !ok {
+		return foreignKeyViolation("webhook_deliveries_subscription_id_fkey")
+	}
+	s.nextDeliveryID++
+	s.deliveries[s.nextDeliveryID] = database.WebhookDelivery{
+		ID:             s.nextDeliveryID,
+		SubscriptionID: arg.SubscriptionID,
+		EventID:        arg.EventID,
+		EventType:      arg.EventType,
+		Payload:        slices.Clone(arg.Payload),
+		Status:         database.WebhookDeliveryStatusPending,
+		NextAttemptAt:  arg.NextAttemptAt,
+		CreatedAt:      arg.NextAttemptAt,
+	}
+	return nil
+}
+
+func (s *Store) ClaimWebhookDeliveries(ctx context.Context, arg database.ClaimWebhookDeliveriesParams) ([]database.ClaimWebhookDeliveriesRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due := []database.WebhookDelivery{}
+	for _, d := range s.deliveries {
+		if d.Status == database.WebhookDeliveryStatusPending && !d.NextAttemptAt.After(arg.Now) {
+			due = append(due, d)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+	if len(due) > int(arg.MaxDeliveries) {
+		due = due[:arg.MaxDeliveries]
+	}
+
+	rows := []database.ClaimWebhookDeliveriesRow{}
+	for _, d := range due {
+		d.NextAttemptAt = arg.LeaseUntil
+		s.deliveries[d.ID] = d
+		w := s.webhooks[d.SubscriptionID]
+		rows = append(rows, database.ClaimWebhookDeliveriesRow{
+			ID:        d.ID,
+			EventID:   d.EventID,
+			EventType: d.EventType,
+			Payload:   d.Payload,
+			Attempts:  d.Attempts,
+			Url:       w.Url,
+			Secret:    w.Secret,
+		})
+	}
+	return rows, nil
+}
+
+func (s *Store) RecordWebhookAttempt(ctx context.Context, arg database.RecordWebhookAttemptParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.deliveries[arg.ID]
+	if !ok {
+		return nil
+	}
+	d.Status = arg.Status
+	d.Attempts++
+	d.NextAttemptAt = arg.NextAttemptAt
+	d.LastAttemptAt = arg.LastAttemptAt
+	d.LastStatusCode = arg.LastStatusCode
+	d.LastError = arg.LastError
+	s.deliveries[d.ID] = d
+	return nil
+}
+
+// newestDeliveries returns up to limit deliveries matching keep, newest
+// first.
+func (s *Store) newestDeliveries(limit int32, keep func(database.WebhookDelivery) bool) []database.WebhookDelivery {
+	rows := []database.WebhookDelivery{}
+	ids := sortedKeys(s.deliveries)
+	for i := len(ids) - 1; i >= 0 && len(rows) < int(limit); i-- {
+		if d := s.deliveries[ids[i]]; keep(d) {
+			rows = append(rows, d)
+		}
+	}
+	return rows
+}
+
+func (s *Store) ListWebhookDeliveries(ctx context.Context, arg database.ListWebhookDeliveriesParams) ([]database.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.newestDeliveries(arg.Limit, func(d database.WebhookDelivery) bool {
+		return d.SubscriptionID == arg.SubscriptionID
+	}), nil
+}
+
+func (s *Store) ListDeadWebhookDeliveries(ctx context.Context, limit int32) ([]database.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.newestDeliveries(limit, func(d database.WebhookDelivery) bool {
+		return d.Status == database.WebhookDeliveryStatusDead
+	}), nil
+}
+
+func (s *Store) RetryWebhookDelivery(ctx context.Context, arg database.RetryWebhookDeliveryParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.deliveries[arg.ID]
+	if !ok || d.Status != database.WebhookDeliveryStatusDead {
+		return 0, nil
+	}
+	d.Status = database.WebhookDeliveryStatusPending
+	d.Attempts = 0
+	d.NextAttemptAt = arg.NextAttemptAt
+	s.deliveries[d.ID] = d
+	return 1, nil
+}
@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+)
+
+// InstrumentDB wraps db so every query records its latency under the
+// sqlc query name.
+func (m *Metrics) InstrumentDB(db database.DBTX) database.DBTX {
+	if m == nil {
+		return db
+	}
+	return database.WithHook(db, func(ctx context.Context, query string) (context.Context, func(error)) {
+		start := time.Now()
+		return ctx, func(err error) {
+			m.ObserveQuery(database.QueryName(query), err, time.Since(start))
+		}
+	})
+}
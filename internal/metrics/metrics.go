@@ -0,0 +1,179 @@
+// Package metrics owns the Prometheus collectors exposed on /metrics.
+// All recording methods are safe to call on a nil *Metrics, which turns
+// them into no-ops for tests and tools that do not serve metrics.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "synlabs"
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	dbDuration   *prometheus.HistogramVec
+	parserCalls  *prometheus.CounterVec
+	parserTime   prometheus.Histogram
+	signups      *prometheus.CounterVec
+	logins       *prometheus.CounterVec
+	jobsPosted   prometheus.Counter
+	applications prometheus.Counter
+}
+
+// New registers every collector on a fresh registry. When pool is not nil
+// its statistics are exported too.
+func New(pool *pgxpool.Pool) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by route pattern, method and status code.",
+		}, []string{"route", "method", "code"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route pattern and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		dbDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Database query latency by sqlc query name and outcome.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"query", "outcome"}),
+		parserCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "resume_parser_calls_total",
+			Help:      "Calls to the resume parser API by outcome.",
+		}, []string{"outcome"}),
+		parserTime: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "resume_parser_duration_seconds",
+			Help:      "Latency of calls to the resume parser API.",
+			Buckets:   []float64{.1, .25, .5, 1, 2.5, 5, 10, 20, 30},
+		}),
+		signups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "signups_total",
+			Help:      "Completed signups by user type.",
+		}, []string{"user_type"}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "logins_total",
+			Help:      "Login attempts by method and result.",
+		}, []string{"method", "result"}),
+		jobsPosted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "jobs_posted_total",
+			Help:      "Jobs posted by admins.",
+		}),
+		applications: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "applications_submitted_total",
+			Help:      "Job applications submitted by applicants.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.dbDuration,
+		m.parserCalls,
+		m.parserTime,
+		m.signups,
+		m.logins,
+		m.jobsPosted,
+		m.applications,
+	)
+	if pool != nil {
+		m.registry.MustRegister(newPoolCollector(pool))
+	}
+
+	return m
+}
+
+func (m *Metrics) Handler() http.Handler {
+	if m == nil {
+		return http.NotFoundHandler()
+	}
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+func (m *Metrics) ObserveHTTP(route, method string, code int, d time.Duration) {
+	if m == nil {
+		return
+	}
+	if route == "" {
+		route = "unmatched"
+	}
+	m.httpRequests.WithLabelValues(route, method, strconv.Itoa(code)).Inc()
+	m.httpDuration.WithLabelValues(route, method).Observe(d.Seconds())
+}
+
+func (m *Metrics) ObserveQuery(query string, err error, d time.Duration) {
+	if m == nil {
+		return
+	}
+	outcome := "success"
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		outcome = "error"
+	}
+	m.dbDuration.WithLabelValues(query, outcome).Observe(d.Seconds())
+}
+
+// ObserveResumeParse records one call to the parser. outcome is a short
+// fixed label such as "success", "request_error" or "bad_status".
+func (m *Metrics) ObserveResumeParse(outcome string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.parserCalls.WithLabelValues(outcome).Inc()
+	m.parserTime.Observe(d.Seconds())
+}
+
+func (m *Metrics) Signup(userType string) {
+	if m == nil {
+		return
+	}
+	m.signups.WithLabelValues(userType).Inc()
+}
+
+func (m *Metrics) Login(method string, ok bool) {
+	if m == nil {
+		return
+	}
+	result := "success"
+	if !ok {
+		result = "failure"
+	}
+	m.logins.WithLabelValues(method, result).Inc()
+}
+
+func (m *Metrics) JobPosted() {
+	if m == nil {
+		return
+	}
+	m.jobsPosted.Inc()
+}
+
+func (m *Metrics) ApplicationSubmitted() {
+	if m == nil {
+		return
+	}
+	m.applications.Inc()
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNilMetricsIsNoop(t *testing.T) {
+	var m *Metrics
+	m.ObserveHTTP("GET /", "GET", 200, time.Millisecond)
+	m.ObserveQuery("GetUser", nil, time.Millisecond)
+	m.ObserveResumeParse("success", time.Second)
+	m.Signup("applicant")
+	m.Login("password", true)
+	m.JobPosted()
+	m.ApplicationSubmitted()
+	if m.InstrumentDB(nil) != nil {
+		t.Fatal("nil metrics should not wrap the database")
+	}
+}
+
+func TestHandlerExposesRecordedMetrics(t *testing.T) {
+	m := New(nil)
+	m.ObserveHTTP("GET /jobs", "GET", 200, 20*time.Millisecond)
+	m.ObserveQuery("GetJob", errors.New("boom"), time.Millisecond)
+	m.ObserveResumeParse("bad_status", time.Second)
+	m.Login("oidc", false)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+
+	for _, want := range []string{
+		`synlabs_http_requests_total{code="200",method="GET",route="GET /jobs"} 1`,
+		`synlabs_db_query_duration_seconds_count{outcome="error",query="GetJob"} 1`,
+		`synlabs_resume_parser_calls_total{outcome="bad_status"} 1`,
+		`synlabs_logins_total{method="oidc",result="failure"} 1`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics output missing %s", want)
+		}
+	}
+}
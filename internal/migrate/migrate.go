@@ -0,0 +1,85 @@
+// Package migrate applies the goose migrations embedded in sql/schema.
+// Every run holds a Postgres advisory lock so replicas starting at the
+// same time apply each migration exactly once.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+
+	"github.com/Vikuuu/synlabs-assignment/sql/schema"
+)
+
+// lockID is the advisory lock key shared by every process migrating this
+// database. It only needs to differ from other lock users of the database.
+const lockID = 0x73796e6c6162 // "synlab"
+
+type Migrator struct {
+	provider *goose.Provider
+}
+
+func New(db *sql.DB) (*Migrator, error) {
+	locker, err := lock.NewPostgresSessionLocker(lock.WithLockID(lockID))
+	if err != nil {
+		return nil, err
+	}
+	p, err := goose.NewProvider(goose.DialectPostgres, db, schema.FS, goose.WithSessionLocker(locker))
+	if err != nil {
+		return nil, fmt.Errorf("loading migrations: %w", err)
+	}
+	return &Migrator{provider: p}, nil
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up(ctx context.Context) ([]*goose.MigrationResult, error) {
+	return m.provider.Up(ctx)
+}
+
+// Down rolls back the most recently applied migration.
+func (m *Migrator) Down(ctx context.Context) (*goose.MigrationResult, error) {
+	return m.provider.Down(ctx)
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]*goose.MigrationStatus, error) {
+	return m.provider.Status(ctx)
+}
+
+const template = `-- +goose Up
+
+-- +goose Down
+`
+
+var nameRe = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Create writes an empty migration into dir numbered after the newest one
+// already there, e.g. 003_add_index.sql, and returns its path.
+func Create(dir, name string) (string, error) {
+	name = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), " ", "_"))
+	if !nameRe.MatchString(name) {
+		return "", errors.New("migration name may only contain letters, digits and underscores")
+	}
+
+	latest, err := schema.LatestVersionIn(os.DirFS(dir))
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%03d_%s.sql", latest+1, name))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(template); err != nil {
+		return "", err
+	}
+	return path, nil
+}
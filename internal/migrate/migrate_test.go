@@ -0,0 +1,30 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateNumbersAfterLatest(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "001_initial.sql"), nil, 0o644)
+	os.WriteFile(filepath.Join(dir, "002_jobs.sql"), nil, 0o644)
+
+	path, err := Create(dir, "Add Job Index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "003_add_job_index.sql" {
+		t.Errorf("created %s", path)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "-- +goose Up") || !strings.Contains(string(data), "-- +goose Down") {
+		t.Errorf("missing goose annotations:\n%s", data)
+	}
+
+	if _, err := Create(dir, "drop; table"); err == nil {
+		t.Error("invalid name accepted")
+	}
+}
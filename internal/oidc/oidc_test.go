@@ -0,0 +1,115 @@
+// The tests are external since oidctest imports this package.
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/oidc"
+	"github.com/Vikuuu/synlabs-assignment/internal/oidc/oidctest"
+)
+
+func TestVerify(t *testing.T) {
+	provider, idp, err := oidctest.NewServer("jobs-api", "ops@corp.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(idp.Close)
+	client := oidc.NewClient(oidc.Config{Issuer: provider.Issuer, ClientID: "jobs-api"}, idp.Client())
+
+	claims := func(override jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"iss":            provider.Issuer,
+			"sub":            "ops",
+			"aud":            "jobs-api",
+			"exp":            time.Now().Add(5 * time.Minute).Unix(),
+			"nonce":          "n-1",
+			"email":          "ops@corp.example.com",
+			"email_verified": true,
+		}
+		for k, v := range override {
+			if v == nil {
+				delete(c, k)
+			} else {
+				c[k] = v
+			}
+		}
+		return c
+	}
+	sign := func(kid string, c jwt.MapClaims) string {
+		t.Helper()
+		token, err := provider.Sign(kid, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	got, err := client.Verify(context.Background(), sign(oidctest.KeyID, claims(nil)), "n-1")
+	if err != nil || got.Email != "ops@corp.example.com" || got.EmailVerified == nil || !*got.EmailVerified {
+		t.Fatalf("claims = %+v, err = %v", got, err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		nonce string
+	}{
+		{"wrong nonce", sign(oidctest.KeyID, claims(nil)), "n-2"},
+		{"wrong audience", sign(oidctest.KeyID, claims(jwt.MapClaims{"aud": "other-app"})), "n-1"},
+		{"wrong issuer", sign(oidctest.KeyID, claims(jwt.MapClaims{"iss": "https://evil.example.com"})), "n-1"},
+		{"unknown key", sign("rotated-away", claims(nil)), "n-1"},
+		{"expired", sign(oidctest.KeyID, claims(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()})), "n-1"},
+		{"no expiry", sign(oidctest.KeyID, claims(jwt.MapClaims{"exp": nil})), "n-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if claims, err := client.Verify(context.Background(), tt.token, tt.nonce); err == nil {
+				t.Errorf("verified %+v", claims)
+			}
+		})
+	}
+
+	if _, err := client.Verify(context.Background(), sign("rotated-away", claims(nil)), "n-1"); !errors.Is(err, oidc.ErrNoKey) {
+		t.Errorf("unknown key: err = %v, want ErrNoKey", err)
+	}
+}
+
+func TestDecodeSession(t *testing.T) {
+	s, err := oidc.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookie, err := s.Encode("secret", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := oidc.DecodeSession(cookie, "secret")
+	if err != nil || got.State != s.State || got.Nonce != s.Nonce || got.Verifier != s.Verifier {
+		t.Fatalf("session = %+v, err = %v", got, err)
+	}
+
+	// Swap the state for one the attacker chose, keeping the signature.
+	parts := strings.Split(cookie, ".")
+	forged, _ := (&oidc.Session{State: "attacker", Nonce: s.Nonce, Verifier: s.Verifier}).Encode("other-secret", time.Minute)
+	tampered := parts[0] + "." + strings.Split(forged, ".")[1] + "." + parts[2]
+	if _, err := oidc.DecodeSession(tampered, "secret"); err == nil {
+		t.Error("decoded a tampered session")
+	}
+	if _, err := oidc.DecodeSession(forged, "secret"); err == nil {
+		t.Error("decoded a session signed with another secret")
+	}
+
+	expired, err := s.Encode("secret", -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := oidc.DecodeSession(expired, "secret"); !errors.Is(err, jwt.ErrTokenExpired) {
+		t.Errorf("expired session: err = %v", err)
+	}
+}
@@ -0,0 +1,250 @@
+// Package oidctest implements a minimal OpenID Connect provider for
+// exercising the SSO login locally and in tests. Every authorization
+// request is approved immediately for the configured user.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/oidc"
+)
+
+// KeyID identifies the signing key of every provider in its JWKS.
+const KeyID = "oidctest"
+
+type authRequest struct {
+	clientID    string
+	redirectURI string
+	nonce       string
+	challenge   string
+	email       string
+}
+
+type Provider struct {
+	Issuer   string
+	ClientID string
+	Email    string
+	Name     string
+	// Claims override those of the ID tokens issued. A nil value drops
+	// the claim.
+	Claims map[string]interface{}
+
+	key   *rsa.PrivateKey
+	mu    sync.Mutex
+	codes map[string]authRequest
+}
+
+func New(issuer, clientID, email string) (*Provider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	return &Provider{
+		Issuer:   issuer,
+		ClientID: clientID,
+		Email:    email,
+		Name:     "Test User",
+		key:      key,
+		codes:    map[string]authRequest{},
+	}, nil
+}
+
+// NewServer starts the provider on a loopback httptest server and sets
+// Issuer to its URL. Callers must Close the returned server.
+func NewServer(clientID, email string) (*Provider, *httptest.Server, error) {
+	p, err := New("", clientID, email)
+	if err != nil {
+		return nil, nil, err
+	}
+	srv := httptest.NewServer(p)
+	p.Issuer = srv.URL
+	return p, srv, nil
+}
+
+func (p *Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/.well-known/openid-configuration":
+		p.handleDiscovery(w, r)
+	case "/authorize":
+		p.handleAuthorize(w, r)
+	case "/token":
+		p.handleToken(w, r)
+	case "/jwks":
+		p.handleJWKS(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (p *Provider) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.Issuer,
+		"authorization_endpoint":                p.Issuer + "/authorize",
+		"token_endpoint":                        p.Issuer + "/token",
+		"jwks_uri":                              p.Issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// handleAuthorize approves the request without user interaction. A
+// login_hint overrides the configured email for that request.
+func (p *Provider) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != p.ClientID || q.Get("response_type") != "code" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "PKCE S256 challenge required", http.StatusBadRequest)
+		return
+	}
+
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirect.String() == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	email := p.Email
+	if hint := q.Get("login_hint"); hint != "" {
+		email = hint
+	}
+
+	code, err := oidc.RandomString()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	p.mu.Lock()
+	p.codes[code] = authRequest{
+		clientID:    q.Get("client_id"),
+		redirectURI: redirect.String(),
+		nonce:       q.Get("nonce"),
+		challenge:   q.Get("code_challenge"),
+		email:       email,
+	}
+	p.mu.Unlock()
+
+	rq := redirect.Query()
+	rq.Set("code", code)
+	rq.Set("state", q.Get("state"))
+	redirect.RawQuery = rq.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (p *Provider) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, "unsupported_grant_type")
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	p.mu.Lock()
+	req, ok := p.codes[code]
+	delete(p.codes, code)
+	p.mu.Unlock()
+	if !ok {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	clientID := r.PostForm.Get("client_id")
+	if user, _, ok := r.BasicAuth(); ok {
+		clientID, _ = url.QueryUnescape(user)
+	}
+	if clientID != req.clientID || r.PostForm.Get("redirect_uri") != req.redirectURI {
+		tokenError(w, "invalid_grant")
+		return
+	}
+	if oidc.CodeChallenge(r.PostForm.Get("code_verifier")) != req.challenge {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now().UTC()
+	claims := jwt.MapClaims{
+		"iss":            p.Issuer,
+		"sub":            req.email,
+		"aud":            req.clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          req.nonce,
+		"email":          req.email,
+		"email_verified": true,
+		"name":           p.Name,
+	}
+	for name, v := range p.Claims {
+		if v == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = v
+		}
+	}
+	idToken, err := p.Sign(KeyID, claims)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": idToken,
+		"id_token":     idToken,
+		"token_type":   "Bearer",
+		"expires_in":   300,
+	})
+}
+
+// Sign signs claims as an ID token with the provider key, naming the key
+// kid in the header.
+func (p *Provider) Sign(kid string, claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	return token.SignedString(p.key)
+}
+
+func (p *Provider) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	pub := p.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": KeyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	resp, err := json.Marshal(v)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(resp)
+}
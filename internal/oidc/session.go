@@ -0,0 +1,60 @@
+package oidc
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Session carries the per-login values that must survive the redirect
+// to the provider. It is stored client side in a signed cookie so any
+// replica can complete the callback.
+type Session struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	jwt.RegisteredClaims
+}
+
+func NewSession() (*Session, error) {
+	state, err := RandomString()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := RandomString()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := RandomString()
+	if err != nil {
+		return nil, err
+	}
+	return &Session{State: state, Nonce: nonce, Verifier: verifier}, nil
+}
+
+func (s *Session) Encode(secret string, expiresIn time.Duration) (string, error) {
+	s.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    "synlabs-oidc",
+		IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+		ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(expiresIn)),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, s)
+	return token.SignedString([]byte(secret))
+}
+
+func DecodeSession(tokenString, secret string) (*Session, error) {
+	s := &Session{}
+	_, err := jwt.ParseWithClaims(
+		tokenString,
+		s,
+		func(token *jwt.Token) (interface{}, error) {
+			return []byte(secret), nil
+		},
+		jwt.WithIssuer("synlabs-oidc"),
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,257 @@
+// Package openapi builds OpenAPI 3.1 documents from Go types, so the
+// published schemas follow the payload structs the handlers decode and
+// encode instead of drifting from them.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const Version = "3.1.0"
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+	Tags       []Tag               `json:"tags,omitempty"`
+
+	schemas *Schemas
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem maps lower case HTTP methods to their operation.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// New returns an empty document whose component schemas are generated by
+// schemas.
+func New(info Info, schemas *Schemas) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas:         schemas.components,
+			SecuritySchemes: map[string]*SecurityScheme{},
+		},
+		schemas: schemas,
+	}
+}
+
+// Route describes an operation in terms of Go values. Request and
+// response bodies are zero values of the types that are encoded.
+type Route struct {
+	Summary     string
+	Description string
+	Tags        []string
+	// Security names the schemes any one of which grants access.
+	Security   []string
+	Params     []Param
+	Request    interface{}
+	Responses  []Body
+	Deprecated bool
+}
+
+type Param struct {
+	Name        string
+	In          string // "path" or "query"; path parameters are always required
+	Description string
+	Type        interface{}
+	Required    bool
+}
+
+type Body struct {
+	Status      int
+	Description string
+	// ContentType defaults to application/json.
+	ContentType string
+	// Type is nil for responses without a body.
+	Type interface{}
+}
+
+var wildcard = regexp.MustCompile(`\{([^}.$]+)(\.\.\.)?\}`)
+
+// Add documents the ServeMux pattern "METHOD /path" with r. Wildcards
+// in the path must be described in r.Params.
+func (d *Document) Add(pattern string, r Route) error {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		return fmt.Errorf("pattern %q has no method", pattern)
+	}
+	path = strings.TrimSuffix(path, "{$}")
+	path = wildcard.ReplaceAllString(path, "{$1}")
+
+	op := &Operation{
+		OperationID: operationID(method, path),
+		Summary:     r.Summary,
+		Description: r.Description,
+		Tags:        r.Tags,
+		Responses:   map[string]*Response{},
+		Deprecated:  r.Deprecated,
+	}
+	for _, name := range r.Security {
+		op.Security = append(op.Security, map[string][]string{name: {}})
+	}
+
+	declared := map[string]bool{}
+	for _, p := range r.Params {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        p.Name,
+			In:          p.In,
+			Description: p.Description,
+			Required:    p.Required || p.In == "path",
+			Schema:      d.schemas.For(p.Type),
+		})
+		if p.In == "path" {
+			declared[p.Name] = true
+		}
+	}
+	for _, m := range wildcard.FindAllStringSubmatch(path, -1) {
+		if !declared[m[1]] {
+			return fmt.Errorf("%s: path parameter %q is not described", pattern, m[1])
+		}
+	}
+
+	if r.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: d.schemas.For(r.Request)}},
+		}
+	}
+	for _, b := range r.Responses {
+		status := strconv.Itoa(b.Status)
+		res, ok := op.Responses[status]
+		if !ok {
+			res = &Response{Description: b.Description}
+			if res.Description == "" {
+				res.Description = http.StatusText(b.Status)
+			}
+			op.Responses[status] = res
+		} else if b.Description != "" && !strings.Contains(res.Description, b.Description) {
+			res.Description += "; " + b.Description
+		}
+		if b.Type != nil {
+			ct := b.ContentType
+			if ct == "" {
+				ct = "application/json"
+			}
+			if res.Content == nil {
+				res.Content = map[string]MediaType{}
+			}
+			res.Content[ct] = MediaType{Schema: d.schemas.For(b.Type)}
+		}
+	}
+
+	item, ok := d.Paths[path]
+	if !ok {
+		item = PathItem{}
+		d.Paths[path] = item
+	}
+	key := strings.ToLower(method)
+	if _, dup := item[key]; dup {
+		return fmt.Errorf("%s is documented twice", pattern)
+	}
+	item[key] = op
+	return nil
+}
+
+// Has reports whether the ServeMux pattern is documented.
+func (d *Document) Has(pattern string) bool {
+	method, path, _ := strings.Cut(pattern, " ")
+	path = wildcard.ReplaceAllString(strings.TrimSuffix(path, "{$}"), "{$1}")
+	_, ok := d.Paths[path][strings.ToLower(method)]
+	return ok
+}
+
+// Patterns lists every documented operation as a ServeMux pattern
+// without wildcard modifiers, sorted.
+func (d *Document) Patterns() []string {
+	var out []string
+	for path, item := range d.Paths {
+		for method := range item {
+			out = append(out, strings.ToUpper(method)+" "+path)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// operationID turns "GET /admin/job/{job_id}" into "getAdminJobJobId".
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	upper := true
+	for _, r := range path {
+		switch {
+		case r == '/' || r == '{' || r == '}' || r == '_' || r == '-' || r == '.':
+			upper = true
+		case upper:
+			b.WriteString(strings.ToUpper(string(r)))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,78 @@
+package openapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+type address struct {
+	Street string `json:"street"`
+}
+
+type person struct {
+	Name     string            `json:"name"`
+	Nickname string            `json:"nickname,omitempty"`
+	Born     time.Time         `json:"born"`
+	Home     *address          `json:"home"`
+	Tags     []string          `json:"tags"`
+	Extra    map[string]string `json:"extra,omitempty"`
+	Secret   string            `json:"-"`
+	internal string
+}
+
+func TestSchemaFromStruct(t *testing.T) {
+	s := NewSchemas()
+	ref := s.For(person{})
+	if ref.Ref != "#/components/schemas/Person" {
+		t.Fatalf("ref = %q", ref.Ref)
+	}
+
+	got, _ := json.Marshal(s.components)
+	want := `{"Address":{"type":"object","properties":{"street":{"type":"string"}},"required":["street"]},` +
+		`"Person":{"type":"object","properties":{` +
+		`"born":{"type":"string","format":"date-time"},` +
+		`"extra":{"type":"object","additionalProperties":{"type":"string"}},` +
+		`"home":{"anyOf":[{"$ref":"#/components/schemas/Address"},{"type":"null"}]},` +
+		`"name":{"type":"string"},"nickname":{"type":"string"},` +
+		`"tags":{"type":"array","items":{"type":"string"}}},` +
+		`"required":["name","born","home","tags"]}}`
+	if string(got) != want {
+		t.Errorf("components =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAddRoute(t *testing.T) {
+	doc := New(Info{Title: "test", Version: "1"}, NewSchemas())
+
+	err := doc.Add("GET /people/{id}", Route{
+		Params:    []Param{{Name: "id", In: "path", Type: int32(0)}},
+		Responses: []Body{{Status: 200, Type: person{}}, {Status: 404}},
+		Security:  []string{"bearer"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Has("GET /people/{id}") || doc.Has("POST /people/{id}") {
+		t.Error("Has does not match the documented operation")
+	}
+	op := doc.Paths["/people/{id}"]["get"]
+	if op.OperationID != "getPeopleId" || !op.Parameters[0].Required {
+		t.Errorf("operation = %+v", op)
+	}
+	if op.Responses["404"].Description != "Not Found" {
+		t.Errorf("404 description = %q", op.Responses["404"].Description)
+	}
+
+	err = doc.Add("DELETE /people/{id}", Route{})
+	if err == nil || !strings.Contains(err.Error(), `"id"`) {
+		t.Errorf("undocumented path parameter accepted: %v", err)
+	}
+	if err := doc.Add("GET /people/{id}", Route{Params: []Param{{Name: "id", In: "path"}}}); err == nil {
+		t.Error("duplicate operation accepted")
+	}
+	if err := doc.Add("GET /{$}", Route{}); err != nil || !doc.Has("GET /{$}") {
+		t.Errorf("root pattern: %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Schema is a JSON Schema (draft 2020-12) as used by OpenAPI 3.1. Type
+// holds a string, or a []string when null is allowed too.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+}
+
+// Nullable returns a copy of s that also accepts null.
+func (s *Schema) Nullable() *Schema {
+	if s.Ref != "" || s.Type == nil {
+		return &Schema{AnyOf: []*Schema{s, {Type: "null"}}}
+	}
+	c := *s
+	if t, ok := s.Type.(string); ok {
+		c.Type = []string{t, "null"}
+	}
+	if c.Enum != nil {
+		c.Enum = append(c.Enum[:len(c.Enum):len(c.Enum)], nil)
+	}
+	return &c
+}
+
+// Schemas generates schemas from Go types. Named struct types become
+// components referenced by name; everything else is inlined.
+type Schemas struct {
+	overrides  map[reflect.Type]*Schema
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func NewSchemas() *Schemas {
+	return &Schemas{
+		overrides: map[reflect.Type]*Schema{
+			reflect.TypeOf(time.Time{}): {Type: "string", Format: "date-time"},
+		},
+		components: map[string]*Schema{},
+		names:      map[reflect.Type]string{},
+	}
+}
+
+// Define uses schema for every value of the type of v, for types whose
+// JSON encoding does not follow from their fields, such as those with a
+// custom MarshalJSON.
+func (s *Schemas) Define(v interface{}, schema *Schema) {
+	s.overrides[reflect.TypeOf(v)] = schema
+}
+
+// For returns the schema of the type of v.
+func (s *Schemas) For(v interface{}) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+	return s.forType(reflect.TypeOf(v))
+}
+
+func (s *Schemas) forType(t reflect.Type) *Schema {
+	if o, ok := s.overrides[t]; ok {
+		return o
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return s.forType(t.Elem()).Nullable()
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.forType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.forType(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return s.component(t)
+	default:
+		return &Schema{}
+	}
+}
+
+func (s *Schemas) component(t reflect.Type) *Schema {
+	name, ok := s.names[t]
+	if !ok {
+		name = componentName(t)
+		if _, taken := s.components[name]; taken {
+			name = componentName(t) + "_" + strings.ReplaceAll(t.PkgPath(), "/", "_")
+		}
+		s.names[t] = name
+		// Reserve the name before recursing so self references terminate.
+		s.components[name] = &Schema{}
+		*s.components[name] = *s.object(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func componentName(t reflect.Type) string {
+	r := []rune(t.Name())
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// object describes a struct the way encoding/json encodes it. Fields
+// without omitempty are required, as they are always present in
+// responses; request payloads mark optional fields with omitempty.
+func (s *Schemas) object(t reflect.Type) *Schema {
+	obj := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.addFields(obj, t)
+	return obj
+}
+
+func (s *Schemas) addFields(obj *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				s.addFields(obj, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		obj.Properties[name] = s.forType(f.Type)
+		if !strings.Contains(opts, "omitempty") {
+			obj.Required = append(obj.Required, name)
+		}
+	}
+}
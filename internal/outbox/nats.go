@@ -0,0 +1,83 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATS is a sink that publishes every event to a NATS server under
+// "<subject prefix>.<event type>".
+//
+// The connection is opened on the first publish, so the API starts
+// while the server is down; once open, the client reconnects on its own.
+// Each publish is flushed, and the server's answer to the flush confirms
+// the message was processed.
+type NATS struct {
+	url    string
+	prefix string
+	opts   []nats.Option
+
+	mu sync.Mutex
+	nc *nats.Conn
+}
+
+// NewNATS parses a nats://[user:pass@]host[:port] URL. token, when not
+// empty, authenticates instead of the user and password.
+func NewNATS(rawURL, subjectPrefix, token string) (*NATS, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "nats" || u.Hostname() == "" {
+		return nil, fmt.Errorf("NATS URL %q must be nats://host[:port]", rawURL)
+	}
+	opts := []nats.Option{nats.Name("synlabs-outbox"), nats.MaxReconnects(-1)}
+	if token != "" {
+		opts = append(opts, nats.Token(token))
+	}
+	return &NATS{url: rawURL, prefix: subjectPrefix, opts: opts}, nil
+}
+
+func (n *NATS) Name() string { return "nats" }
+
+func (n *NATS) Publish(ctx context.Context, e Event) error {
+	nc, err := n.conn()
+	if err != nil {
+		return err
+	}
+	if err := nc.Publish(n.prefix+"."+e.Type, e.Payload); err != nil {
+		return err
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		return nc.Flush()
+	}
+	return nc.FlushWithContext(ctx)
+}
+
+func (n *NATS) conn() (*nats.Conn, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.nc == nil {
+		nc, err := nats.Connect(n.url, n.opts...)
+		if err != nil {
+			return nil, fmt.Errorf("connecting to NATS: %w", err)
+		}
+		n.nc = nc
+	}
+	return n.nc, nil
+}
+
+// Close closes the connection to the server, if any.
+func (n *NATS) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.nc != nil {
+		n.nc.Close()
+		n.nc = nil
+	}
+	return nil
+}
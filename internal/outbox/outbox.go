@@ -0,0 +1,169 @@
+// Package outbox publishes domain events from the outbox table. Events
+// are written in the same transaction as the change they describe, so
+// none is lost when a request fails halfway; the Dispatcher then hands
+// each one to every Sink until all of them accept it.
+//
+// Delivery is at-least-once: an event is published again after a crash
+// between publishing and marking it published, and to every sink when
+// any one of them failed. Consumers deduplicate on Event.ID.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+	"github.com/Vikuuu/synlabs-assignment/internal/retry"
+)
+
+// Event is one row of the outbox. Payload is the JSON envelope with the
+// ID, type, creation time and data of the event.
+type Event struct {
+	ID        string
+	Type      string
+	Payload   []byte
+	CreatedAt time.Time
+}
+
+// Sink receives published events. Publish must not return before the
+// event is durably accepted, since the event is not retried afterwards.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, e Event) error
+}
+
+// Store is the part of database.Querier the Dispatcher uses.
+type Store interface {
+	ClaimOutboxEvents(ctx context.Context, arg database.ClaimOutboxEventsParams) ([]database.ClaimOutboxEventsRow, error)
+	MarkOutboxPublished(ctx context.Context, arg database.MarkOutboxPublishedParams) error
+	RecordOutboxFailure(ctx context.Context, arg database.RecordOutboxFailureParams) error
+	DeletePublishedOutboxEvents(ctx context.Context, publishedAt pgtype.Timestamp) (int64, error)
+}
+
+type Config struct {
+	// Backoff is the wait after the first failure. It doubles with every
+	// further failure up to MaxBackoff; events are never given up on.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Timeout bounds publishing one event to all sinks.
+	Timeout      time.Duration
+	PollInterval time.Duration
+	// Retention is how long published events are kept.
+	Retention time.Duration
+}
+
+type Dispatcher struct {
+	store  Store
+	sinks  []Sink
+	logger *slog.Logger
+	cfg    Config
+	// batch is how many events one poll claims.
+	batch int32
+	now   func() time.Time
+}
+
+func NewDispatcher(store Store, sinks []Sink, logger *slog.Logger, cfg Config) *Dispatcher {
+	return &Dispatcher{
+		store:  store,
+		sinks:  sinks,
+		logger: logger,
+		cfg:    cfg,
+		batch:  100,
+		now:    time.Now,
+	}
+}
+
+// Run publishes pending events every poll interval until ctx is
+// cancelled, and prunes old published ones along the way.
+func (d *Dispatcher) Run(ctx context.Context) {
+	t := time.NewTicker(d.cfg.PollInterval)
+	defer t.Stop()
+	lastPrune := time.Time{}
+	for {
+		for {
+			n, err := d.DispatchDue(ctx)
+			if err != nil && ctx.Err() == nil {
+				d.logger.Error("dispatching outbox", "error", err)
+			}
+			// A full batch means more are probably waiting.
+			if err != nil || n < int(d.batch) {
+				break
+			}
+		}
+		if d.now().Sub(lastPrune) > time.Hour {
+			lastPrune = d.now()
+			if _, err := d.Prune(ctx); err != nil && ctx.Err() == nil {
+				d.logger.Error("pruning outbox", "error", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// DispatchDue claims the events that are due and publishes each of them
+// once, returning how many it tried.
+func (d *Dispatcher) DispatchDue(ctx context.Context) (int, error) {
+	now := d.now()
+	// Publishing an event may take up to Timeout, so the lease outlives
+	// it; an event claimed by a process that died is retried afterwards.
+	rows, err := d.store.ClaimOutboxEvents(ctx, database.ClaimOutboxEventsParams{
+		LeaseUntil: now.Add(2 * d.cfg.Timeout),
+		Now:        now,
+		MaxEvents:  d.batch,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("claiming events: %w", err)
+	}
+
+	for _, row := range rows {
+		e := Event{ID: row.EventID, Type: row.EventType, Payload: row.Payload, CreatedAt: row.CreatedAt}
+		if err := d.publish(ctx, e); err != nil {
+			attempts := int(row.Attempts) + 1
+			d.logger.Warn("publishing event failed", "event_id", e.ID, "event", e.Type, "attempts", attempts, "error", err)
+			err = d.store.RecordOutboxFailure(ctx, database.RecordOutboxFailureParams{
+				ID:            row.ID,
+				NextAttemptAt: d.now().Add(retry.Backoff(attempts, d.cfg.Backoff, d.cfg.MaxBackoff)),
+				LastError:     pgtype.Text{String: err.Error(), Valid: true},
+			})
+		} else {
+			err = d.store.MarkOutboxPublished(ctx, database.MarkOutboxPublishedParams{
+				ID:          row.ID,
+				PublishedAt: pgtype.Timestamp{Time: d.now(), Valid: true},
+			})
+		}
+		if err != nil {
+			return 0, fmt.Errorf("recording event %s: %w", row.EventID, err)
+		}
+	}
+	return len(rows), nil
+}
+
+func (d *Dispatcher) publish(ctx context.Context, e Event) error {
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+	defer cancel()
+
+	var errs []error
+	for _, s := range d.sinks {
+		if err := s.Publish(ctx, e); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Prune deletes events published longer than the retention ago.
+func (d *Dispatcher) Prune(ctx context.Context) (int64, error) {
+	return d.store.DeletePublishedOutboxEvents(ctx, pgtype.Timestamp{
+		Time:  d.now().Add(-d.cfg.Retention),
+		Valid: true,
+	})
+}
@@ -0,0 +1,219 @@
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+	"github.com/Vikuuu/synlabs-assignment/internal/memstore"
+)
+
+func newTestDispatcher(store Store, sinks []Sink, now *time.Time) *Dispatcher {
+	d := NewDispatcher(store, sinks, slog.New(slog.NewTextHandler(io.Discard, nil)), Config{
+		Backoff:      time.Minute,
+		MaxBackoff:   time.Hour,
+		Timeout:      5 * time.Second,
+		PollInterval: time.Second,
+		Retention:    24 * time.Hour,
+	})
+	d.now = func() time.Time { return *now }
+	return d
+}
+
+func addEvent(t *testing.T, store *memstore.Store, id string, now time.Time) {
+	t.Helper()
+	err := store.CreateOutboxEvent(context.Background(), database.CreateOutboxEventParams{
+		EventID:   id,
+		EventType: "job.created",
+		Payload:   []byte(fmt.Sprintf(`{"id":%q}`, id)),
+		CreatedAt: now,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDispatchPublishesToEverySink(t *testing.T) {
+	now := time.Now()
+	store := memstore.New()
+	addEvent(t, store, "evt_1", now)
+	addEvent(t, store, "evt_2", now)
+
+	handlers := NewHandlers()
+	got := []string{}
+	handlers.Subscribe("job.created", func(ctx context.Context, e Event) error {
+		got = append(got, e.ID)
+		return nil
+	})
+	handlers.Subscribe("job.closed", func(ctx context.Context, e Event) error {
+		t.Errorf("job.closed handler called with %s", e.Type)
+		return nil
+	})
+	out := &bytes.Buffer{}
+
+	d := newTestDispatcher(store, []Sink{handlers, NewWriter(out)}, &now)
+	if n, err := d.DispatchDue(context.Background()); err != nil || n != 2 {
+		t.Fatalf("DispatchDue = %d, %v", n, err)
+	}
+	if strings.Join(got, ",") != "evt_1,evt_2" {
+		t.Errorf("handled %v", got)
+	}
+	if out.String() != "{\"id\":\"evt_1\"}\n{\"id\":\"evt_2\"}\n" {
+		t.Errorf("written %q", out)
+	}
+	for _, e := range store.Outbox() {
+		if !e.PublishedAt.Valid {
+			t.Errorf("%s not marked published", e.EventID)
+		}
+	}
+	if n, _ := d.DispatchDue(context.Background()); n != 0 {
+		t.Errorf("published events were dispatched again")
+	}
+
+	now = now.Add(25 * time.Hour)
+	if n, err := d.Prune(context.Background()); err != nil || n != 2 || len(store.Outbox()) != 0 {
+		t.Errorf("Prune = %d, %v", n, err)
+	}
+}
+
+func TestDispatchRetriesFailedEvents(t *testing.T) {
+	now := time.Now()
+	store := memstore.New()
+	addEvent(t, store, "evt_1", now)
+
+	calls := 0
+	handlers := NewHandlers()
+	handlers.Subscribe("job.created", func(ctx context.Context, e Event) error {
+		calls++
+		if calls == 1 {
+			return errors.New("not yet")
+		}
+		return nil
+	})
+
+	d := newTestDispatcher(store, []Sink{handlers}, &now)
+	ctx := context.Background()
+	if _, err := d.DispatchDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	e := store.Outbox()[0]
+	if e.PublishedAt.Valid || e.Attempts != 1 || !strings.Contains(e.LastError.String, "not yet") {
+		t.Fatalf("after failure: %+v", e)
+	}
+	if n, _ := d.DispatchDue(ctx); n != 0 {
+		t.Fatalf("retried before the backoff")
+	}
+
+	now = now.Add(time.Minute)
+	if n, err := d.DispatchDue(ctx); err != nil || n != 1 {
+		t.Fatalf("DispatchDue = %d, %v", n, err)
+	}
+	if e := store.Outbox()[0]; !e.PublishedAt.Valid || calls != 2 {
+		t.Errorf("after retry: %+v, calls = %d", e, calls)
+	}
+}
+
+// fakeNATS speaks enough of the NATS protocol to record what clients
+// publish.
+type fakeNATS struct {
+	ln        net.Listener
+	token     string
+	published chan string
+}
+
+func startFakeNATS(t *testing.T, token string) *fakeNATS {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	f := &fakeNATS{ln: ln, token: token, published: make(chan string, 10)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeNATS) serve(conn net.Conn) {
+	defer conn.Close()
+	fmt.Fprintf(conn, "INFO {\"server_id\":\"fake\",\"auth_required\":true,\"max_payload\":1048576}\r\n")
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		op, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+		switch op {
+		case "CONNECT":
+			if !strings.Contains(args, fmt.Sprintf(`"auth_token":%q`, f.token)) {
+				fmt.Fprintf(conn, "-ERR 'Authorization Violation'\r\n")
+				return
+			}
+		case "PUB":
+			subject, size, _ := strings.Cut(args, " ")
+			n, _ := strconv.Atoi(size)
+			payload := make([]byte, n+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return
+			}
+			f.published <- subject + " " + string(payload[:n])
+		case "PING":
+			fmt.Fprintf(conn, "PONG\r\n")
+		}
+	}
+}
+
+func TestNATSPublishes(t *testing.T) {
+	srv := startFakeNATS(t, "s3cret")
+	sink, err := NewNATS("nats://"+srv.ln.Addr().String(), "synlabs.events", "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, id := range []string{"evt_1", "evt_2"} {
+		if err := sink.Publish(ctx, Event{ID: id, Type: "job.created", Payload: []byte(`{"id":"` + id + `"}`)}); err != nil {
+			t.Fatalf("publishing %s: %v", id, err)
+		}
+		// The flush's PONG follows the PUB, so the message has arrived.
+		if got, want := <-srv.published, `synlabs.events.job.created {"id":"`+id+`"}`; got != want {
+			t.Errorf("published %q, want %q", got, want)
+		}
+	}
+
+	bad, _ := NewNATS("nats://"+srv.ln.Addr().String(), "synlabs.events", "wrong")
+	if err := bad.Publish(ctx, Event{Type: "job.created", Payload: []byte(`{}`)}); !errors.Is(err, nats.ErrAuthorization) {
+		t.Errorf("publishing with the wrong token: %v", err)
+	}
+}
+
+func TestNewNATSRejectsOtherSchemes(t *testing.T) {
+	if _, err := NewNATS("http://localhost:4222", "x", ""); err == nil {
+		t.Error("http URL accepted")
+	}
+	if _, err := NewNATS("nats://ann:pw@localhost", "x", ""); err != nil {
+		t.Errorf("nats URL with credentials: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Handlers is a sink that calls the functions subscribed in-process to
+// an event type. Since events may be published more than once, handlers
+// should be idempotent.
+type Handlers struct {
+	mu       sync.RWMutex
+	handlers map[string][]func(context.Context, Event) error
+}
+
+func NewHandlers() *Handlers {
+	return &Handlers{handlers: map[string][]func(context.Context, Event) error{}}
+}
+
+// Subscribe calls fn for every event of the given type.
+func (h *Handlers) Subscribe(eventType string, fn func(context.Context, Event) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handlers[eventType] = append(h.handlers[eventType], fn)
+}
+
+func (h *Handlers) Name() string { return "in-process" }
+
+// Publish calls every handler of the event's type, even after one of
+// them failed.
+func (h *Handlers) Publish(ctx context.Context, e Event) error {
+	h.mu.RLock()
+	fns := h.handlers[e.Type]
+	h.mu.RUnlock()
+
+	var errs []error
+	for _, fn := range fns {
+		if err := fn(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Writer is a sink that writes every event payload to w as one line of
+// JSON, e.g. to stdout for a log shipper to pick up.
+type Writer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+func (w *Writer) Name() string { return "writer" }
+
+func (w *Writer) Publish(ctx context.Context, e Event) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := fmt.Fprintf(w.w, "%s\n", e.Payload)
+	return err
+}
@@ -0,0 +1,19 @@
+// Package retry spaces out retries of failed deliveries.
+package retry
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff is the wait after the given number of failed attempts: base
+// doubled per earlier failure and capped at max, less up to a fifth of
+// it as jitter so receivers that were down are not hit all at once.
+func Backoff(attempts int, base, max time.Duration) time.Duration {
+	d := base
+	for i := 1; i < attempts && d < max; i++ {
+		d *= 2
+	}
+	d = min(d, max)
+	return d - rand.N(d/5+1)
+}
@@ -0,0 +1,15 @@
+package retry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{1: time.Second, 3: 4 * time.Second, 10: time.Minute} {
+		got := Backoff(attempts, time.Second, time.Minute)
+		if got > want || got < want*4/5 {
+			t.Errorf("Backoff(%d) = %s, want within a fifth below %s", attempts, got, want)
+		}
+	}
+}
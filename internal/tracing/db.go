@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+)
+
+// InstrumentDB wraps db so every query runs in a client span named after
+// the sqlc query.
+func InstrumentDB(db database.DBTX) database.DBTX {
+	return database.WithHook(db, func(ctx context.Context, query string) (context.Context, func(error)) {
+		name := database.QueryName(query)
+		ctx, span := Tracer().Start(
+			ctx,
+			"db "+name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemPostgreSQL,
+				attribute.String("db.operation.name", name),
+				semconv.DBQueryText(query),
+			),
+		)
+		return ctx, func(err error) { end(span, err) }
+	})
+}
+
+func end(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return rec
+}
+
+func TestHandlerContinuesTraceAndNamesRoute(t *testing.T) {
+	rec := recordSpans(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/job/{job_id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	req := httptest.NewRequest("GET", "/admin/job/7", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	Handler(mux).ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /admin/job/{job_id}" {
+		t.Errorf("span name = %q", span.Name())
+	}
+	if got := span.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace ID = %s, want the incoming one", got)
+	}
+	if got := span.Parent().SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("parent span = %s, want the incoming one", got)
+	}
+}
+
+type fakeDB struct{}
+
+func (fakeDB) Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, errors.New("connection closed")
+}
+
+func (fakeDB) Query(context.Context, string, ...interface{}) (pgx.Rows, error) {
+	return nil, errors.New("connection closed")
+}
+
+func (fakeDB) QueryRow(context.Context, string, ...interface{}) pgx.Row {
+	return nil
+}
+
+func TestInstrumentDBNamesSpansAfterQuery(t *testing.T) {
+	rec := recordSpans(t)
+
+	ctx, parent := Tracer().Start(context.Background(), "request")
+	db := InstrumentDB(fakeDB{})
+	db.Exec(ctx, "-- name: ApplyJob :exec\nINSERT INTO apply_jobs VALUES ($1, $2)", 1, 2)
+	parent.End()
+
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	query := spans[0]
+	if query.Name() != "db ApplyJob" {
+		t.Errorf("span name = %q", query.Name())
+	}
+	if query.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("query span is not a child of the request span")
+	}
+	if query.Status().Code.String() != "Error" {
+		t.Errorf("status = %s, want Error", query.Status().Code)
+	}
+}
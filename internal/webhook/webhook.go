@@ -0,0 +1,225 @@
+// Package webhook signs and delivers webhook events. Events are queued as
+// delivery rows in the same transaction as the change they describe;
+// the Dispatcher sends them, retrying with exponential backoff until a
+// delivery succeeds or runs out of attempts and is dead.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+	"github.com/Vikuuu/synlabs-assignment/internal/retry"
+)
+
+const (
+	EventHeader     = "Synlabs-Event"
+	EventIDHeader   = "Synlabs-Event-Id"
+	SignatureHeader = "Synlabs-Signature"
+)
+
+var ErrSignature = errors.New("webhook signature mismatch")
+
+func mac(secret string, t time.Time, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(h, "%d.", t.Unix())
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// Sign returns the signature header for body sent at t:
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">". Binding the
+// timestamp lets receivers reject replays.
+func Sign(secret string, t time.Time, body []byte) string {
+	return fmt.Sprintf("t=%d,v1=%s", t.Unix(), hex.EncodeToString(mac(secret, t, body)))
+}
+
+// Verify checks a signature header made by Sign and that its timestamp
+// is within tolerance of now, either way, so that neither old nor
+// pre-dated signatures can be replayed.
+func Verify(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var ts int64
+	var sigs [][]byte
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts, _ = strconv.ParseInt(v, 10, 64)
+		case "v1":
+			if sig, err := hex.DecodeString(v); err == nil {
+				sigs = append(sigs, sig)
+			}
+		}
+	}
+	if ts == 0 || len(sigs) == 0 {
+		return fmt.Errorf("%w: malformed header", ErrSignature)
+	}
+	t := time.Unix(ts, 0)
+	if d := now.Sub(t); d > tolerance {
+		return fmt.Errorf("%w: signed %s ago", ErrSignature, d.Round(time.Second))
+	} else if d < -tolerance {
+		return fmt.Errorf("%w: signed %s in the future", ErrSignature, (-d).Round(time.Second))
+	}
+
+	want := mac(secret, t, body)
+	for _, sig := range sigs {
+		if hmac.Equal(sig, want) {
+			return nil
+		}
+	}
+	return ErrSignature
+}
+
+// Store is the part of database.Querier the Dispatcher uses.
+type Store interface {
+	ClaimWebhookDeliveries(ctx context.Context, arg database.ClaimWebhookDeliveriesParams) ([]database.ClaimWebhookDeliveriesRow, error)
+	RecordWebhookAttempt(ctx context.Context, arg database.RecordWebhookAttemptParams) error
+}
+
+type Config struct {
+	// MaxAttempts is how often a delivery is tried before it is dead.
+	MaxAttempts int
+	// Backoff is the wait after the first failed attempt. It doubles
+	// with every further failure up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Timeout bounds each attempt.
+	Timeout      time.Duration
+	PollInterval time.Duration
+}
+
+type Dispatcher struct {
+	store  Store
+	http   *http.Client
+	logger *slog.Logger
+	cfg    Config
+	// batch is how many deliveries one poll claims.
+	batch int32
+	now   func() time.Time
+}
+
+func NewDispatcher(store Store, httpClient *http.Client, logger *slog.Logger, cfg Config) *Dispatcher {
+	return &Dispatcher{
+		store:  store,
+		http:   httpClient,
+		logger: logger,
+		cfg:    cfg,
+		batch:  50,
+		now:    time.Now,
+	}
+}
+
+// Run delivers due events every poll interval until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	t := time.NewTicker(d.cfg.PollInterval)
+	defer t.Stop()
+	for {
+		for {
+			n, err := d.DispatchDue(ctx)
+			if err != nil && ctx.Err() == nil {
+				d.logger.Error("dispatching webhooks", "error", err)
+			}
+			// A full batch means more are probably waiting.
+			if err != nil || n < int(d.batch) {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// DispatchDue claims the deliveries that are due and attempts each of
+// them once, returning how many it attempted.
+func (d *Dispatcher) DispatchDue(ctx context.Context) (int, error) {
+	now := d.now()
+	// The lease outlives an attempt, so a delivery stuck because this
+	// process died is picked up again afterwards.
+	rows, err := d.store.ClaimWebhookDeliveries(ctx, database.ClaimWebhookDeliveriesParams{
+		LeaseUntil:    now.Add(2 * d.cfg.Timeout),
+		Now:           now,
+		MaxDeliveries: d.batch,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("claiming deliveries: %w", err)
+	}
+
+	for _, row := range rows {
+		status, err := d.send(ctx, row)
+		if err := d.store.RecordWebhookAttempt(ctx, d.outcome(row, status, err)); err != nil {
+			return 0, fmt.Errorf("recording delivery %d: %w", row.ID, err)
+		}
+	}
+	return len(rows), nil
+}
+
+func (d *Dispatcher) send(ctx context.Context, row database.ClaimWebhookDeliveriesRow) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, row.Url, bytes.NewReader(row.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "synlabs-webhooks/1")
+	req.Header.Set(EventHeader, row.EventType)
+	req.Header.Set(EventIDHeader, row.EventID)
+	req.Header.Set(SignatureHeader, Sign(row.Secret, d.now(), row.Payload))
+
+	res, err := d.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+		return res.StatusCode, nil
+	}
+	snippet, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+	return res.StatusCode, fmt.Errorf("status %d: %s", res.StatusCode, bytes.TrimSpace(snippet))
+}
+
+// outcome records an attempt: delivered, retried after a backoff, or
+// dead once the attempts are used up.
+func (d *Dispatcher) outcome(row database.ClaimWebhookDeliveriesRow, status int, err error) database.RecordWebhookAttemptParams {
+	now := d.now()
+	p := database.RecordWebhookAttemptParams{
+		ID:             row.ID,
+		Status:         database.WebhookDeliveryStatusDelivered,
+		NextAttemptAt:  now,
+		LastAttemptAt:  pgtype.Timestamp{Time: now, Valid: true},
+		LastStatusCode: pgtype.Int4{Int32: int32(status), Valid: status != 0},
+	}
+	if err == nil {
+		return p
+	}
+
+	p.LastError = pgtype.Text{String: err.Error(), Valid: true}
+	attempts := int(row.Attempts) + 1
+	if attempts >= d.cfg.MaxAttempts {
+		p.Status = database.WebhookDeliveryStatusDead
+		d.logger.Warn("webhook delivery dead", "delivery_id", row.ID, "event", row.EventType, "attempts", attempts, "error", err)
+		return p
+	}
+	p.Status = database.WebhookDeliveryStatusPending
+	p.NextAttemptAt = now.Add(retry.Backoff(attempts, d.cfg.Backoff, d.cfg.MaxBackoff))
+	d.logger.Info("webhook delivery failed", "delivery_id", row.ID, "event", row.EventType, "attempts", attempts, "error", err)
+	return p
+}
@@ -0,0 +1,160 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+	"github.com/Vikuuu/synlabs-assignment/internal/memstore"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"id":"evt_1"}`)
+	header := Sign("whsec_test", now, body)
+
+	if err := Verify("whsec_test", header, body, now.Add(time.Minute), 5*time.Minute); err != nil {
+		t.Errorf("valid signature: %v", err)
+	}
+	if err := Verify("whsec_test", header, body, now.Add(-time.Minute), 5*time.Minute); err != nil {
+		t.Errorf("clock skew within tolerance: %v", err)
+	}
+	for name, err := range map[string]error{
+		"wrong secret": Verify("whsec_other", header, body, now, 5*time.Minute),
+		"changed body": Verify("whsec_test", header, []byte(`{"id":"evt_2"}`), now, 5*time.Minute),
+		"too old":      Verify("whsec_test", header, body, now.Add(time.Hour), 5*time.Minute),
+		"pre-dated":    Verify("whsec_test", header, body, now.Add(-time.Hour), 5*time.Minute),
+		"malformed":    Verify("whsec_test", "v1=zz", body, now, 5*time.Minute),
+	} {
+		if !errors.Is(err, ErrSignature) {
+			t.Errorf("%s: err = %v, want ErrSignature", name, err)
+		}
+	}
+}
+
+// queue subscribes url and queues one event for it.
+func queue(t *testing.T, store *memstore.Store, url string, now time.Time) int64 {
+	t.Helper()
+	ctx := context.Background()
+	admin, err := store.CreateUser(ctx, database.CreateUserParams{Email: "admin@example.com", UserType: database.UserTypeAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := store.CreateWebhook(ctx, database.CreateWebhookParams{
+		Url:       url,
+		Secret:    "whsec_test",
+		Events:    []string{"job.created"},
+		CreatedBy: admin.ID,
+		CreatedAt: now,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+		SubscriptionID: sub.ID,
+		EventID:        "evt_1",
+		EventType:      "job.created",
+		Payload:        []byte(`{"id":"evt_1"}`),
+		NextAttemptAt:  now,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliveries, err := store.ListWebhookDeliveries(ctx, database.ListWebhookDeliveriesParams{SubscriptionID: sub.ID, Limit: 1})
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("deliveries = %+v, err = %v", deliveries, err)
+	}
+	return deliveries[0].ID
+}
+
+func delivery(t *testing.T, store *memstore.Store, id int64) database.WebhookDelivery {
+	t.Helper()
+	all, _ := store.ListWebhookDeliveries(context.Background(), database.ListWebhookDeliveriesParams{SubscriptionID: 1, Limit: 100})
+	for _, d := range all {
+		if d.ID == id {
+			return d
+		}
+	}
+	t.Fatalf("delivery %d not found", id)
+	return database.WebhookDelivery{}
+}
+
+func newTestDispatcher(store Store, now *time.Time) *Dispatcher {
+	d := NewDispatcher(store, http.DefaultClient, slog.New(slog.NewTextHandler(io.Discard, nil)), Config{
+		MaxAttempts:  3,
+		Backoff:      time.Minute,
+		MaxBackoff:   time.Hour,
+		Timeout:      5 * time.Second,
+		PollInterval: time.Second,
+	})
+	d.now = func() time.Time { return *now }
+	return d
+}
+
+func TestDispatchSignsAndDelivers(t *testing.T) {
+	now := time.Now()
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	store := memstore.New()
+	id := queue(t, store, srv.URL, now)
+	n, err := newTestDispatcher(store, &now).DispatchDue(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("DispatchDue = %d, %v", n, err)
+	}
+
+	if got.Header.Get(EventHeader) != "job.created" || got.Header.Get(EventIDHeader) != "evt_1" {
+		t.Errorf("headers = %v", got.Header)
+	}
+	if err := Verify("whsec_test", got.Header.Get(SignatureHeader), body, now, time.Minute); err != nil {
+		t.Errorf("signature: %v", err)
+	}
+	if d := delivery(t, store, id); d.Status != database.WebhookDeliveryStatusDelivered || d.Attempts != 1 || d.LastStatusCode.Int32 != http.StatusOK {
+		t.Errorf("delivery = %+v", d)
+	}
+}
+
+func TestDispatchRetriesUntilDead(t *testing.T) {
+	now := time.Now()
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	store := memstore.New()
+	id := queue(t, store, srv.URL, now)
+	dispatcher := newTestDispatcher(store, &now)
+	ctx := context.Background()
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		if n, err := dispatcher.DispatchDue(ctx); err != nil || n != 1 {
+			t.Fatalf("attempt %d: DispatchDue = %d, %v", attempt, n, err)
+		}
+		// Nothing is due again until the backoff has passed.
+		if n, _ := dispatcher.DispatchDue(ctx); n != 0 {
+			t.Fatalf("attempt %d: retried before the backoff", attempt)
+		}
+		now = now.Add(2 * time.Hour)
+	}
+
+	d := delivery(t, store, id)
+	if calls != 3 || d.Status != database.WebhookDeliveryStatusDead || d.LastStatusCode.Int32 != http.StatusServiceUnavailable {
+		t.Errorf("calls = %d, delivery = %+v", calls, d)
+	}
+	if n, _ := dispatcher.DispatchDue(ctx); n != 0 {
+		t.Errorf("dead delivery was attempted again")
+	}
+}
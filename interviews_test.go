@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+)
+
+// interviewBody is a request to schedule an interview at start.
+func interviewBody(start time.Time, minutes int, interviewers ...int32) string {
+	ids := []string{}
+	for _, id := range interviewers {
+		ids = append(ids, fmt.Sprint(id))
+	}
+	return fmt.Sprintf(`{"starts_at":%q,"duration_minutes":%d,"interviewer_ids":[%s],"location":"Room 4","video_url":"https://meet.example.com/abc"}`,
+		start.Format(time.RFC3339), minutes, strings.Join(ids, ","))
+}
+
+// invite downloads an invite, checking its content type, and unfolds
+// its lines.
+func (a *testAPI) invite(path, token, method string) string {
+	a.t.Helper()
+	res := a.do("GET", path, "", token)
+	a.expect(res, http.StatusOK, nil)
+	if got := res.Header.Get("Content-Type"); got != "text/calendar; charset=utf-8; method="+method {
+		a.t.Errorf("Content-Type = %q", got)
+	}
+	body, _ := io.ReadAll(res.Body)
+	return strings.ReplaceAll(string(body), "\r\n ", "")
+}
+
+func TestInterviewScheduling(t *testing.T) {
+	api, _ := newMailTestAPI(t)
+	admin := api.signup("admin@example.com", "admin")
+	api.signup("second@example.com", "admin")
+	applicant := api.signup("applicant@example.com", "applicant")
+	other := api.signup("other@example.com", "applicant")
+	adminID, secondID := api.userID("admin@example.com"), api.userID("second@example.com")
+	api.expect(api.do("POST", "/v1/admin/job", `{"title":"Backend","description":"d","company_name":"Acme"}`, admin), http.StatusCreated, nil)
+	api.expect(api.do("GET", "/v1/jobs/apply?job_id=1", "", applicant), http.StatusOK, nil)
+	api.expect(api.do("GET", "/v1/jobs/apply?job_id=1", "", other), http.StatusOK, nil)
+
+	start := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Minute)
+	created := interviewResponse{}
+	api.expect(api.do("POST", "/v1/admin/application/1/interviews", interviewBody(start, 45, adminID, secondID), admin), http.StatusCreated, &created)
+	if !created.StartsAt.Equal(start) || !created.EndsAt.Equal(start.Add(45*time.Minute)) || created.DurationMinutes != 45 ||
+		len(created.Interviewers) != 2 || created.Status != database.InterviewStatusScheduled || created.JobTitle != "Backend" {
+		t.Fatalf("created = %+v", created)
+	}
+
+	applicantID := api.userID("applicant@example.com")
+	api.expectProblem(api.do("POST", "/v1/admin/application/1/interviews", interviewBody(start, 45, applicantID), admin), http.StatusUnprocessableEntity, codeValidationFailed)
+	api.expectProblem(api.do("POST", "/v1/admin/application/1/interviews", interviewBody(start.Add(-72*time.Hour), 45, adminID), admin), http.StatusUnprocessableEntity, codeValidationFailed)
+	api.expectProblem(api.do("POST", "/v1/admin/application/1/interviews", interviewBody(start, 5, adminID), admin), http.StatusUnprocessableEntity, codeValidationFailed)
+	api.expectProblem(api.do("POST", "/v1/admin/application/9/interviews", interviewBody(start, 45, adminID), admin), http.StatusNotFound, codeApplicationNotFound)
+
+	// An interviewer cannot be in two interviews at once; back to back is fine.
+	api.expectProblem(api.do("POST", "/v1/admin/application/2/interviews", interviewBody(start.Add(30*time.Minute), 30, secondID), admin), http.StatusConflict, codeInterviewConflict)
+	api.expect(api.do("POST", "/v1/admin/application/2/interviews", interviewBody(start.Add(45*time.Minute), 30, secondID), admin), http.StatusCreated, nil)
+
+	list := []interviewResponse{}
+	api.expect(api.do("GET", "/v1/admin/application/1/interviews", "", admin), http.StatusOK, &list)
+	if len(list) != 1 || list[0].ID != created.ID {
+		t.Errorf("application interviews = %+v", list)
+	}
+	api.expect(api.do("GET", "/v1/me/interviews", "", other), http.StatusOK, &list)
+	if len(list) != 1 || list[0].ApplicationID != 2 {
+		t.Errorf("other applicant's interviews = %+v", list)
+	}
+	api.expectProblem(api.do("POST", "/v1/me/interviews/1/confirm", "", other), http.StatusNotFound, codeInterviewNotFound)
+
+	ics := api.invite("/v1/me/interviews/1/invite.ics", applicant, "REQUEST")
+	for _, want := range []string{
+		"UID:interview-1@jobs.example.com",
+		"DTSTART:" + start.Format("20060102T150405Z"),
+		"STATUS:TENTATIVE",
+		"PARTSTAT=NEEDS-ACTION",
+		"mailto:applicant@example.com",
+		"mailto:second@example.com",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("invite is missing %q:\n%s", want, ics)
+		}
+	}
+
+	confirmed := interviewResponse{}
+	api.expect(api.do("POST", "/v1/me/interviews/1/confirm", "", applicant), http.StatusOK, &confirmed)
+	if confirmed.Status != database.InterviewStatusConfirmed || confirmed.Sequence != created.Sequence+1 {
+		t.Errorf("confirmed = %+v", confirmed)
+	}
+	again := interviewResponse{}
+	api.expect(api.do("POST", "/v1/me/interviews/1/confirm", "", applicant), http.StatusOK, &again)
+	if again.Sequence != confirmed.Sequence {
+		t.Errorf("confirming again changed the interview: %+v", again)
+	}
+	if ics := api.invite("/v1/admin/interview/1/invite.ics", admin, "REQUEST"); !strings.Contains(ics, "STATUS:CONFIRMED") {
+		t.Errorf("confirmed invite:\n%s", ics)
+	}
+
+	api.expectProblem(api.do("POST", "/v1/me/interviews/1/reschedule", `{"note":" "}`, applicant), http.StatusUnprocessableEntity, codeValidationFailed)
+	requested := interviewResponse{}
+	api.expect(api.do("POST", "/v1/me/interviews/1/reschedule", `{"note":"I am travelling that day"}`, applicant), http.StatusOK, &requested)
+	if requested.Status != database.InterviewStatusRescheduleRequested || requested.RescheduleNote != "I am travelling that day" {
+		t.Errorf("reschedule requested = %+v", requested)
+	}
+
+	moved := interviewResponse{}
+	api.expect(api.do("PUT", "/v1/admin/interview/1", interviewBody(start.Add(24*time.Hour), 60, adminID), admin), http.StatusOK, &moved)
+	if moved.Status != database.InterviewStatusScheduled || moved.RescheduleNote != "" || len(moved.Interviewers) != 1 ||
+		moved.DurationMinutes != 60 || moved.Sequence <= requested.Sequence {
+		t.Errorf("moved = %+v", moved)
+	}
+
+	api.expect(api.do("POST", "/v1/admin/interview/1/cancel", "", admin), http.StatusOK, nil)
+	api.expectProblem(api.do("POST", "/v1/admin/interview/1/cancel", "", admin), http.StatusConflict, codeInterviewCancelled)
+	api.expectProblem(api.do("POST", "/v1/me/interviews/1/confirm", "", applicant), http.StatusConflict, codeInterviewCancelled)
+	api.expectProblem(api.do("PUT", "/v1/admin/interview/1", interviewBody(start, 60, adminID), admin), http.StatusConflict, codeInterviewCancelled)
+	if ics := api.invite("/v1/admin/interview/1/invite.ics", admin, "CANCEL"); !strings.Contains(ics, "STATUS:CANCELLED") {
+		t.Errorf("cancelled invite:\n%s", ics)
+	}
+	api.expectProblem(api.do("GET", "/v1/admin/interview/9/invite.ics", "", admin), http.StatusNotFound, codeInterviewNotFound)
+}
+
+func TestInterviewNotifications(t *testing.T) {
+	api, mailer := newMailTestAPI(t)
+	admin := api.signup("admin@example.com", "admin")
+	applicant := api.signup("applicant@example.com", "applicant")
+	api.expect(api.do("POST", "/v1/admin/job", `{"title":"Backend","description":"d","company_name":"Acme"}`, admin), http.StatusCreated, nil)
+	api.expect(api.do("GET", "/v1/jobs/apply?job_id=1", "", applicant), http.StatusOK, nil)
+	api.dispatch()
+	mailer.take()
+
+	start := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Minute)
+	api.expect(api.do("POST", "/v1/admin/application/1/interviews", interviewBody(start, 45, api.userID("admin@example.com")), admin), http.StatusCreated, nil)
+	api.dispatch()
+	sent := mailer.take()
+	if len(sent) != 1 || sent[0].Subject != "Interview scheduled: Backend at Acme" ||
+		!strings.Contains(sent[0].Text, "Video call: https://meet.example.com/abc") {
+		t.Fatalf("invite emails = %+v", sent)
+	}
+	if a := sent[0].Attachments; len(a) != 1 || a[0].ContentType != "text/calendar; charset=utf-8; method=REQUEST" ||
+		!strings.Contains(string(a[0].Data), "UID:interview-1@jobs.example.com") {
+		t.Errorf("attachments = %+v", a)
+	}
+
+	api.expect(api.do("POST", "/v1/me/interviews/1/reschedule", `{"note":"Can we do Friday?"}`, applicant), http.StatusOK, nil)
+	api.dispatch()
+	if sent := mailer.take(); len(sent) != 0 {
+		t.Errorf("emailed a reschedule request: %+v", sent)
+	}
+	inbox := notificationsResponse{}
+	api.expect(api.do("GET", "/v1/me/notifications", "", admin), http.StatusOK, &inbox)
+	if len(inbox.Notifications) < 2 || inbox.Notifications[0].Kind != notificationInterviewStatusChanged ||
+		!strings.HasSuffix(inbox.Notifications[0].Body, ": Can we do Friday?") ||
+		inbox.Notifications[1].Kind != notificationInterviewScheduled {
+		t.Errorf("interviewer inbox = %+v", inbox)
+	}
+
+	api.expect(api.do("POST", "/v1/admin/interview/1/cancel", "", admin), http.StatusOK, nil)
+	api.dispatch()
+	sent = mailer.take()
+	if len(sent) != 1 || sent[0].Subject != "Interview cancelled: Backend at Acme" || len(sent[0].Attachments) != 1 ||
+		!strings.Contains(string(sent[0].Attachments[0].Data), "METHOD:CANCEL") {
+		t.Fatalf("cancellation emails = %+v", sent)
+	}
+	inbox = notificationsResponse{}
+	api.expect(api.do("GET", "/v1/me/notifications", "", applicant), http.StatusOK, &inbox)
+	if inbox.Notifications[0].Title != "Interview cancelled for Backend" {
+		t.Errorf("applicant inbox = %+v", inbox)
+	}
+}
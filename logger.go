@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+type ctxKey string
+
+const logStateKey ctxKey = "logState"
+
+// logState is shared by every layer handling a request so that values
+// learned deep in the chain (like the authenticated user) show up in the
+// access log written by the outermost middleware.
+type logState struct {
+	requestID string
+	userID    int
+	// route is the matched pattern, recorded by the innermost middleware
+	// because the outer ones only see a copy of the request.
+	route  string
+	logger *slog.Logger
+}
+
+func newLogger(w io.Writer, level string) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level: parseLogLevel(level),
+	}))
+}
+
+func parseLogLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func withLogState(ctx context.Context, st *logState) context.Context {
+	return context.WithValue(ctx, logStateKey, st)
+}
+
+func logStateFrom(ctx context.Context) *logState {
+	st, _ := ctx.Value(logStateKey).(*logState)
+	return st
+}
+
+// requestLogger returns the logger for r carrying the request ID, the
+// user ID once authenticated, and the matched route.
+func requestLogger(r *http.Request) *slog.Logger {
+	logger := slog.Default()
+	if st := logStateFrom(r.Context()); st != nil {
+		logger = st.logger
+	}
+	if route := requestRoute(r); route != "" {
+		logger = logger.With("route", route)
+	}
+	return logger
+}
+
+// requestRoute returns the route pattern that matched r, even when r is
+// a copy made before the mux ran.
+func requestRoute(r *http.Request) string {
+	if r.Pattern != "" {
+		return r.Pattern
+	}
+	if st := logStateFrom(r.Context()); st != nil {
+		return st.route
+	}
+	return ""
+}
+
+func setLogRoute(ctx context.Context, route string) {
+	if st := logStateFrom(ctx); st != nil {
+		st.route = route
+	}
+}
+
+// setLogUser attaches the authenticated user to the request logger.
+func setLogUser(ctx context.Context, userID int) {
+	st := logStateFrom(ctx)
+	if st == nil {
+		return
+	}
+	st.userID = userID
+	st.logger = st.logger.With("user_id", userID)
+}
+
+// setLogTrace attaches the trace ID so log lines can be joined with the
+// request's spans.
+func setLogTrace(ctx context.Context, traceID string) {
+	st := logStateFrom(ctx)
+	if st == nil {
+		return
+	}
+	st.logger = st.logger.With("trace_id", traceID)
+}
@@ -1,62 +1,366 @@
 package main
 
 import (
-	"database/sql"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
 
-	"github.com/joho/godotenv"
-	_ "github.com/lib/pq"
+	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/Vikuuu/synlabs-assignment/internal/config"
 	"github.com/Vikuuu/synlabs-assignment/internal/database"
+	"github.com/Vikuuu/synlabs-assignment/internal/live"
+	"github.com/Vikuuu/synlabs-assignment/internal/mail"
+	"github.com/Vikuuu/synlabs-assignment/internal/metrics"
+	"github.com/Vikuuu/synlabs-assignment/internal/oidc"
+	"github.com/Vikuuu/synlabs-assignment/internal/outbox"
+	"github.com/Vikuuu/synlabs-assignment/internal/tracing"
+	"github.com/Vikuuu/synlabs-assignment/internal/webhook"
 )
 
 type apiConfig struct {
-	db     *database.Queries
+	db     database.Store
+	health healthChecker
 	secret string
+
+	// httpClient is used for every outbound call so they are traced.
+	httpClient *http.Client
+
+	metrics      *metrics.Metrics
+	workers      *background
+	shuttingDown atomic.Bool
+
+	oidc             *oidc.Client
+	oidcAdminDomains []string
+	// oidcCookiePath scopes the login session cookie to the directory of
+	// the callback, which may be under /v1 or not.
+	oidcCookiePath string
+
+	resumeParser config.ResumeParser
+
+	// events receives every outbox event in-process.
+	events *outbox.Handlers
+
+	// live receives the events streamed to clients.
+	live *live.Broker
+
+	mailer mail.Sender
+	// mailBaseURL is where links in emails point to.
+	mailBaseURL string
 }
 
-func (cfg *apiConfig) WithAuthAdmin(handler http.HandlerFunc) http.Handler {
+func (cfg *apiConfig) WithAuthAdmin(handler apiHandler) http.Handler {
 	return cfg.middlewareIsAdmin(handler)
 }
 
-func (cfg *apiConfig) WithAuthApplicant(handler http.HandlerFunc) http.Handler {
+func (cfg *apiConfig) WithAuthApplicant(handler apiHandler) http.Handler {
 	return cfg.middlewareIsApplicant(handler)
 }
 
+func (cfg *apiConfig) WithAuth(handler apiHandler) http.Handler {
+	return cfg.middlewareIsUser(handler)
+}
+
+func (cfg *apiConfig) routes(logger *slog.Logger) http.Handler {
+	mux := cfg.router()
+	return middlewareRequestID(logger, middlewareAccessLog(
+		cfg.middlewareMetrics(middlewareRecover(middlewareTracing(mux))),
+	))
+}
+
+// The unversioned API paths were the only ones before /v1 existed. They
+// keep working until legacySunset but announce their replacement.
+var (
+	legacyDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	legacySunset       = time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC)
+)
+
+// router registers every route. Each one must be described in
+// openapi.go as well.
+func (cfg *apiConfig) router() *router {
+	mux := newRouter()
+
+	mux.ServeMux.Handle("/", handlerNotFound(mux.ServeMux))
+	mux.HandleFunc("GET /{$}", handlerLandingPage)
+	mux.Handle("GET /openapi.json", apiHandler(handlerOpenAPI))
+	mux.HandleFunc("GET /docs", handlerDocs)
+	mux.Handle("GET /healthz", apiHandler(handlerHealthz))
+	mux.Handle("GET /readyz", apiHandler(cfg.handlerReadyz))
+	mux.Handle("GET /metrics", cfg.metrics.Handler())
+	mux.Handle("GET /unsubscribe", apiHandler(cfg.handlerUnsubscribePage))
+	mux.Handle("POST /unsubscribe", apiHandler(cfg.handlerUnsubscribe))
+
+	cfg.routesV1(mux.version("/v1"))
+
+	return mux
+}
+
+func (cfg *apiConfig) routesV1(v *version) {
+	v.Handle("POST /signup", apiHandler(cfg.handlerSignUp))
+	v.Handle("POST /login", apiHandler(cfg.handlerLogIn))
+	if cfg.oidc != nil {
+		v.Handle("GET /login/oidc", apiHandler(cfg.handlerOIDCLogin))
+		v.Handle("GET /login/oidc/callback", apiHandler(cfg.handlerOIDCCallback))
+	}
+	v.Handle("POST /uploadResume", cfg.WithAuthApplicant(cfg.handlerUploadResume))
+	v.Handle("POST /admin/job", cfg.WithAuthAdmin(cfg.handlerAddJob))
+	v.Handle("GET /admin/job/{job_id}", cfg.WithAuthAdmin(cfg.handlerJob))
+	v.Handle("GET /admin/applicants", cfg.WithAuthAdmin(cfg.handlerApplicants))
+	v.Handle("GET /admin/applicant/{applicant_id}", cfg.WithAuthAdmin(cfg.handlerApplicant))
+	v.Handle("GET /jobs", cfg.WithAuthApplicant(cfg.handlerViewJobs))
+	v.Handle("GET /jobs/apply", cfg.WithAuthApplicant(cfg.handlerApplyJob))
+	// Only the routes above predate /v1 and keep an unversioned alias.
+	v.legacyAliases(legacyDeprecatedAt, legacySunset)
+
+	v.Handle("POST /admin/job/{job_id}/close", cfg.WithAuthAdmin(cfg.handlerCloseJob))
+	v.Handle("PATCH /admin/application/{application_id}", cfg.WithAuthAdmin(cfg.handlerUpdateApplication))
+	v.Handle("POST /admin/webhooks", cfg.WithAuthAdmin(cfg.handlerAddWebhook))
+	v.Handle("GET /admin/webhooks", cfg.WithAuthAdmin(cfg.handlerWebhooks))
+	v.Handle("DELETE /admin/webhooks/{webhook_id}", cfg.WithAuthAdmin(cfg.handlerDeleteWebhook))
+	v.Handle("GET /admin/webhooks/{webhook_id}/deliveries", cfg.WithAuthAdmin(cfg.handlerWebhookDeliveries))
+	v.Handle("GET /admin/webhooks/dead-letters", cfg.WithAuthAdmin(cfg.handlerDeadLetters))
+	v.Handle("POST /admin/webhooks/deliveries/{delivery_id}/retry", cfg.WithAuthAdmin(cfg.handlerRetryDelivery))
+	v.Handle("GET /me/notification-preferences", cfg.WithAuth(cfg.handlerNotificationPreferences))
+	v.Handle("PUT /me/notification-preferences", cfg.WithAuth(cfg.handlerSetNotificationPreferences))
+	v.Handle("GET /me/notifications", cfg.WithAuth(cfg.handlerNotifications))
+	v.Handle("POST /me/notifications/read", cfg.WithAuth(cfg.handlerMarkNotificationsRead))
+	v.Handle("GET /admin/events", cfg.WithAuthAdmin(cfg.handlerAdminEvents))
+	v.Handle("GET /me/events", cfg.WithAuthApplicant(cfg.handlerApplicantEvents))
+	v.Handle("POST /admin/application/{application_id}/interviews", cfg.WithAuthAdmin(cfg.handlerCreateInterview))
+	v.Handle("GET /admin/application/{application_id}/interviews", cfg.WithAuthAdmin(cfg.handlerApplicationInterviews))
+	v.Handle("PUT /admin/interview/{interview_id}", cfg.WithAuthAdmin(cfg.handlerRescheduleInterview))
+	v.Handle("POST /admin/interview/{interview_id}/cancel", cfg.WithAuthAdmin(cfg.handlerCancelInterview))
+	v.Handle("GET /admin/interview/{interview_id}/invite.ics", cfg.WithAuthAdmin(cfg.handlerInterviewInvite))
+	v.Handle("GET /me/interviews", cfg.WithAuthApplicant(cfg.handlerApplicantInterviews))
+	v.Handle("GET /me/interviews/{interview_id}/invite.ics", cfg.WithAuthApplicant(cfg.handlerApplicantInterviewInvite))
+	v.Handle("POST /me/interviews/{interview_id}/confirm", cfg.WithAuthApplicant(cfg.handlerConfirmInterview))
+	v.Handle("POST /me/interviews/{interview_id}/reschedule", cfg.WithAuthApplicant(cfg.handlerRequestReschedule))
+	v.Handle("PUT /admin/job/{job_id}/scorecard", cfg.WithAuthAdmin(cfg.handlerSetScorecard))
+	v.Handle("GET /admin/job/{job_id}/scorecard", cfg.WithAuthAdmin(cfg.handlerScorecard))
+	v.Handle("PUT /admin/application/{application_id}/feedback", cfg.WithAuthAdmin(cfg.handlerSaveFeedback))
+	v.Handle("GET /admin/application/{application_id}/feedback", cfg.WithAuthAdmin(cfg.handlerApplicationFeedback))
+}
+
 func main() {
-	godotenv.Load()
-	port := os.Getenv("PORT")
+	cfg, err := config.Load(os.Getenv("CONFIG_FILE"))
+
+	// Route the standard logger through slog as well so nothing is
+	// written as free text.
+	logger := newLogger(os.Stdout, cfg.LogLevel)
+	slog.SetDefault(logger)
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			if err := cmd(context.Background(), cfg, os.Args[2:], os.Stdout); err != nil {
+				log.Fatalf("%s: %s", os.Args[1], err)
+			}
+			return
+		}
+	}
+
+	flag.BoolVar(&cfg.MigrateOnStart, "migrate-on-start", cfg.MigrateOnStart, "apply pending migrations before serving")
+	flag.Parse()
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("%s", err)
+	}
+	logger.Info("effective configuration", "config", cfg.Redacted())
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Enabled:     cfg.Tracing.Enabled,
+		ServiceName: "synlabs-assignment",
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		log.Fatalf("tracing cannot be set up: %s", err)
+	}
 
-	db, err := sql.Open("postgres", os.Getenv("DB_URL"))
+	pool, err := openPool(context.Background(), cfg)
 	if err != nil {
 		log.Fatalf("connection cannot be made to db: %s", err)
 	}
-	defer db.Close()
+	defer pool.Close()
+
+	if cfg.MigrateOnStart {
+		if err := migrateOnStart(context.Background(), pool, logger); err != nil {
+			log.Fatalf("migrations failed: %s", err)
+		}
+	}
 
-	config := apiConfig{
-		db:     database.New(db),
-		secret: os.Getenv("SECRET"),
+	config := newAPIConfig(cfg, pool)
+	config.workers.Go(webhook.NewDispatcher(config.db, config.httpClient, logger, webhook.Config{
+		MaxAttempts:  cfg.Webhooks.MaxAttempts,
+		Backoff:      cfg.Webhooks.Backoff,
+		MaxBackoff:   cfg.Webhooks.MaxBackoff,
+		Timeout:      cfg.Webhooks.Timeout,
+		PollInterval: cfg.Webhooks.PollInterval,
+	}).Run)
+	config.workers.Go(func(ctx context.Context) {
+		live.Listen(ctx, pool, config.live, logger)
+	})
+	config.subscribeEmails(config.events)
+	config.subscribeInbox(config.events)
+	config.workers.Go(func(ctx context.Context) {
+		config.runDigests(ctx, cfg.Mail.DigestInterval, logger)
+	})
+	sinks, err := outboxSinks(cfg.Outbox, config.events)
+	if err != nil {
+		log.Fatalf("outbox sinks cannot be set up: %s", err)
 	}
+	config.workers.Go(outbox.NewDispatcher(config.db, sinks, logger, outbox.Config{
+		Backoff:      cfg.Outbox.Backoff,
+		MaxBackoff:   cfg.Outbox.MaxBackoff,
+		Timeout:      cfg.Outbox.Timeout,
+		PollInterval: cfg.Outbox.PollInterval,
+		Retention:    cfg.Outbox.Retention,
+	}).Run)
 
-	mux := http.NewServeMux()
 	srv := &http.Server{
-		Addr:    ":" + port,
-		Handler: mux,
-	}
-
-	mux.HandleFunc("GET /", handlerLandingPage)
-	mux.HandleFunc("POST /signup", config.handlerSignUp)
-	mux.HandleFunc("POST /login", config.handlerLogIn)
-	mux.HandleFunc("POST /uploadResume", config.handlerUploadResume)
-	mux.Handle("POST /admin/job", config.WithAuthAdmin(config.handlerAddJob))
-	mux.Handle("GET /admin/job/{job_id}", config.WithAuthAdmin(config.handlerJob))
-	mux.Handle("GET /admin/applicants", config.WithAuthAdmin(config.handlerApplicants))
-	mux.Handle("GET /admin/applicant/{applicant_id}", config.WithAuthAdmin(config.handlerApplicant))
-	mux.Handle("GET /jobs", config.WithAuthApplicant(config.handlerViewJobs))
-	mux.Handle("GET /jobs/apply", config.WithAuthApplicant(config.handlerApplyJob))
-
-	log.Printf("Serving on Port: %s\n", port)
-	log.Fatal(srv.ListenAndServe())
+		Addr:              ":" + cfg.Port,
+		Handler:           config.routes(logger),
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+	// Event streams never finish on their own.
+	srv.RegisterOnShutdown(config.live.Close)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("server starting", "port", cfg.Port)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("server failed: %s", err)
+		}
+	case <-ctx.Done():
+	}
+
+	// Fail readiness first so the orchestrator stops routing to us, then
+	// drain in-flight requests and background workers.
+	logger.Info("shutting down")
+	config.shuttingDown.Store(true)
+	time.Sleep(cfg.Server.ShutdownDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("error draining requests", "error", err)
+	}
+	if err := config.workers.Stop(shutdownCtx); err != nil {
+		logger.Error("error stopping background workers", "error", err)
+	}
+	// The outbox dispatcher has stopped publishing, so its sinks can go.
+	for _, sink := range sinks {
+		if c, ok := sink.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				logger.Error("error closing outbox sink", "sink", sink.Name(), "error", err)
+			}
+		}
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("error flushing traces", "error", err)
+	}
+	logger.Info("server stopped")
+}
+
+// openPool creates the connection pool. Connections are opened lazily,
+// so an unreachable database surfaces on the first query.
+func openPool(ctx context.Context, cfg config.Config) (*pgxpool.Pool, error) {
+	poolCfg, err := pgxpool.ParseConfig(string(cfg.DBURL))
+	if err != nil {
+		return nil, err
+	}
+	poolCfg.MaxConns = int32(cfg.DB.MaxConns)
+	poolCfg.MinConns = int32(cfg.DB.MinConns)
+	poolCfg.MaxConnLifetime = cfg.DB.MaxConnLifetime
+	poolCfg.MaxConnIdleTime = cfg.DB.MaxConnIdleTime
+	poolCfg.HealthCheckPeriod = cfg.DB.HealthCheckPeriod
+	return pgxpool.NewWithConfig(ctx, poolCfg)
+}
+
+// newAPIConfig wires the dependencies shared by the server and the admin
+// commands.
+func newAPIConfig(cfg config.Config, pool *pgxpool.Pool) *apiConfig {
+	m := metrics.New(pool)
+	instrument := func(db database.DBTX) database.DBTX {
+		db = database.WithTimeouts(db, cfg.DB.StatementTimeout, cfg.DB.QueryTimeouts)
+		return tracing.InstrumentDB(m.InstrumentDB(db))
+	}
+	apiCfg := &apiConfig{
+		db:     database.NewStore(pool, instrument),
+		health: poolHealth{pool: pool},
+		secret: cfg.Secret.Reveal(),
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: tracing.Transport(nil),
+		},
+		metrics:      m,
+		workers:      newBackground(),
+		resumeParser: cfg.ResumeParser,
+		events:       outbox.NewHandlers(),
+		live:         live.NewBroker(),
+		mailer:       mail.Log{Logger: slog.Default()},
+		mailBaseURL:  cfg.Mail.BaseURL,
+	}
+	if cfg.Mail.SMTPHost != "" {
+		apiCfg.mailer = mail.NewSMTP(cfg.Mail.SMTPHost, cfg.Mail.SMTPPort,
+			cfg.Mail.SMTPUsername, cfg.Mail.SMTPPassword.Reveal(), cfg.Mail.From)
+	}
+
+	if cfg.OIDC.Enabled() {
+		apiCfg.oidc = oidc.NewClient(oidc.Config{
+			Issuer:       cfg.OIDC.Issuer,
+			ClientID:     cfg.OIDC.ClientID,
+			ClientSecret: cfg.OIDC.ClientSecret.Reveal(),
+			RedirectURL:  cfg.OIDC.RedirectURL,
+		}, apiCfg.httpClient)
+		apiCfg.oidcAdminDomains = cfg.OIDC.AdminDomains
+		apiCfg.oidcCookiePath = oidcCookiePath(cfg.OIDC.RedirectURL)
+	}
+	return apiCfg
+}
+
+// outboxSinks returns the in-process handlers followed by the sinks
+// enabled in cfg.
+func outboxSinks(cfg config.Outbox, events *outbox.Handlers) ([]outbox.Sink, error) {
+	sinks := []outbox.Sink{events}
+	for _, name := range cfg.Sinks {
+		switch name {
+		case "stdout":
+			sinks = append(sinks, outbox.NewWriter(os.Stdout))
+		case "nats":
+			n, err := outbox.NewNATS(cfg.NATSURL, cfg.NATSSubject, cfg.NATSToken.Reveal())
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, n)
+		default:
+			return nil, fmt.Errorf("unknown sink %q", name)
+		}
+	}
+	return sinks, nil
 }
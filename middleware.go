@@ -2,44 +2,64 @@ package main
 
 import (
 	"context"
-	"log"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/Vikuuu/synlabs-assignment/internal/auth"
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+	"github.com/Vikuuu/synlabs-assignment/internal/tracing"
 )
 
-func (cfg *apiConfig) middlewareIsAdmin(handler http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		jwt, err := auth.GetBearerToken(r.Header)
-		if err != nil {
-			log.Fatalf("error getting jwtToken: %s", err)
-			respondWithError(w, "Unauthorized", http.StatusUnauthorized)
-			return
-		}
+// authenticate resolves the bearer token on r to a user.
+func (cfg *apiConfig) authenticate(r *http.Request) (int, database.UserType, error) {
+	jwt, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		return 0, "", newAPIError(codeUnauthorized, "A valid bearer token is required", err)
+	}
 
-		userID, err := auth.ValidateJWT(jwt, cfg.secret)
-		if err != nil {
-			log.Fatalf("error validating token: %s", err)
-			respondWithError(w, "Unauthorized", http.StatusUnauthorized)
-			return
+	userID, err := auth.ValidateJWT(jwt, cfg.secret)
+	if err != nil {
+		return 0, "", newAPIError(codeUnauthorized, "A valid bearer token is required", err)
+	}
+
+	userType, err := cfg.db.GetUserFromID(r.Context(), int32(userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, "", newAPIError(codeUnauthorized, "A valid bearer token is required", err)
 		}
+		return 0, "", fmt.Errorf("getting user: %w", err)
+	}
 
-		userType, err := cfg.db.GetUserFromID(context.Background(), int32(userID))
+	return userID, userType, nil
+}
+
+func (cfg *apiConfig) middlewareIsAdmin(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, userType, err := cfg.authenticate(r)
 		if err != nil {
-			log.Fatalf("error getting user: %s", err)
-			w.WriteHeader(http.StatusInternalServerError)
+			writeError(w, r, err)
 			return
 		}
 
 		if userType == "applicant" {
-			respondWithError(
-				w,
+			writeError(w, r, newAPIError(
+				codeForbidden,
 				"You are not authorized to access this endpoint",
-				http.StatusUnauthorized,
-			)
+				nil,
+			))
 			return
 		}
 
+		setLogUser(r.Context(), userID)
 		ctx := context.WithValue(r.Context(), "userID", userID)
 		handler.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -47,38 +67,199 @@ func (cfg *apiConfig) middlewareIsAdmin(handler http.Handler) http.Handler {
 
 func (cfg *apiConfig) middlewareIsApplicant(handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		jwt, err := auth.GetBearerToken(r.Header)
+		userID, userType, err := cfg.authenticate(r)
 		if err != nil {
-			log.Fatalf("error getting jwtToken: %s", err)
-			respondWithError(w, "Unauthorized", http.StatusUnauthorized)
+			writeError(w, r, err)
 			return
 		}
 
-		userID, err := auth.ValidateJWT(jwt, cfg.secret)
-		if err != nil {
-			log.Fatalf("error validating token: %s", err)
-			respondWithError(w, "Unauthorized", http.StatusUnauthorized)
+		if userType == "admin" {
+			writeError(w, r, newAPIError(
+				codeForbidden,
+				"You are not authorized to access this endpoint",
+				nil,
+			))
 			return
 		}
 
-		userType, err := cfg.db.GetUserFromID(context.Background(), int32(userID))
+		setLogUser(r.Context(), userID)
+		ctx := context.WithValue(r.Context(), "userID", userID)
+		handler.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// middlewareIsUser lets in any signed-in user, admin or applicant.
+func (cfg *apiConfig) middlewareIsUser(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, _, err := cfg.authenticate(r)
 		if err != nil {
-			log.Fatalf("error getting user: %s", err)
-			w.WriteHeader(http.StatusInternalServerError)
+			writeError(w, r, err)
 			return
 		}
 
-		if userType == "admin" {
-			respondWithError(
-				w,
-				"You are not authorized to access this endpoint",
-				http.StatusUnauthorized,
+		setLogUser(r.Context(), userID)
+		ctx := context.WithValue(r.Context(), "userID", userID)
+		handler.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// middlewareRecover turns a panicking handler into a 500 response so one
+// bad request cannot take the process down.
+func middlewareRecover(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w}
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+
+			requestLogger(r).Error(
+				"panic serving request",
+				"panic", fmt.Sprint(v),
+				"stack", string(debug.Stack()),
 			)
-			return
+			if rec.status == 0 {
+				respondWithProblem(w, r, newAPIError(
+					codeInternal,
+					"The server could not complete the request",
+					nil,
+				))
+			}
+		}()
+
+		handler.ServeHTTP(rec, r)
+	})
+}
+
+const requestIDHeader = "X-Request-ID"
+
+// middlewareRequestID propagates the caller's X-Request-ID, or generates
+// one, and seeds the request logger with it.
+func middlewareRequestID(logger *slog.Logger, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
 		}
+		w.Header().Set(requestIDHeader, requestID)
 
-		log.Println(userID)
-		ctx := context.WithValue(r.Context(), "userID", userID)
-		handler.ServeHTTP(w, r.WithContext(ctx))
+		st := &logState{
+			requestID: requestID,
+			logger:    logger.With("request_id", requestID),
+		}
+		handler.ServeHTTP(w, r.WithContext(withLogState(r.Context(), st)))
 	})
 }
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (rec *statusRecorder) WriteHeader(code int) {
+	if rec.status == 0 {
+		rec.status = code
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += n
+	return n, err
+}
+
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// middlewareAccessLog writes one log line per request once the response
+// is complete. It must run inside middlewareRequestID.
+func middlewareAccessLog(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+
+		handler.ServeHTTP(rec, r)
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		switch {
+		case rec.status >= 500:
+			level = slog.LevelError
+		case rec.status >= 400:
+			level = slog.LevelWarn
+		}
+
+		requestLogger(r).LogAttrs(
+			r.Context(),
+			level,
+			"request completed",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Int("bytes", rec.bytes),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("remote_addr", r.RemoteAddr),
+			slog.String("user_agent", r.UserAgent()),
+		)
+	})
+}
+
+// middlewareMetrics records request count and latency per route pattern.
+// The pattern is only known once the mux has matched, so it is read after
+// the handler returns.
+func (cfg *apiConfig) middlewareMetrics(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+
+		handler.ServeHTTP(rec, r)
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		cfg.metrics.ObserveHTTP(requestRoute(r), r.Method, rec.status, time.Since(start))
+	})
+}
+
+// middlewareTracing starts the server span for the request and adds its
+// trace ID to the request logger. It must wrap the mux directly, and also
+// hands the matched route back to the outer middleware.
+func middlewareTracing(mux http.Handler) http.Handler {
+	return tracing.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+			setLogTrace(r.Context(), sc.TraceID().String())
+		}
+		mux.ServeHTTP(w, r)
+		setLogRoute(r.Context(), r.Pattern)
+	}))
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/config"
+	"github.com/Vikuuu/synlabs-assignment/internal/migrate"
+)
+
+const migrateUsage = `usage: synlabs-assignment migrate <command>
+
+commands:
+  up              apply all pending migrations
+  down            roll back the last applied migration
+  status          list migrations and whether they are applied
+  create [-dir d] <name>
+                  write a new empty migration into d (default sql/schema)
+`
+
+// runMigrate implements the migrate subcommand. up, down and status use
+// the migrations embedded in the binary; create writes to the source tree.
+func runMigrate(ctx context.Context, cfg config.Config, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(out, migrateUsage)
+		return errors.New("missing migrate command")
+	}
+
+	if args[0] == "create" {
+		fs := flag.NewFlagSet("migrate create", flag.ContinueOnError)
+		dir := fs.String("dir", "sql/schema", "directory holding the migrations")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return errors.New("migrate create needs exactly one name")
+		}
+		path, err := migrate.Create(*dir, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "created %s\n", path)
+		return nil
+	}
+
+	if err := cfg.DBURL.Validate(); err != nil {
+		return fmt.Errorf("db_url: %w", err)
+	}
+	pool, err := openPool(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	db := stdlib.OpenDBFromPool(pool)
+	defer db.Close()
+
+	m, err := migrate.New(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		results, err := m.Up(ctx)
+		for _, res := range results {
+			fmt.Fprintln(out, res)
+		}
+		if err == nil && len(results) == 0 {
+			fmt.Fprintln(out, "no pending migrations")
+		}
+		return err
+	case "down":
+		res, err := m.Down(ctx)
+		if res != nil {
+			fmt.Fprintln(out, res)
+		}
+		return err
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, st := range statuses {
+			applied := "pending"
+			if !st.AppliedAt.IsZero() {
+				applied = st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(out, "%-20s %s\n", applied, st.Source.Path)
+		}
+		return nil
+	default:
+		fmt.Fprint(out, migrateUsage)
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+}
+
+// migrateOnStart applies pending migrations before the server accepts
+// traffic. Other replicas block on the advisory lock until it is done.
+func migrateOnStart(ctx context.Context, pool *pgxpool.Pool, logger *slog.Logger) error {
+	db := stdlib.OpenDBFromPool(pool)
+	defer db.Close()
+
+	m, err := migrate.New(db)
+	if err != nil {
+		return err
+	}
+	results, err := m.Up(ctx)
+	for _, res := range results {
+		logger.Info("applied migration", "migration", res.Source.Path, "duration", res.Duration)
+	}
+	return err
+}
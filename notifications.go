@@ -0,0 +1,413 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"log/slog"
+	"net/url"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/auth"
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+	"github.com/Vikuuu/synlabs-assignment/internal/mail"
+	"github.com/Vikuuu/synlabs-assignment/internal/outbox"
+)
+
+// Email lists users can unsubscribe from. They match the fields of
+// notificationPreferences.
+const (
+	listApplicationUpdates = "application_updates"
+	listApplicantDigest    = "applicant_digest"
+)
+
+var listReasons = map[string]string{
+	listApplicationUpdates: "You get this email because you applied to a job on Synlabs.",
+	listApplicantDigest:    "You get this digest because you post jobs on Synlabs.",
+}
+
+// interviewReason is the footer of the interview emails, which are on no
+// list: applicants get them whatever their preferences.
+const interviewReason = "You get this email because you have an interview through Synlabs."
+
+//go:embed templates/email/*.tmpl
+var emailFS embed.FS
+
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// emailTemplates holds every email, each with a subject and a text body
+// in <name>.txt.tmpl and an HTML body in <name>.html.tmpl, wrapped in
+// the layouts.
+var emailTemplates = func() map[string]emailTemplate {
+	out := map[string]emailTemplate{}
+	for _, name := range []string{"application_received", "status_changed", "applicant_digest", "interview_invite", "interview_cancelled"} {
+		out[name] = emailTemplate{
+			text: texttemplate.Must(texttemplate.ParseFS(emailFS, "templates/email/layout.txt.tmpl", "templates/email/"+name+".txt.tmpl")),
+			html: htmltemplate.Must(htmltemplate.ParseFS(emailFS, "templates/email/layout.html.tmpl", "templates/email/"+name+".html.tmpl")),
+		}
+	}
+	return out
+}()
+
+// emailFooter is embedded in the data of every email.
+type emailFooter struct {
+	Reason         string
+	UnsubscribeURL string
+}
+
+func (f *emailFooter) footer() *emailFooter { return f }
+
+type applicationReceivedEmail struct {
+	emailFooter
+	Name        string
+	JobTitle    string
+	CompanyName string
+	AppliedAt   time.Time
+}
+
+type statusChangedEmail struct {
+	emailFooter
+	Name           string
+	JobTitle       string
+	CompanyName    string
+	Status         database.ApplicationStatus
+	PreviousStatus database.ApplicationStatus
+}
+
+// interviewEmail is the data of interview_invite and
+// interview_cancelled.
+type interviewEmail struct {
+	emailFooter
+	Name        string
+	JobTitle    string
+	CompanyName string
+	StartsAt    time.Time
+	EndsAt      time.Time
+	Location    string
+	VideoURL    string
+	Rescheduled bool
+}
+
+type applicantDigestEmail struct {
+	emailFooter
+	Name         string
+	Since        time.Time
+	Applications []database.ListApplicationsForPosterRow
+}
+
+func (cfg *apiConfig) unsubscribeURL(userID int32, list string) string {
+	token := auth.MakeUnsubscribeToken(userID, list, cfg.secret)
+	return strings.TrimSuffix(cfg.mailBaseURL, "/") + "/unsubscribe?token=" + url.QueryEscape(token)
+}
+
+// renderEmail renders the named email to a user of one list, with a
+// link to unsubscribe from it.
+func (cfg *apiConfig) renderEmail(name string, userID int32, to, list string, data interface{ footer() *emailFooter }) (mail.Message, error) {
+	unsubscribe := cfg.unsubscribeURL(userID, list)
+	*data.footer() = emailFooter{Reason: listReasons[list], UnsubscribeURL: unsubscribe}
+	msg, err := renderTemplate(name, to, data)
+	if err != nil {
+		return mail.Message{}, err
+	}
+	msg.Headers = map[string]string{
+		// RFC 8058 one-click unsubscribe.
+		"List-Unsubscribe":      "<" + unsubscribe + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+	return msg, nil
+}
+
+// renderTransactionalEmail renders the named email, which is on no list,
+// so it has no unsubscribe link. reason tells the user why they get it.
+func renderTransactionalEmail(name, to, reason string, data interface{ footer() *emailFooter }) (mail.Message, error) {
+	*data.footer() = emailFooter{Reason: reason}
+	return renderTemplate(name, to, data)
+}
+
+// renderTemplate renders the subject and bodies of the named email.
+func renderTemplate(name, to string, data interface{ footer() *emailFooter }) (mail.Message, error) {
+	tmpl := emailTemplates[name]
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return mail.Message{}, fmt.Errorf("rendering %s subject: %w", name, err)
+	}
+	if err := tmpl.text.ExecuteTemplate(&text, "layout.txt.tmpl", data); err != nil {
+		return mail.Message{}, fmt.Errorf("rendering %s text: %w", name, err)
+	}
+	if err := tmpl.html.ExecuteTemplate(&html, "layout.html.tmpl", data); err != nil {
+		return mail.Message{}, fmt.Errorf("rendering %s html: %w", name, err)
+	}
+	return mail.Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// subscribeEmails sends the emails that follow from outbox events.
+func (cfg *apiConfig) subscribeEmails(events *outbox.Handlers) {
+	events.Subscribe(eventApplicationCreated, cfg.emailApplicationReceived)
+	events.Subscribe(eventApplicationStatusChanged, cfg.emailStatusChanged)
+	events.Subscribe(eventInterviewScheduled, cfg.emailInterviewScheduled)
+	events.Subscribe(eventInterviewStatusChanged, cfg.emailInterviewCancelled)
+}
+
+// decodeEventData decodes the data of an event envelope.
+func decodeEventData(payload []byte, data interface{}) error {
+	envelope := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return fmt.Errorf("decoding event: %w", err)
+	}
+	if err := json.Unmarshal(envelope.Data, data); err != nil {
+		return fmt.Errorf("decoding event data: %w", err)
+	}
+	return nil
+}
+
+// applicationEmailContext loads the applicant and job of an application
+// event. ok is false when the applicant no longer exists or, unless the
+// email is transactional, does not want application updates.
+func (cfg *apiConfig) applicationEmailContext(ctx context.Context, data applicationEvent, transactional bool) (user database.GetNotificationRecipientRow, job database.GetJobRow, ok bool, err error) {
+	user, err = cfg.db.GetNotificationRecipient(ctx, data.ApplicantID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return user, job, false, nil
+	}
+	if err != nil {
+		return user, job, false, fmt.Errorf("getting recipient: %w", err)
+	}
+	if !transactional && !user.ApplicationUpdates {
+		return user, job, false, nil
+	}
+	job, err = cfg.db.GetJob(ctx, data.JobID)
+	if err != nil {
+		return user, job, false, fmt.Errorf("getting job: %w", err)
+	}
+	return user, job, true, nil
+}
+
+func (cfg *apiConfig) emailApplicationReceived(ctx context.Context, e outbox.Event) error {
+	data := applicationEvent{}
+	if err := decodeEventData(e.Payload, &data); err != nil {
+		return err
+	}
+	user, job, ok, err := cfg.applicationEmailContext(ctx, data, false)
+	if err != nil || !ok {
+		return err
+	}
+	msg, err := cfg.renderEmail("application_received", user.ID, user.Email, listApplicationUpdates, &applicationReceivedEmail{
+		Name:        user.Name,
+		JobTitle:    job.Title,
+		CompanyName: job.CompanyName,
+		AppliedAt:   data.AppliedAt,
+	})
+	if err != nil {
+		return err
+	}
+	return cfg.emailOnce(ctx, user.ID, "application_received", e.ID, msg)
+}
+
+func (cfg *apiConfig) emailStatusChanged(ctx context.Context, e outbox.Event) error {
+	data := applicationEvent{}
+	if err := decodeEventData(e.Payload, &data); err != nil {
+		return err
+	}
+	user, job, ok, err := cfg.applicationEmailContext(ctx, data, false)
+	if err != nil || !ok {
+		return err
+	}
+	msg, err := cfg.renderEmail("status_changed", user.ID, user.Email, listApplicationUpdates, &statusChangedEmail{
+		Name:           user.Name,
+		JobTitle:       job.Title,
+		CompanyName:    job.CompanyName,
+		Status:         data.Status,
+		PreviousStatus: data.PreviousStatus,
+	})
+	if err != nil {
+		return err
+	}
+	return cfg.emailOnce(ctx, user.ID, "status_changed", e.ID, msg)
+}
+
+// emailInterviewScheduled sends the applicant the invite to a new or
+// moved interview.
+func (cfg *apiConfig) emailInterviewScheduled(ctx context.Context, e outbox.Event) error {
+	data := interviewEvent{}
+	if err := decodeEventData(e.Payload, &data); err != nil {
+		return err
+	}
+	return cfg.emailInterview(ctx, e, data, "interview_invite")
+}
+
+func (cfg *apiConfig) emailInterviewCancelled(ctx context.Context, e outbox.Event) error {
+	data := interviewEvent{}
+	if err := decodeEventData(e.Payload, &data); err != nil {
+		return err
+	}
+	if data.Status != database.InterviewStatusCancelled {
+		return nil
+	}
+	return cfg.emailInterview(ctx, e, data, "interview_cancelled")
+}
+
+// emailInterview sends the named email about an interview with its
+// current invite attached, whatever the applicant's preferences. An
+// invite to an interview cancelled since is not sent; the cancellation
+// has an email of its own.
+func (cfg *apiConfig) emailInterview(ctx context.Context, e outbox.Event, data interviewEvent, name string) error {
+	row, err := cfg.db.GetInterview(ctx, data.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("getting interview: %w", err)
+	}
+	if name == "interview_invite" && row.Interview.Status == database.InterviewStatusCancelled {
+		return nil
+	}
+	user, job, ok, err := cfg.applicationEmailContext(ctx, applicationEvent{ApplicantID: data.ApplicantID, JobID: data.JobID}, true)
+	if err != nil || !ok {
+		return err
+	}
+
+	method, invite, err := cfg.interviewInvite(ctx, row)
+	if err != nil {
+		return err
+	}
+	msg, err := renderTransactionalEmail(name, user.Email, interviewReason, &interviewEmail{
+		Name:        user.Name,
+		JobTitle:    job.Title,
+		CompanyName: job.CompanyName,
+		StartsAt:    row.Interview.StartsAt,
+		EndsAt:      row.Interview.EndsAt,
+		Location:    row.Interview.Location,
+		VideoURL:    row.Interview.VideoUrl,
+		Rescheduled: data.PreviousStatus != "",
+	})
+	if err != nil {
+		return err
+	}
+	msg.Attachments = []mail.Attachment{{
+		Filename:    "invite.ics",
+		ContentType: "text/calendar; charset=utf-8; method=" + method,
+		Data:        invite,
+	}}
+	return cfg.emailOnce(ctx, user.ID, name, e.ID, msg)
+}
+
+// emailOnce sends msg unless it was already sent for the event, since
+// the outbox may hand out an event more than once.
+func (cfg *apiConfig) emailOnce(ctx context.Context, userID int32, kind, eventID string, msg mail.Message) error {
+	sent, err := cfg.db.EmailSent(ctx, database.EmailSentParams{UserID: userID, Kind: kind, EventID: eventID})
+	if err != nil {
+		return fmt.Errorf("checking sent emails: %w", err)
+	}
+	if sent {
+		return nil
+	}
+	if err := cfg.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("sending %s email: %w", kind, err)
+	}
+	return cfg.db.RecordSentEmail(ctx, database.RecordSentEmailParams{
+		UserID:  userID,
+		Kind:    kind,
+		EventID: eventID,
+		SentAt:  time.Now(),
+	})
+}
+
+// runDigests sends the new applicants digest to every admin once per
+// interval until ctx is cancelled.
+func (cfg *apiConfig) runDigests(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	t := time.NewTicker(min(interval, 10*time.Minute))
+	defer t.Stop()
+	for {
+		if err := cfg.sendDigests(ctx, time.Now(), interval); err != nil && ctx.Err() == nil {
+			logger.Error("sending applicant digests", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// sendDigests emails each admin due for a digest the applications to
+// their jobs since their last one. Admins without new applications get
+// no email but are still marked as done.
+func (cfg *apiConfig) sendDigests(ctx context.Context, now time.Time, interval time.Duration) error {
+	dueBefore := pgtype.Timestamp{Time: now.Add(-interval), Valid: true}
+	recipients, err := cfg.db.ListDigestRecipients(ctx, dueBefore)
+	if err != nil {
+		return fmt.Errorf("listing digest recipients: %w", err)
+	}
+
+	var errs []error
+	for _, r := range recipients {
+		// Claiming first keeps two servers from sending the same digest.
+		n, err := cfg.db.ClaimDigest(ctx, database.ClaimDigestParams{
+			UserID:       r.ID,
+			DigestSentAt: pgtype.Timestamp{Time: now, Valid: true},
+			DueBefore:    dueBefore,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("claiming digest of user %d: %w", r.ID, err))
+			continue
+		}
+		if n == 0 {
+			continue
+		}
+		if err := cfg.sendDigest(ctx, r, now, interval); err != nil {
+			errs = append(errs, fmt.Errorf("digest of user %d: %w", r.ID, err))
+			// Give the claim back so the next run covers these applications.
+			restore := database.SetDigestSentAtParams{UserID: r.ID, DigestSentAt: r.DigestSentAt}
+			if err := cfg.db.SetDigestSentAt(ctx, restore); err != nil {
+				errs = append(errs, fmt.Errorf("restoring digest of user %d: %w", r.ID, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (cfg *apiConfig) sendDigest(ctx context.Context, r database.ListDigestRecipientsRow, now time.Time, interval time.Duration) error {
+	since := now.Add(-interval)
+	if r.DigestSentAt.Valid {
+		since = r.DigestSentAt.Time
+	}
+	apps, err := cfg.db.ListApplicationsForPoster(ctx, database.ListApplicationsForPosterParams{
+		PostedBy: r.ID,
+		Since:    since,
+		Until:    now,
+	})
+	if err != nil {
+		return fmt.Errorf("listing applications: %w", err)
+	}
+	if len(apps) == 0 {
+		return nil
+	}
+
+	msg, err := cfg.renderEmail("applicant_digest", r.ID, r.Email, listApplicantDigest, &applicantDigestEmail{
+		Name:         r.Name,
+		Since:        since,
+		Applications: apps,
+	})
+	if err != nil {
+		return err
+	}
+	return cfg.mailer.Send(ctx, msg)
+}
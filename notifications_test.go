@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/mail"
+	"github.com/Vikuuu/synlabs-assignment/internal/outbox"
+)
+
+// fakeMailer records the emails sent.
+type fakeMailer struct {
+	mu   sync.Mutex
+	sent []mail.Message
+}
+
+func (m *fakeMailer) Send(ctx context.Context, msg mail.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// take returns the emails sent since the last call.
+func (m *fakeMailer) take() []mail.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sent := m.sent
+	m.sent = nil
+	return sent
+}
+
+func newMailTestAPI(t *testing.T) (*testAPI, *fakeMailer) {
+	mailer := &fakeMailer{}
+	api := newTestAPI(t, func(cfg *apiConfig) {
+		cfg.mailer = mailer
+		cfg.mailBaseURL = "https://jobs.example.com/"
+	})
+	return api, mailer
+}
+
+// dispatch hands the pending outbox events to the email and inbox
+// handlers.
+func (a *testAPI) dispatch() {
+	a.t.Helper()
+	handlers := outbox.NewHandlers()
+	a.cfg.subscribeEmails(handlers)
+	a.cfg.subscribeInbox(handlers)
+	d := outbox.NewDispatcher(a.store, []outbox.Sink{handlers}, slog.New(slog.NewTextHandler(io.Discard, nil)), outbox.Config{
+		Backoff:      time.Minute,
+		MaxBackoff:   time.Hour,
+		Timeout:      5 * time.Second,
+		PollInterval: time.Second,
+		Retention:    time.Hour,
+	})
+	if _, err := d.DispatchDue(context.Background()); err != nil {
+		a.t.Fatal(err)
+	}
+}
+
+func TestApplicationEmails(t *testing.T) {
+	api, mailer := newMailTestAPI(t)
+	admin := api.signup("admin@example.com", "admin")
+	applicant := api.signup("applicant@example.com", "applicant")
+	api.expect(api.do("POST", "/v1/admin/job", `{"title":"Backend","description":"d","company_name":"Acme"}`, admin), http.StatusCreated, nil)
+	api.expect(api.do("GET", "/v1/jobs/apply?job_id=1", "", applicant), http.StatusOK, nil)
+	api.expect(api.do("PATCH", "/v1/admin/application/1", `{"status":"interviewing"}`, admin), http.StatusOK, nil)
+	api.dispatch()
+
+	sent := mailer.take()
+	if len(sent) != 2 {
+		t.Fatalf("sent %d emails, want 2", len(sent))
+	}
+	received, changed := sent[0], sent[1]
+	if received.To != "applicant@example.com" || received.Subject != "We received your application for Backend" ||
+		!strings.Contains(received.Text, "Thanks for applying to Backend at Acme") ||
+		!strings.Contains(received.HTML, "<strong>Backend</strong>") {
+		t.Errorf("application received email = %+v", received)
+	}
+	if changed.Subject != "Your application for Backend is now interviewing" ||
+		!strings.Contains(changed.Text, "from applied to interviewing") {
+		t.Errorf("status changed email = %+v", changed)
+	}
+	unsubscribe := strings.Trim(changed.Headers["List-Unsubscribe"], "<>")
+	if !strings.HasPrefix(unsubscribe, "https://jobs.example.com/unsubscribe?token=") ||
+		!strings.Contains(changed.Text, "Unsubscribe: "+unsubscribe) {
+		t.Fatalf("unsubscribe link %q, text %q", unsubscribe, changed.Text)
+	}
+
+	// An event handed out again does not send the email twice.
+	for _, e := range api.store.Outbox() {
+		if e.EventType != eventApplicationCreated {
+			continue
+		}
+		if err := api.cfg.emailApplicationReceived(context.Background(), outbox.Event{ID: e.EventID, Type: e.EventType, Payload: e.Payload}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if sent := mailer.take(); len(sent) != 0 {
+		t.Errorf("sent again: %+v", sent)
+	}
+
+	// One-click unsubscribe, then no more updates.
+	path := strings.TrimPrefix(unsubscribe, "https://jobs.example.com")
+	api.expect(api.do("POST", path, "List-Unsubscribe=One-Click", ""), http.StatusOK, nil)
+	api.expect(api.do("PATCH", "/v1/admin/application/1", `{"status":"hired"}`, admin), http.StatusOK, nil)
+	api.dispatch()
+	if sent := mailer.take(); len(sent) != 0 {
+		t.Errorf("sent after unsubscribing: %+v", sent)
+	}
+}
+
+// Unsubscribing from application updates must not cost applicants
+// their interview invites.
+func TestInterviewEmailsIgnorePreferences(t *testing.T) {
+	api, mailer := newMailTestAPI(t)
+	admin := api.signup("admin@example.com", "admin")
+	applicant := api.signup("applicant@example.com", "applicant")
+	api.expect(api.do("POST", "/v1/admin/job", `{"title":"Backend","description":"d","company_name":"Acme"}`, admin), http.StatusCreated, nil)
+	api.expect(api.do("PUT", "/v1/me/notification-preferences", `{"application_updates":false,"applicant_digest":false}`, applicant), http.StatusOK, nil)
+	api.expect(api.do("GET", "/v1/jobs/apply?job_id=1", "", applicant), http.StatusOK, nil)
+
+	start := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Minute)
+	api.expect(api.do("POST", "/v1/admin/application/1/interviews", interviewBody(start, 45, api.userID("admin@example.com")), admin), http.StatusCreated, nil)
+	api.dispatch()
+	api.expect(api.do("POST", "/v1/admin/interview/1/cancel", "", admin), http.StatusOK, nil)
+	api.dispatch()
+
+	sent := mailer.take()
+	if len(sent) != 2 || sent[0].Subject != "Interview scheduled: Backend at Acme" || sent[1].Subject != "Interview cancelled: Backend at Acme" {
+		t.Fatalf("sent = %+v", sent)
+	}
+	for _, msg := range sent {
+		if len(msg.Attachments) != 1 || msg.Headers["List-Unsubscribe"] != "" ||
+			strings.Contains(msg.Text, "Unsubscribe") || strings.Contains(msg.HTML, "Unsubscribe") {
+			t.Errorf("%s: attachments %d, headers %v, text %q", msg.Subject, len(msg.Attachments), msg.Headers, msg.Text)
+		}
+	}
+}
+
+func TestApplicantDigest(t *testing.T) {
+	api, mailer := newMailTestAPI(t)
+	admin := api.signup("admin@example.com", "admin")
+	api.signup("other-admin@example.com", "admin")
+	applicant := api.signup("applicant@example.com", "applicant")
+	api.expect(api.do("POST", "/v1/admin/job", `{"title":"Backend","description":"d","company_name":"Acme"}`, admin), http.StatusCreated, nil)
+	api.expect(api.do("GET", "/v1/jobs/apply?job_id=1", "", applicant), http.StatusOK, nil)
+
+	ctx := context.Background()
+	now := time.Now()
+	if err := api.cfg.sendDigests(ctx, now, 24*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	// The other admin has no applicants, so gets no digest.
+	sent := mailer.take()
+	if len(sent) != 1 {
+		t.Fatalf("sent %+v", sent)
+	}
+	if d := sent[0]; d.To != "admin@example.com" || d.Subject != "1 new applicant for your jobs" ||
+		!strings.Contains(d.Text, "- Test User <applicant@example.com> applied to Backend") ||
+		!strings.Contains(d.HTML, "<td style=\"padding: 4px\">Backend</td>") {
+		t.Errorf("digest = %+v", d)
+	}
+
+	// Nobody is due again until the interval has passed.
+	if err := api.cfg.sendDigests(ctx, now.Add(time.Hour), 24*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if sent := mailer.take(); len(sent) != 0 {
+		t.Errorf("sent before the interval: %+v", sent)
+	}
+	if err := api.cfg.sendDigests(ctx, now.Add(25*time.Hour), 24*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if sent := mailer.take(); len(sent) != 0 {
+		t.Errorf("sent without new applicants: %+v", sent)
+	}
+}
+
+func TestNotificationPreferenceRoutes(t *testing.T) {
+	api, _ := newMailTestAPI(t)
+	admin := api.signup("admin@example.com", "admin")
+
+	prefs := notificationPreferences{}
+	api.expect(api.do("GET", "/v1/me/notification-preferences", "", admin), http.StatusOK, &prefs)
+	if !prefs.ApplicationUpdates || !prefs.ApplicantDigest {
+		t.Errorf("default preferences = %+v", prefs)
+	}
+	api.expect(api.do("PUT", "/v1/me/notification-preferences", `{"application_updates":true,"applicant_digest":false}`, admin), http.StatusOK, nil)
+	api.expectProblem(api.do("PUT", "/v1/me/notification-preferences", `{"application_updates":false}`, admin), http.StatusUnprocessableEntity, codeValidationFailed)
+	api.expectProblem(api.do("GET", "/v1/me/notification-preferences", "", ""), http.StatusUnauthorized, codeUnauthorized)
+	api.expect(api.do("GET", "/v1/me/notification-preferences", "", admin), http.StatusOK, &prefs)
+	if !prefs.ApplicationUpdates || prefs.ApplicantDigest {
+		t.Errorf("preferences = %+v", prefs)
+	}
+
+	// The confirmation page changes nothing.
+	link := api.cfg.unsubscribeURL(api.userID("admin@example.com"), listApplicationUpdates)
+	path := strings.TrimPrefix(link, "https://jobs.example.com")
+	res := api.do("GET", path, "", "")
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(body), `<form method="post">`) {
+		t.Fatalf("unsubscribe page: %d %s", res.StatusCode, body)
+	}
+	api.expect(api.do("GET", "/v1/me/notification-preferences", "", admin), http.StatusOK, &prefs)
+	if !prefs.ApplicationUpdates {
+		t.Errorf("preferences = %+v", prefs)
+	}
+	api.expect(api.do("POST", path, "", ""), http.StatusOK, nil)
+	api.expect(api.do("GET", "/v1/me/notification-preferences", "", admin), http.StatusOK, &prefs)
+	if prefs.ApplicationUpdates || prefs.ApplicantDigest {
+		t.Errorf("preferences = %+v", prefs)
+	}
+
+	api.expectProblem(api.do("POST", path+"x", "", ""), http.StatusUnprocessableEntity, codeValidationFailed)
+	api.expectProblem(api.do("GET", "/unsubscribe", "", ""), http.StatusUnprocessableEntity, codeValidationFailed)
+}
+
+func TestNotificationInbox(t *testing.T) {
+	api, _ := newMailTestAPI(t)
+	admin := api.signup("admin@example.com", "admin")
+	applicant := api.signup("applicant@example.com", "applicant")
+	api.expect(api.do("POST", "/v1/admin/job", `{"title":"Backend","description":"d","company_name":"Acme"}`, admin), http.StatusCreated, nil)
+	api.expect(api.do("GET", "/v1/jobs/apply?job_id=1", "", applicant), http.StatusOK, nil)
+	api.expect(api.do("PATCH", "/v1/admin/application/1", `{"status":"interviewing"}`, admin), http.StatusOK, nil)
+	api.expect(api.do("PATCH", "/v1/admin/application/1", `{"status":"offered"}`, admin), http.StatusOK, nil)
+	err := publish(context.Background(), api.store, eventResumeParsed, resumeParsedEvent{ApplicantID: api.userID("applicant@example.com")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.dispatch()
+
+	inbox := notificationsResponse{}
+	api.expect(api.do("GET", "/v1/me/notifications", "", admin), http.StatusOK, &inbox)
+	if inbox.UnreadCount != 1 || len(inbox.Notifications) != 1 ||
+		inbox.Notifications[0].Kind != notificationNewApplicant || inbox.Notifications[0].Body != "Test User applied to Backend." {
+		t.Errorf("admin inbox = %+v", inbox)
+	}
+
+	// Newest first, two to a page.
+	api.expect(api.do("GET", "/v1/me/notifications?limit=2", "", applicant), http.StatusOK, &inbox)
+	if inbox.UnreadCount != 3 || len(inbox.Notifications) != 2 || inbox.NextBefore == 0 ||
+		inbox.Notifications[0].Kind != notificationResumeParsed ||
+		inbox.Notifications[1].Title != "Your application for Backend is now offered" {
+		t.Fatalf("first page = %+v", inbox)
+	}
+	newest, next := inbox.Notifications[0].ID, inbox.NextBefore
+	inbox = notificationsResponse{}
+	api.expect(api.do("GET", fmt.Sprintf("/v1/me/notifications?limit=2&before=%d", next), "", applicant), http.StatusOK, &inbox)
+	if len(inbox.Notifications) != 1 || inbox.NextBefore != 0 ||
+		inbox.Notifications[0].Body != "The status of your application to Backend at Acme changed from applied to interviewing." {
+		t.Fatalf("second page = %+v", inbox)
+	}
+	api.expectProblem(api.do("GET", "/v1/me/notifications?limit=500", "", applicant), http.StatusUnprocessableEntity, codeValidationFailed)
+
+	// Other users' notifications are left alone.
+	read := markReadResponse{}
+	body := fmt.Sprintf(`{"ids":[%d,%d]}`, newest, newest+100)
+	api.expect(api.do("POST", "/v1/me/notifications/read", body, applicant), http.StatusOK, &read)
+	if read.Marked != 1 || read.UnreadCount != 2 {
+		t.Errorf("read = %+v", read)
+	}
+	api.expect(api.do("POST", "/v1/me/notifications/read", `{"all":true}`, applicant), http.StatusOK, &read)
+	if read.Marked != 2 || read.UnreadCount != 0 {
+		t.Errorf("read all = %+v", read)
+	}
+	api.expectProblem(api.do("POST", "/v1/me/notifications/read", `{}`, applicant), http.StatusUnprocessableEntity, codeValidationFailed)
+	api.expect(api.do("GET", "/v1/me/notifications", "", applicant), http.StatusOK, &inbox)
+	if inbox.Notifications[0].ReadAt == nil {
+		t.Errorf("inbox = %+v", inbox)
+	}
+
+	// A redelivered event adds nothing.
+	for _, e := range api.store.Outbox() {
+		if e.EventType != eventResumeParsed {
+			continue
+		}
+		if err := api.cfg.notifyResumeParsed(context.Background(), outbox.Event{ID: e.EventID, Type: e.EventType, Payload: e.Payload}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	api.expect(api.do("GET", "/v1/me/notifications", "", applicant), http.StatusOK, &inbox)
+	if len(inbox.Notifications) != 3 || inbox.UnreadCount != 0 {
+		t.Errorf("inbox after redelivery = %+v", inbox)
+	}
+}
@@ -0,0 +1,569 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+	"github.com/Vikuuu/synlabs-assignment/internal/openapi"
+)
+
+const bearerAuth = "bearerAuth"
+
+// apiRoute documents one pattern registered in router. Patterns of
+// versioned routes leave out the version prefix.
+type apiRoute struct {
+	pattern string
+	route   openapi.Route
+	// errors lists the problem codes the route may answer with besides
+	// internal.error.
+	errors []errorCode
+	// v1Only is set on routes added after /v1, which have no deprecated
+	// unversioned alias.
+	v1Only bool
+}
+
+var (
+	authErrors = []errorCode{codeUnauthorized, codeForbidden}
+	bodyErrors = []errorCode{codeMalformedRequest, codeValidationFailed}
+)
+
+func withErrors(lists ...[]errorCode) []errorCode {
+	var out []errorCode
+	for _, l := range lists {
+		out = append(out, l...)
+	}
+	return out
+}
+
+func textBody(status int, description string) openapi.Body {
+	return openapi.Body{Status: status, Description: description, ContentType: "text/plain", Type: ""}
+}
+
+// metaRoutes are served at the root, outside of any API version.
+func metaRoutes() []apiRoute {
+	unsubscribeToken := openapi.Param{Name: "token", In: "query", Type: "", Required: true}
+
+	return []apiRoute{
+		{pattern: "GET /{$}", route: openapi.Route{
+			Summary:   "Landing page",
+			Tags:      []string{"meta"},
+			Responses: []openapi.Body{textBody(http.StatusOK, "")},
+		}},
+		{pattern: "GET /openapi.json", route: openapi.Route{
+			Summary:   "This document",
+			Tags:      []string{"meta"},
+			Responses: []openapi.Body{{Status: http.StatusOK, Type: map[string]interface{}{}}},
+		}},
+		{pattern: "GET /docs", route: openapi.Route{
+			Summary: "Interactive API documentation",
+			Tags:    []string{"meta"},
+			Responses: []openapi.Body{
+				{Status: http.StatusOK, ContentType: "text/html", Type: ""},
+			},
+		}},
+		{pattern: "GET /healthz", route: openapi.Route{
+			Summary:     "Liveness probe",
+			Description: "Succeeds while the process can serve HTTP; does not touch the database.",
+			Tags:        []string{"meta"},
+			Responses:   []openapi.Body{{Status: http.StatusOK, Type: healthResponse{}}},
+		}},
+		{pattern: "GET /readyz", route: openapi.Route{
+			Summary:     "Readiness probe",
+			Description: "Fails while shutting down, when the database is unreachable or its schema is behind.",
+			Tags:        []string{"meta"},
+			Responses: []openapi.Body{
+				{Status: http.StatusOK, Type: healthResponse{}},
+				{Status: http.StatusServiceUnavailable, Type: healthResponse{}},
+			},
+		}},
+		{pattern: "GET /metrics", route: openapi.Route{
+			Summary:   "Prometheus metrics",
+			Tags:      []string{"meta"},
+			Responses: []openapi.Body{textBody(http.StatusOK, "Prometheus text exposition format")},
+		}},
+		{pattern: "GET /unsubscribe", route: openapi.Route{
+			Summary:     "Confirm unsubscribing from an email list",
+			Description: "Linked from the footer of every email. Shows a form that posts back to the same URL.",
+			Tags:        []string{"notifications"},
+			Params:      []openapi.Param{unsubscribeToken},
+			Responses: []openapi.Body{
+				{Status: http.StatusOK, ContentType: "text/html", Type: ""},
+			},
+		}, errors: []errorCode{codeValidationFailed}},
+		{pattern: "POST /unsubscribe", route: openapi.Route{
+			Summary:     "Unsubscribe from an email list",
+			Description: "Also the target of one-click unsubscribes from mail clients (RFC 8058).",
+			Tags:        []string{"notifications"},
+			Params:      []openapi.Param{unsubscribeToken},
+			Responses: []openapi.Body{
+				{Status: http.StatusOK, ContentType: "text/html", Type: ""},
+			},
+		}, errors: []errorCode{codeValidationFailed}},
+	}
+}
+
+// v1Routes are the routes of routesV1, without the /v1 prefix.
+func v1Routes() []apiRoute {
+	jobID := openapi.Param{Name: "job_id", In: "path", Type: int32(0)}
+	applicantID := openapi.Param{Name: "applicant_id", In: "path", Type: int32(0)}
+	applicationID := openapi.Param{Name: "application_id", In: "path", Type: int32(0)}
+	webhookID := openapi.Param{Name: "webhook_id", In: "path", Type: int32(0)}
+	deliveryID := openapi.Param{Name: "delivery_id", In: "path", Type: int64(0)}
+	interviewID := openapi.Param{Name: "interview_id", In: "path", Type: int32(0)}
+	invite := openapi.Body{
+		Status:      http.StatusOK,
+		Description: "iCalendar (RFC 5545) invite, or a cancellation once the interview is cancelled",
+		ContentType: "text/calendar",
+		Type:        "",
+	}
+
+	return []apiRoute{
+		{pattern: "POST /signup", route: openapi.Route{
+			Summary:   "Create an account",
+			Tags:      []string{"auth"},
+			Request:   signupPayload{},
+			Responses: []openapi.Body{{Status: http.StatusCreated, Type: signupResponse{}}},
+		}, errors: withErrors(bodyErrors, []errorCode{codeEmailTaken})},
+		{pattern: "POST /login", route: openapi.Route{
+			Summary:   "Log in with email and password",
+			Tags:      []string{"auth"},
+			Request:   loginPayload{},
+			Responses: []openapi.Body{{Status: http.StatusOK, Type: loginResponse{}}},
+		}, errors: withErrors(bodyErrors, []errorCode{codeInvalidCredentials})},
+		{pattern: "GET /login/oidc", route: openapi.Route{
+			Summary:     "Start single sign-on",
+			Description: "Redirects to the identity provider. Only available when OIDC is configured.",
+			Tags:        []string{"auth"},
+			Responses:   []openapi.Body{{Status: http.StatusFound, Description: "Redirect to the identity provider"}},
+		}, errors: []errorCode{codeUpstreamFailed}},
+		{pattern: "GET /login/oidc/callback", route: openapi.Route{
+			Summary: "Finish single sign-on",
+			Description: "Where the identity provider redirects back to. Only admins may sign in this way, " +
+				"with an email the provider has verified.",
+			Tags: []string{"auth"},
+			Params: []openapi.Param{
+				{Name: "code", In: "query", Type: ""},
+				{Name: "state", In: "query", Type: ""},
+				{Name: "error", In: "query", Type: ""},
+				{Name: "error_description", In: "query", Type: ""},
+			},
+			Responses: []openapi.Body{{Status: http.StatusOK, Type: loginResponse{}}},
+		}, errors: []errorCode{codeLoginSession, codeInvalidCredentials, codeForbidden, codeEmailTaken}},
+		{pattern: "POST /uploadResume", route: openapi.Route{
+			Summary:     "Upload and parse a resume",
+			Description: "Sends the resume to the parser and stores the extracted details on the caller's profile.",
+			Tags:        []string{"applicant"},
+			Security:    []string{bearerAuth},
+			Request:     uploadResumePayload{},
+			Responses:   []openapi.Body{{Status: http.StatusOK, Type: uploadResumeResponse{}}},
+		}, errors: withErrors(authErrors, bodyErrors, []errorCode{codeUpstreamFailed})},
+		{pattern: "GET /jobs", route: openapi.Route{
+			Summary:   "List open jobs",
+			Tags:      []string{"applicant"},
+			Security:  []string{bearerAuth},
+			Responses: []openapi.Body{{Status: http.StatusOK, Type: []jobListResponse{}}},
+		}, errors: authErrors},
+		{pattern: "GET /jobs/apply", route: openapi.Route{
+			Summary:   "Apply to a job",
+			Tags:      []string{"applicant"},
+			Security:  []string{bearerAuth},
+			Params:    []openapi.Param{{Name: "job_id", In: "query", Type: int32(0), Required: true}},
+			Responses: []openapi.Body{{Status: http.StatusOK, Type: applyResponse{}}},
+		}, errors: withErrors(authErrors, []errorCode{codeValidationFailed, codeJobNotFound, codeJobClosed})},
+		{pattern: "POST /admin/job", route: openapi.Route{
+			Summary:   "Post a job",
+			Tags:      []string{"admin"},
+			Security:  []string{bearerAuth},
+			Request:   addJobPayload{},
+			Responses: []openapi.Body{{Status: http.StatusCreated, Type: addJobResponse{}}},
+		}, errors: withErrors(authErrors, bodyErrors)},
+		{pattern: "GET /admin/job/{job_id}", route: openapi.Route{
+			Summary:   "Get a job",
+			Tags:      []string{"admin"},
+			Security:  []string{bearerAuth},
+			Params:    []openapi.Param{jobID},
+			Responses: []openapi.Body{{Status: http.StatusOK, Type: jobResponse{}}},
+		}, errors: withErrors(authErrors, []errorCode{codeValidationFailed, codeJobNotFound})},
+		{pattern: "GET /admin/applicants", route: openapi.Route{
+			Summary:   "List applicants",
+			Tags:      []string{"admin"},
+			Security:  []string{bearerAuth},
+			Responses: []openapi.Body{{Status: http.StatusOK, Type: []applicantsResponse{}}},
+		}, errors: authErrors},
+		{pattern: "GET /admin/applicant/{applicant_id}", route: openapi.Route{
+			Summary:   "Get an applicant with their parsed resume",
+			Tags:      []string{"admin"},
+			Security:  []string{bearerAuth},
+			Params:    []openapi.Param{applicantID},
+			Responses: []openapi.Body{{Status: http.StatusOK, Type: applicantResponse{}}},
+		}, errors: withErrors(authErrors, []errorCode{codeValidationFailed, codeApplicantNotFound})},
+		{pattern: "POST /admin/job/{job_id}/close", route: openapi.Route{
+			Summary:     "Close a job",
+			Description: "Stops the job from accepting applications. Only the admin who posted the job may close it.",
+			Tags:        []string{"admin"},
+			Security:    []string{bearerAuth},
+			Params:      []openapi.Param{jobID},
+			Responses:   []openapi.Body{{Status: http.StatusOK, Type: jobResponse{}}},
+		}, errors: withErrors(authErrors, []errorCode{codeValidationFailed, codeJobNotFound, codeJobClosed}), v1Only: true},
+		{pattern: "PATCH /admin/application/{application_id}", route: openapi.Route{
+			Summary:     "Change the status of an application",
+			Description: "Only the admin who posted the job may change the status of its applications.",
+			Tags:        []string{"admin"},
+			Security:    []string{bearerAuth},
+			Params:      []openapi.Param{applicationID},
+			Request:     updateApplicationPayload{},
+			Responses:   []openapi.Body{{Status: http.StatusOK, Type: applicationResponse{}}},
+		}, errors: withErrors(authErrors, bodyErrors, []errorCode{codeApplicationNotFound}), v1Only: true},
+		{pattern: "POST /admin/webhooks", route: openapi.Route{
+			Summary:     "Subscribe a webhook",
+			Description: "The secret signs every delivery and is only returned here; one is generated when none is given.",
+			Tags:        []string{"webhooks"},
+			Security:    []string{bearerAuth},
+			Request:     addWebhookPayload{},
+			Responses:   []openapi.Body{{Status: http.StatusCreated, Type: webhookResponse{}}},
+		}, errors: withErrors(authErrors, bodyErrors), v1Only: true},
+		{pattern: "GET /admin/webhooks", route: openapi.Route{
+			Summary:   "List webhooks",
+			Tags:      []string{"webhooks"},
+			Security:  []string{bearerAuth},
+			Responses: []openapi.Body{{Status: http.StatusOK, Type: []webhookResponse{}}},
+		}, errors: authErrors, v1Only: true},
+		{pattern: "DELETE /admin/webhooks/{webhook_id}", route: openapi.Route{
+			Summary:   "Delete a webhook and its delivery log",
+			Tags:      []string{"webhooks"},
+			Security:  []string{bearerAuth},
+			Params:    []openapi.Param{webhookID},
+			Responses: []openapi.Body{{Status: http.StatusNoContent, Description: "Deleted"}},
+		}, errors: withErrors(authErrors, []errorCode{codeValidationFailed, codeWebhookNotFound}), v1Only: true},
+		{pattern: "GET /admin/webhooks/{webhook_id}/deliveries", route: openapi.Route{
+			Summary:     "List the deliveries of a webhook",
+			Description: "The latest deliveries first.",
+			Tags:        []string{"webhooks"},
+			Security:    []string{bearerAuth},
+			Params:      []openapi.Param{webhookID},
+			Responses:   []openapi.Body{{Status: http.StatusOK, Type: []deliveryResponse{}}},
+		}, errors: withErrors(authErrors, []errorCode{codeValidationFailed}), v1Only: true},
+		{pattern: "GET /admin/webhooks/dead-letters", route: openapi.Route{
+			Summary:     "List dead deliveries",
+			Description: "Deliveries of any webhook that ran out of attempts, latest first.",
+			Tags:        []string{"webhooks"},
+			Security:    []string{bearerAuth},
+			Responses:   []openapi.Body{{Status: http.StatusOK, Type: []deliveryResponse{}}},
+		}, errors: authErrors, v1Only: true},
+		{pattern: "POST /admin/webhooks/deliveries/{delivery_id}/retry", route: openapi.Route{
+			Summary:     "Retry a dead delivery",
+			Description: "Queues the delivery again with a fresh set of attempts.",
+			Tags:        []string{"webhooks"},
+			Security:    []string{bearerAuth},
+			Params:      []openapi.Param{deliveryID},
+			Responses:   []openapi.Body{{Status: http.StatusAccepted, Description: "Queued"}},
+		}, errors: withErrors(authErrors, []errorCode{codeValidationFailed, codeDeliveryNotFound}), v1Only: true},
+		{pattern: "GET /me/notification-preferences", route: openapi.Route{
+			Summary:   "Get your email preferences",
+			Tags:      []string{"notifications"},
+			Security:  []string{bearerAuth},
+			Responses: []openapi.Body{{Status: http.StatusOK, Type: notificationPreferences{}}},
+		}, errors: []errorCode{codeUnauthorized}, v1Only: true},
+		{pattern: "PUT /me/notification-preferences", route: openapi.Route{
+			Summary:   "Set your email preferences",
+			Tags:      []string{"notifications"},
+			Security:  []string{bearerAuth},
+			Request:   notificationPreferencesPayload{},
+			Responses: []openapi.Body{{Status: http.StatusOK, Type: notificationPreferences{}}},
+		}, errors: withErrors([]errorCode{codeUnauthorized}, bodyErrors), v1Only: true},
+		{pattern: "GET /me/notifications", route: openapi.Route{
+			Summary:     "List your notifications",
+			Description: "Newest first, with the number of unread ones. Pass next_before as before to get the next page.",
+			Tags:        []string{"notifications"},
+			Security:    []string{bearerAuth},
+			Params: []openapi.Param{
+				{Name: "limit", In: "query", Type: int32(0), Description: "Page size, 1 to 100; 20 by default"},
+				{Name: "before", In: "query", Type: int64(0), Description: "Only list notifications older than this one"},
+			},
+			Responses: []openapi.Body{{Status: http.StatusOK, Type: notificationsResponse{}}},
+		}, errors: []errorCode{codeUnauthorized, codeValidationFailed}, v1Only: true},
+		{pattern: "POST /me/notifications/read", route: openapi.Route{
+			Summary:     "Mark notifications as read",
+			Description: "Marks the given notifications, or all of them. Notifications of other users are ignored.",
+			Tags:        []string{"notifications"},
+			Security:    []string{bearerAuth},
+			Request:     markReadPayload{},
+			Responses:   []openapi.Body{{Status: http.StatusOK, Type: markReadResponse{}}},
+		}, errors: withErrors([]errorCode{codeUnauthorized}, bodyErrors), v1Only: true},
+		{pattern: "GET /admin/events", route: openapi.Route{
+			Summary: "Stream application events for your jobs",
+			Description: "Server-sent events: application.created and application.status_changed for the jobs you posted, " +
+				"each with the event as data, as delivered to webhooks. Events sent while disconnected are not replayed.",
+			Tags:     []string{"admin"},
+			Security: []string{bearerAuth},
+			Responses: []openapi.Body{
+				{Status: http.StatusOK, ContentType: "text/event-stream", Type: ""},
+			},
+		}, errors: authErrors, v1Only: true},
+		{pattern: "GET /me/events", route: openapi.Route{
+			Summary: "Stream events for your applications",
+			Description: "Server-sent events: application.created and application.status_changed for your applications, " +
+				"each with the event as data, as delivered to webhooks. Events sent while disconnected are not replayed.",
+			Tags:     []string{"applicant"},
+			Security: []string{bearerAuth},
+			Responses: []openapi.Body{
+				{Status: http.StatusOK, ContentType: "text/event-stream", Type: ""},
+			},
+		}, errors: authErrors, v1Only: true},
+		{pattern: "POST /admin/application/{application_id}/interviews", route: openapi.Route{
+			Summary: "Schedule an interview",
+			Description: "The interviewers must be admins without another interview in the slot. " +
+				"The applicant is emailed the invite.",
+			Tags:      []string{"interviews"},
+			Security:  []string{bearerAuth},
+			Params:    []openapi.Param{applicationID},
+			Request:   interviewPayload{},
+			Responses: []openapi.Body{{Status: http.StatusCreated, Type: interviewResponse{}}},
+		}, errors: withErrors(authErrors, bodyErrors, []errorCode{codeApplicationNotFound, codeInterviewConflict}), v1Only: true},
+		{pattern: "GET /admin/application/{application_id}/interviews", route: openapi.Route{
+			Summary:   "List the interviews of an application",
+			Tags:      []string{"interviews"},
+			Security:  []string{bearerAuth},
+			Params:    []openapi.Param{applicationID},
+			Responses: []openapi.Body{{Status: http.StatusOK, Type: []interviewResponse{}}},
+		}, errors: withErrors(authErrors, []errorCode{codeValidationFailed, codeApplicationNotFound}), v1Only: true},
+		{pattern: "PUT /admin/interview/{interview_id}", route: openapi.Route{
+			Summary:     "Reschedule an interview",
+			Description: "Replaces the slot, interviewers and place. The applicant is emailed the new invite and has to confirm it again.",
+			Tags:        []string{"interviews"},
+			Security:    []string{bearerAuth},
+			Params:      []openapi.Param{interviewID},
+			Request:     interviewPayload{},
+			Responses:   []openapi.Body{{Status: http.StatusOK, Type: interviewResponse{}}},
+		}, errors: withErrors(authErrors, bodyErrors, []errorCode{codeInterviewNotFound, codeInterviewConflict, codeInterviewCancelled}), v1Only: true},
+		{pattern: "POST /admin/interview/{interview_id}/cancel", route: openapi.Route{
+			Summary:     "Cancel an interview",
+			Description: "The applicant is emailed a cancellation for their calendar.",
+			Tags:        []string{"interviews"},
+			Security:    []string{bearerAuth},
+			Params:      []openapi.Param{interviewID},
+			Responses:   []openapi.Body{{Status: http.StatusOK, Type: interviewResponse{}}},
+		}, errors: withErrors(authErrors, []errorCode{codeValidationFailed, codeInterviewNotFound, codeInterviewCancelled}), v1Only: true},
+		{pattern: "GET /admin/interview/{interview_id}/invite.ics", route: openapi.Route{
+			Summary:   "Download the invite to an interview",
+			Tags:      []string{"interviews"},
+			Security:  []string{bearerAuth},
+			Params:    []openapi.Param{interviewID},
+			Responses: []openapi.Body{invite},
+		}, errors: withErrors(authErrors, []errorCode{codeValidationFailed, codeInterviewNotFound}), v1Only: true},
+		{pattern: "GET /me/interviews", route: openapi.Route{
+			Summary:   "List your interviews",
+			Tags:      []string{"interviews"},
+			Security:  []string{bearerAuth},
+			Responses: []openapi.Body{{Status: http.StatusOK, Type: []interviewResponse{}}},
+		}, errors: authErrors, v1Only: true},
+		{pattern: "GET /me/interviews/{interview_id}/invite.ics", route: openapi.Route{
+			Summary:   "Download the invite to one of your interviews",
+			Tags:      []string{"interviews"},
+			Security:  []string{bearerAuth},
+			Params:    []openapi.Param{interviewID},
+			Responses: []openapi.Body{invite},
+		}, errors: withErrors(authErrors, []errorCode{codeValidationFailed, codeInterviewNotFound}), v1Only: true},
+		{pattern: "POST /me/interviews/{interview_id}/confirm", route: openapi.Route{
+			Summary:     "Confirm one of your interviews",
+			Description: "Confirming again is a no-op.",
+			Tags:        []string{"interviews"},
+			Security:    []string{bearerAuth},
+			Params:      []openapi.Param{interviewID},
+			Responses:   []openapi.Body{{Status: http.StatusOK, Type: interviewResponse{}}},
+		}, errors: withErrors(authErrors, []errorCode{codeValidationFailed, codeInterviewNotFound, codeInterviewCancelled}), v1Only: true},
+		{pattern: "POST /me/interviews/{interview_id}/reschedule", route: openapi.Route{
+			Summary:     "Ask to reschedule one of your interviews",
+			Description: "The note tells the interviewers why; the interview keeps its slot until an admin moves it.",
+			Tags:        []string{"interviews"},
+			Security:    []string{bearerAuth},
+			Params:      []openapi.Param{interviewID},
+			Request:     rescheduleRequestPayload{},
+			Responses:   []openapi.Body{{Status: http.StatusOK, Type: interviewResponse{}}},
+		}, errors: withErrors(authErrors, bodyErrors, []errorCode{codeInterviewNotFound, codeInterviewCancelled}), v1Only: true},
+		{pattern: "PUT /admin/job/{job_id}/scorecard", route: openapi.Route{
+			Summary:     "Set the scorecard of a job",
+			Description: "Feedback already given keeps the criteria it was given on.",
+			Tags:        []string{"scorecards"},
+			Security:    []string{bearerAuth},
+			Params:      []openapi.Param{jobID},
+			Request:     scorecardPayload{},
+			Responses:   []openapi.Body{{Status: http.StatusOK, Type: scorecardResponse{}}},
+		}, errors: withErrors(authErrors, bodyErrors, []errorCode{codeJobNotFound}), v1Only: true},
+		{pattern: "GET /admin/job/{job_id}/scorecard", route: openapi.Route{
+			Summary:   "Get the scorecard of a job",
+			Tags:      []string{"scorecards"},
+			Security:  []string{bearerAuth},
+			Params:    []openapi.Param{jobID},
+			Responses: []openapi.Body{{Status: http.StatusOK, Type: scorecardResponse{}}},
+		}, errors: withErrors(authErrors, []errorCode{codeValidationFailed, codeScorecardNotFound}), v1Only: true},
+		{pattern: "PUT /admin/application/{application_id}/feedback", route: openapi.Route{
+			Summary: "Save your feedback on an application",
+			Description: "Rates the application on the scorecard of its job, as a draft or submitted. " +
+				"Only admins interviewing for the application can give feedback. " +
+				"Submitted feedback can no longer be changed.",
+			Tags:      []string{"scorecards"},
+			Security:  []string{bearerAuth},
+			Params:    []openapi.Param{applicationID},
+			Request:   feedbackPayload{},
+			Responses: []openapi.Body{{Status: http.StatusOK, Type: feedbackResponse{}}},
+		}, errors: withErrors(authErrors, bodyErrors, []errorCode{codeApplicationNotFound, codeScorecardNotFound, codeFeedbackSubmitted}), v1Only: true},
+		{pattern: "GET /admin/application/{application_id}/feedback", route: openapi.Route{
+			Summary: "List the feedback on an application",
+			Description: "Interviewers of the application, and admins with a draft, " +
+				"see the feedback of others only once they have submitted their own.",
+			Tags:      []string{"scorecards"},
+			Security:  []string{bearerAuth},
+			Params:    []openapi.Param{applicationID},
+			Responses: []openapi.Body{{Status: http.StatusOK, Type: applicationFeedbackResponse{}}},
+		}, errors: withErrors(authErrors, []errorCode{codeValidationFailed, codeApplicationNotFound}), v1Only: true},
+	}
+}
+
+// newSchemas generates schemas for the payload types, with the types
+// whose JSON encoding does not follow from their Go type defined up front.
+func newSchemas() *openapi.Schemas {
+	schemas := openapi.NewSchemas()
+	schemas.Define(pgtype.Int4{}, (&openapi.Schema{Type: "integer", Format: "int32"}).Nullable())
+	schemas.Define(database.UserType(""), &openapi.Schema{
+		Type: "string",
+		Enum: []interface{}{database.UserTypeApplicant, database.UserTypeAdmin},
+	})
+	// The raw JSON served is always event data or a whole event.
+	schemas.Define(json.RawMessage{}, &openapi.Schema{Type: "object"})
+	statuses := []interface{}{}
+	for _, s := range applicationStatuses {
+		statuses = append(statuses, s)
+	}
+	schemas.Define(database.ApplicationStatus(""), &openapi.Schema{Type: "string", Enum: statuses})
+	schemas.Define(database.WebhookDeliveryStatus(""), &openapi.Schema{
+		Type: "string",
+		Enum: []interface{}{
+			database.WebhookDeliveryStatusPending,
+			database.WebhookDeliveryStatusDelivered,
+			database.WebhookDeliveryStatusDead,
+		},
+	})
+	schemas.Define(database.InterviewStatus(""), &openapi.Schema{
+		Type: "string",
+		Enum: []interface{}{
+			database.InterviewStatusScheduled,
+			database.InterviewStatusConfirmed,
+			database.InterviewStatusRescheduleRequested,
+			database.InterviewStatusCancelled,
+		},
+	})
+	schemas.Define(database.Recommendation(""), &openapi.Schema{
+		Type: "string",
+		Enum: []interface{}{
+			database.RecommendationStrongNo,
+			database.RecommendationNo,
+			database.RecommendationYes,
+			database.RecommendationStrongYes,
+		},
+	})
+	codes := []interface{}{}
+	for code := range errorCodes {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i].(errorCode) < codes[j].(errorCode) })
+	schemas.Define(errorCode(""), &openapi.Schema{Type: "string", Enum: codes})
+	return schemas
+}
+
+// buildSpec assembles the OpenAPI document from metaRoutes and every
+// API version, including the deprecated unversioned aliases.
+func buildSpec() (*openapi.Document, error) {
+	doc := openapi.New(openapi.Info{
+		Title:       "Synlabs jobs API",
+		Version:     "1.0.0",
+		Description: "Job board for applicants and admins. Errors are RFC 7807 problem documents with a stable code.",
+	}, newSchemas())
+	doc.Components.SecuritySchemes[bearerAuth] = &openapi.SecurityScheme{
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+		Description:  "Access token from POST /login.",
+	}
+	doc.Tags = []openapi.Tag{
+		{Name: "auth", Description: "Accounts and login"},
+		{Name: "applicant", Description: "Routes for applicants"},
+		{Name: "admin", Description: "Routes for admins"},
+		{Name: "webhooks", Description: "Outbound webhooks, for admins"},
+		{Name: "interviews", Description: "Interview scheduling and calendar invites"},
+		{Name: "scorecards", Description: "Scorecards and interview feedback, for admins"},
+		{Name: "notifications", Description: "The notification inbox, email preferences and unsubscribing"},
+		{Name: "meta", Description: "Health, metrics and documentation"},
+	}
+
+	routes := metaRoutes()
+	for _, ar := range v1Routes() {
+		legacy := ar
+		ar.pattern = prefixPattern(ar.pattern, "/v1")
+		routes = append(routes, ar)
+		if ar.v1Only {
+			continue
+		}
+
+		_, path, _ := strings.Cut(ar.pattern, " ")
+		legacy.route.Deprecated = true
+		legacy.route.Description = strings.TrimSpace(fmt.Sprintf(
+			"Deprecated alias of %s, removed on %s. %s",
+			path, legacySunset.Format(time.DateOnly), legacy.route.Description,
+		))
+		routes = append(routes, legacy)
+	}
+
+	for _, ar := range routes {
+		for _, code := range append(ar.errors, codeInternal) {
+			ar.route.Responses = append(ar.route.Responses, openapi.Body{
+				Status:      errorCodes[code].status,
+				Description: errorCodes[code].title,
+				ContentType: "application/problem+json",
+				Type:        problem{},
+			})
+		}
+		if err := doc.Add(ar.pattern, ar.route); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+var specJSON = sync.OnceValues(func() ([]byte, error) {
+	doc, err := buildSpec()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+})
+
+func handlerOpenAPI(w http.ResponseWriter, r *http.Request) error {
+	data, err := specJSON()
+	if err != nil {
+		return fmt.Errorf("building OpenAPI document: %w", err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+	return nil
+}
+
+//go:embed docs.html
+var docsPage []byte
+
+// handlerDocs serves an interactive viewer for /openapi.json.
+func handlerDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(docsPage)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/memstore"
+	"github.com/Vikuuu/synlabs-assignment/internal/oidc"
+)
+
+// TestEveryRouteIsDocumented fails when a route is registered without a
+// matching entry in openapi.go, or the other way around.
+func TestEveryRouteIsDocumented(t *testing.T) {
+	doc, err := buildSpec()
+	if err != nil {
+		t.Fatalf("building spec: %s", err)
+	}
+
+	// Enable OIDC so its routes are registered too.
+	cfg := &apiConfig{db: memstore.New(), oidc: oidc.NewClient(oidc.Config{Issuer: "https://idp.example.com"}, nil)}
+	registered := map[string]bool{}
+	for _, pattern := range cfg.router().patterns {
+		if !doc.Has(pattern) {
+			t.Errorf("route %q is missing from the OpenAPI document, describe it in openapi.go", pattern)
+		}
+		registered[normalizePattern(pattern)] = true
+	}
+	for _, pattern := range doc.Patterns() {
+		if !registered[pattern] {
+			t.Errorf("OpenAPI document describes %q, which is not registered", pattern)
+		}
+	}
+}
+
+var wildcardModifier = regexp.MustCompile(`\{([^}]+?)(\.\.\.)?\}`)
+
+// normalizePattern writes a ServeMux pattern the way Document.Patterns
+// lists it.
+func normalizePattern(pattern string) string {
+	return wildcardModifier.ReplaceAllString(strings.TrimSuffix(pattern, "{$}"), "{$1}")
+}
+
+func TestOpenAPIAndDocsRoutes(t *testing.T) {
+	srv := newTestServer(t)
+
+	res := doRequest(t, "GET", srv.URL+"/openapi.json", "", nil)
+	if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("status %d, content type %q", res.StatusCode, res.Header.Get("Content-Type"))
+	}
+	var spec struct {
+		OpenAPI    string                                `json:"openapi"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&spec); err != nil {
+		t.Fatal(err)
+	}
+	if spec.OpenAPI != "3.1.0" {
+		t.Errorf("openapi = %q", spec.OpenAPI)
+	}
+	if _, ok := spec.Paths["/v1/admin/job/{job_id}"]["get"]; !ok {
+		t.Errorf("paths = %v", spec.Paths)
+	}
+	for _, name := range []string{"SignupPayload", "JobResponse", "Problem"} {
+		if _, ok := spec.Components.Schemas[name]; !ok {
+			t.Errorf("schema %s is missing", name)
+		}
+	}
+
+	res = doRequest(t, "GET", srv.URL+"/docs", "", nil)
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(body), "openapi.json") {
+		t.Errorf("docs page: status %d", res.StatusCode)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// router is a ServeMux that remembers the patterns registered on it, so
+// tests can hold them against the OpenAPI document.
+type router struct {
+	*http.ServeMux
+	patterns []string
+}
+
+func newRouter() *router {
+	return &router{ServeMux: http.NewServeMux()}
+}
+
+func (rt *router) Handle(pattern string, h http.Handler) {
+	rt.ServeMux.Handle(pattern, h)
+	rt.patterns = append(rt.patterns, pattern)
+}
+
+func (rt *router) HandleFunc(pattern string, h func(http.ResponseWriter, *http.Request)) {
+	rt.Handle(pattern, http.HandlerFunc(h))
+}
+
+// version mounts one version of the API under its path prefix. Versions
+// register their own handlers, so /v2 can answer with different types
+// while /v1 keeps its contract.
+type version struct {
+	rt     *router
+	prefix string
+	routes []versionRoute
+}
+
+type versionRoute struct {
+	pattern string
+	handler http.Handler
+}
+
+func (rt *router) version(prefix string) *version {
+	return &version{rt: rt, prefix: prefix}
+}
+
+// Handle registers pattern, written without the version prefix.
+func (v *version) Handle(pattern string, h http.Handler) {
+	v.rt.Handle(prefixPattern(pattern, v.prefix), h)
+	v.routes = append(v.routes, versionRoute{pattern: pattern, handler: h})
+}
+
+// legacyAliases registers every route of v so far again at its
+// unversioned path. Responses announce the deprecation (RFC 9745), the date the
+// alias goes away (RFC 8594) and where the route lives now.
+func (v *version) legacyAliases(deprecatedAt, sunset time.Time) {
+	for _, route := range v.routes {
+		h := route.handler
+		v.rt.Handle(route.pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "@"+strconv.FormatInt(deprecatedAt.Unix(), 10))
+			w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			w.Header().Add("Link", "<"+v.prefix+r.URL.Path+`>; rel="successor-version"`)
+			h.ServeHTTP(w, r)
+		}))
+	}
+}
+
+// prefixPattern turns ("POST /signup", "/v1") into "POST /v1/signup".
+func prefixPattern(pattern, prefix string) string {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		return prefix + pattern
+	}
+	return method + " " + prefix + path
+}
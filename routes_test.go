@@ -0,0 +1,764 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/auth"
+	"github.com/Vikuuu/synlabs-assignment/internal/config"
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+	"github.com/Vikuuu/synlabs-assignment/internal/live"
+	"github.com/Vikuuu/synlabs-assignment/internal/memstore"
+	"github.com/Vikuuu/synlabs-assignment/internal/metrics"
+	"github.com/Vikuuu/synlabs-assignment/internal/oidc"
+	"github.com/Vikuuu/synlabs-assignment/internal/oidc/oidctest"
+	"github.com/Vikuuu/synlabs-assignment/sql/schema"
+)
+
+// testAPI is a server backed by the in-memory store.
+type testAPI struct {
+	t     *testing.T
+	cfg   *apiConfig
+	store *memstore.Store
+	srv   *httptest.Server
+}
+
+func newTestAPI(t *testing.T, opts ...func(*apiConfig)) *testAPI {
+	t.Helper()
+	store := memstore.New()
+	cfg := &apiConfig{
+		db:         store,
+		secret:     "test-secret",
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		live:       live.NewBroker(),
+	}
+	store.Listen(func(channel, payload string) {
+		if e, err := live.ParseEvent(payload); err == nil && channel == live.Channel {
+			cfg.live.Publish(e)
+		}
+	})
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	api := &testAPI{t: t, cfg: cfg, store: store, srv: startTestServer(t, cfg)}
+	// Open event streams would keep the server from closing.
+	t.Cleanup(cfg.live.Close)
+	return api
+}
+
+func (a *testAPI) do(method, path, body, token string) *http.Response {
+	a.t.Helper()
+	header := http.Header{}
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+	return doRequest(a.t, method, a.srv.URL+path, body, header)
+}
+
+// expect checks the status and decodes a JSON body into v when not nil.
+func (a *testAPI) expect(res *http.Response, status int, v interface{}) {
+	a.t.Helper()
+	if res.StatusCode != status {
+		body, _ := io.ReadAll(res.Body)
+		a.t.Fatalf("%s %s: status = %d, want %d: %s", res.Request.Method, res.Request.URL.Path, res.StatusCode, status, body)
+	}
+	if v != nil {
+		if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+			a.t.Fatalf("decoding response: %s", err)
+		}
+	}
+}
+
+func (a *testAPI) expectProblem(res *http.Response, status int, code errorCode) {
+	a.t.Helper()
+	if res.StatusCode != status {
+		a.t.Fatalf("%s %s: status = %d, want %d", res.Request.Method, res.Request.URL.Path, res.StatusCode, status)
+	}
+	if p := decodeProblem(a.t, res); p.Code != code {
+		a.t.Fatalf("code = %q, want %q", p.Code, code)
+	}
+}
+
+// signup creates a user and returns an access token for them.
+func (a *testAPI) signup(email, userType string) string {
+	a.t.Helper()
+	body := fmt.Sprintf(`{"name":"Test User","email":%q,"password":"secret123","user_type":%q,"address":"1 Main St","profile_headline":"Tester"}`, email, userType)
+	a.expect(a.do("POST", "/v1/signup", body, ""), http.StatusCreated, nil)
+
+	login := loginResponse{}
+	a.expect(a.do("POST", "/v1/login", fmt.Sprintf(`{"email":%q,"password":"secret123"}`, email), ""), http.StatusOK, &login)
+	return login.AccessToken
+}
+
+func (a *testAPI) userID(email string) int32 {
+	a.t.Helper()
+	u, err := a.store.GetUser(context.Background(), email)
+	if err != nil {
+		a.t.Fatalf("looking up %s: %s", email, err)
+	}
+	return u.ID
+}
+
+func TestLandingPage(t *testing.T) {
+	api := newTestAPI(t)
+	res := api.do("GET", "/", "", "")
+	api.expect(res, http.StatusOK, nil)
+	if body, _ := io.ReadAll(res.Body); string(body) != "Working" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+type fakeHealth struct {
+	pingErr error
+	version int64
+}
+
+func (h fakeHealth) Ping(context.Context) error { return h.pingErr }
+
+func (h fakeHealth) SchemaVersion(context.Context) (int64, error) { return h.version, nil }
+
+func TestHealthProbes(t *testing.T) {
+	latest, err := schema.LatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		health   fakeHealth
+		shutdown bool
+		want     int
+	}{
+		{"ready", fakeHealth{version: latest}, false, http.StatusOK},
+		{"schema behind", fakeHealth{version: latest - 1}, false, http.StatusServiceUnavailable},
+		{"database down", fakeHealth{pingErr: errors.New("refused")}, false, http.StatusServiceUnavailable},
+		{"shutting down", fakeHealth{version: latest}, true, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t, func(cfg *apiConfig) { cfg.health = tt.health })
+			api.cfg.shuttingDown.Store(tt.shutdown)
+
+			api.expect(api.do("GET", "/healthz", "", ""), http.StatusOK, nil)
+			res := healthResponse{}
+			api.expect(api.do("GET", "/readyz", "", ""), tt.want, &res)
+			if (tt.want == http.StatusOK) != (res.Status == "ready") {
+				t.Errorf("status = %q with checks %v", res.Status, res.Checks)
+			}
+		})
+	}
+}
+
+func TestMetricsRoute(t *testing.T) {
+	api := newTestAPI(t, func(cfg *apiConfig) { cfg.metrics = metrics.New(nil) })
+	api.signup("metrics@example.com", "applicant")
+
+	res := api.do("GET", "/metrics", "", "")
+	api.expect(res, http.StatusOK, nil)
+	body, _ := io.ReadAll(res.Body)
+	for _, want := range []string{
+		`http_requests_total{code="201",method="POST",route="POST /v1/signup"} 1`,
+		`signups_total{user_type="applicant"} 1`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics missing %s:\n%s", want, body)
+		}
+	}
+}
+
+func TestSignupAndLogin(t *testing.T) {
+	api := newTestAPI(t)
+
+	created := signupResponse{}
+	api.expect(api.do("POST", "/v1/signup", `{"name":"Ann","email":" Ann@Example.com ","password":"secret123"}`, ""), http.StatusCreated, &created)
+	if created.Email != "ann@example.com" || created.UserType != database.UserTypeApplicant {
+		t.Errorf("created = %+v", created)
+	}
+
+	api.expectProblem(api.do("POST", "/v1/signup", `{"name":"Ann","email":"ann@example.com","password":"secret123"}`, ""), http.StatusConflict, codeEmailTaken)
+	api.expectProblem(api.do("POST", "/v1/signup", `{"name":"","email":"bad","password":"short"}`, ""), http.StatusUnprocessableEntity, codeValidationFailed)
+
+	login := loginResponse{}
+	api.expect(api.do("POST", "/v1/login", `{"email":"ANN@example.com","password":"secret123"}`, ""), http.StatusOK, &login)
+	if login.AccessToken == "" || login.UserType != database.UserTypeApplicant {
+		t.Errorf("login = %+v", login)
+	}
+	api.expectProblem(api.do("POST", "/v1/login", `{"email":"ann@example.com","password":"wrong1234"}`, ""), http.StatusUnauthorized, codeInvalidCredentials)
+	api.expectProblem(api.do("POST", "/v1/login", `{"email":"nobody@example.com","password":"secret123"}`, ""), http.StatusUnauthorized, codeInvalidCredentials)
+
+	if _, err := api.store.GetApplicant(context.Background(), api.userID("ann@example.com")); err != nil {
+		t.Errorf("applicant profile not created: %s", err)
+	}
+}
+
+// Accounts from before emails were normalized may be stored mixed case.
+func TestMixedCaseStoredEmail(t *testing.T) {
+	api := newTestAPI(t)
+	hash, err := auth.HashPassword("secret123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.store.CreateUser(context.Background(), database.CreateUserParams{
+		Name:         "Bob",
+		Email:        "Bob@Example.com",
+		UserType:     database.UserTypeApplicant,
+		PasswordHash: hash,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	api.expect(api.do("POST", "/v1/login", `{"email":"Bob@Example.com","password":"secret123"}`, ""), http.StatusOK, nil)
+	api.expectProblem(api.do("POST", "/v1/signup", `{"name":"Bob","email":"bob@example.com","password":"secret123"}`, ""), http.StatusConflict, codeEmailTaken)
+}
+
+func TestAdminJobRoutes(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.signup("admin@example.com", "admin")
+	applicant := api.signup("applicant@example.com", "applicant")
+
+	job := addJobResponse{}
+	api.expect(api.do("POST", "/v1/admin/job", `{"title":"Go Developer","description":"Write Go","company_name":"Synlabs"}`, admin), http.StatusCreated, &job)
+	if job.Title != "Go Developer" {
+		t.Errorf("job = %+v", job)
+	}
+	api.expectProblem(api.do("POST", "/v1/admin/job", `{"title":""}`, admin), http.StatusUnprocessableEntity, codeValidationFailed)
+	api.expectProblem(api.do("POST", "/v1/admin/job", `{"title":"x","description":"y","company_name":"z"}`, applicant), http.StatusForbidden, codeForbidden)
+
+	got := jobResponse{}
+	api.expect(api.do("GET", "/v1/admin/job/1", "", admin), http.StatusOK, &got)
+	if got.CompanyName != "Synlabs" || got.PostedBy != api.userID("admin@example.com") || got.ClosedAt != nil {
+		t.Errorf("job = %+v", got)
+	}
+	api.expectProblem(api.do("GET", "/v1/admin/job/99", "", admin), http.StatusNotFound, codeJobNotFound)
+	api.expectProblem(api.do("GET", "/v1/admin/job/abc", "", admin), http.StatusUnprocessableEntity, codeValidationFailed)
+}
+
+func TestAdminApplicantRoutes(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.signup("admin@example.com", "admin")
+	applicant := api.signup("applicant@example.com", "applicant")
+
+	list := []applicantsResponse{}
+	api.expect(api.do("GET", "/v1/admin/applicants", "", admin), http.StatusOK, &list)
+	if len(list) != 1 || list[0].Email != "applicant@example.com" {
+		t.Errorf("applicants = %+v", list)
+	}
+	api.expectProblem(api.do("GET", "/v1/admin/applicants", "", applicant), http.StatusForbidden, codeForbidden)
+
+	one := applicantResponse{}
+	path := fmt.Sprintf("/v1/admin/applicant/%d", api.userID("applicant@example.com"))
+	api.expect(api.do("GET", path, "", admin), http.StatusOK, &one)
+	if one.Email != "applicant@example.com" || one.ProfileHeadline != "Tester" {
+		t.Errorf("applicant = %+v", one)
+	}
+	// Admins have no profile, so they are not applicants.
+	path = fmt.Sprintf("/v1/admin/applicant/%d", api.userID("admin@example.com"))
+	api.expectProblem(api.do("GET", path, "", admin), http.StatusNotFound, codeApplicantNotFound)
+}
+
+func TestApplicantJobRoutes(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.signup("admin@example.com", "admin")
+	applicant := api.signup("applicant@example.com", "applicant")
+	for _, title := range []string{"Backend", "Frontend"} {
+		body := fmt.Sprintf(`{"title":%q,"description":"d","company_name":"c"}`, title)
+		api.expect(api.do("POST", "/v1/admin/job", body, admin), http.StatusCreated, nil)
+	}
+
+	jobs := []jobListResponse{}
+	api.expect(api.do("GET", "/v1/jobs", "", applicant), http.StatusOK, &jobs)
+	if len(jobs) != 2 || jobs[0].Title != "Backend" {
+		t.Errorf("jobs = %+v", jobs)
+	}
+	api.expectProblem(api.do("GET", "/v1/jobs", "", admin), http.StatusForbidden, codeForbidden)
+
+	api.expect(api.do("GET", "/v1/jobs/apply?job_id=1", "", applicant), http.StatusOK, nil)
+	got := jobResponse{}
+	api.expect(api.do("GET", "/v1/admin/job/1", "", admin), http.StatusOK, &got)
+	if got.TotalApplications.Int32 != 1 {
+		t.Errorf("total applications = %d, want 1", got.TotalApplications.Int32)
+	}
+	if apps := api.store.Applications(); len(apps) != 1 || apps[0].ApplicantID.Int32 != api.userID("applicant@example.com") {
+		t.Errorf("applications = %+v", apps)
+	}
+
+	api.expectProblem(api.do("GET", "/v1/jobs/apply?job_id=99", "", applicant), http.StatusNotFound, codeJobNotFound)
+	api.expectProblem(api.do("GET", "/v1/jobs/apply?job_id=x", "", applicant), http.StatusUnprocessableEntity, codeValidationFailed)
+
+	api.store.CloseJob(context.Background(), database.CloseJobParams{ID: 2, ClosedAt: pgTime(time.Now())})
+	api.expectProblem(api.do("GET", "/v1/jobs/apply?job_id=2", "", applicant), http.StatusConflict, codeJobClosed)
+	api.expect(api.do("GET", "/v1/jobs", "", applicant), http.StatusOK, &jobs)
+	if len(jobs) != 1 {
+		t.Errorf("closed job still listed: %+v", jobs)
+	}
+}
+
+func TestApplicationStatusAndCloseJob(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.signup("admin@example.com", "admin")
+	applicant := api.signup("applicant@example.com", "applicant")
+	api.expect(api.do("POST", "/v1/admin/job", `{"title":"Backend","description":"d","company_name":"c"}`, admin), http.StatusCreated, nil)
+	api.expect(api.do("GET", "/v1/jobs/apply?job_id=1", "", applicant), http.StatusOK, nil)
+
+	app := applicationResponse{}
+	api.expect(api.do("PATCH", "/v1/admin/application/1", `{"status":"interviewing"}`, admin), http.StatusOK, &app)
+	if app.Status != database.ApplicationStatusInterviewing || app.JobTitle != "Backend" {
+		t.Errorf("application = %+v", app)
+	}
+	api.expectProblem(api.do("PATCH", "/v1/admin/application/1", `{"status":"ghosted"}`, admin), http.StatusUnprocessableEntity, codeValidationFailed)
+	api.expectProblem(api.do("PATCH", "/v1/admin/application/9", `{"status":"hired"}`, admin), http.StatusNotFound, codeApplicationNotFound)
+	api.expectProblem(api.do("PATCH", "/v1/admin/application/1", `{"status":"hired"}`, applicant), http.StatusForbidden, codeForbidden)
+
+	one := applicantResponse{}
+	api.expect(api.do("GET", fmt.Sprintf("/v1/admin/applicant/%d", api.userID("applicant@example.com")), "", admin), http.StatusOK, &one)
+	if len(one.Applications) != 1 || one.Applications[0].Status != database.ApplicationStatusInterviewing {
+		t.Errorf("applications = %+v", one.Applications)
+	}
+
+	job := jobResponse{}
+	api.expect(api.do("POST", "/v1/admin/job/1/close", "", admin), http.StatusOK, &job)
+	if job.ClosedAt == nil {
+		t.Errorf("job = %+v", job)
+	}
+	api.expectProblem(api.do("POST", "/v1/admin/job/1/close", "", admin), http.StatusConflict, codeJobClosed)
+	api.expectProblem(api.do("POST", "/v1/admin/job/9/close", "", admin), http.StatusNotFound, codeJobNotFound)
+}
+
+// Admins act only on the jobs they posted, and nothing is published when
+// they try another's.
+func TestAdminsCannotChangeOthersJobs(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.signup("admin@example.com", "admin")
+	other := api.signup("other@example.com", "admin")
+	applicant := api.signup("applicant@example.com", "applicant")
+	api.expect(api.do("POST", "/v1/admin/job", `{"title":"Backend","description":"d","company_name":"c"}`, admin), http.StatusCreated, nil)
+	api.expect(api.do("GET", "/v1/jobs/apply?job_id=1", "", applicant), http.StatusOK, nil)
+	published := len(api.store.Outbox())
+
+	api.expectProblem(api.do("PATCH", "/v1/admin/application/1", `{"status":"rejected"}`, other), http.StatusForbidden, codeForbidden)
+	api.expectProblem(api.do("POST", "/v1/admin/job/1/close", "", other), http.StatusForbidden, codeForbidden)
+	if n := len(api.store.Outbox()); n != published {
+		t.Errorf("published %d events for another admin's job", n-published)
+	}
+
+	job := jobResponse{}
+	api.expect(api.do("GET", "/v1/admin/job/1", "", admin), http.StatusOK, &job)
+	if job.ClosedAt != nil {
+		t.Errorf("job = %+v", job)
+	}
+	one := applicantResponse{}
+	api.expect(api.do("GET", fmt.Sprintf("/v1/admin/applicant/%d", api.userID("applicant@example.com")), "", admin), http.StatusOK, &one)
+	if one.Applications[0].Status != database.ApplicationStatusApplied {
+		t.Errorf("applications = %+v", one.Applications)
+	}
+}
+
+func TestWebhookRoutes(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.signup("admin@example.com", "admin")
+	applicant := api.signup("applicant@example.com", "applicant")
+
+	hook := webhookResponse{}
+	body := `{"url":"https://example.com/hook","events":["job.created","job.closed","job.created"]}`
+	api.expect(api.do("POST", "/v1/admin/webhooks", body, admin), http.StatusCreated, &hook)
+	if !strings.HasPrefix(hook.Secret, "whsec_") || len(hook.Events) != 2 {
+		t.Errorf("webhook = %+v", hook)
+	}
+	api.expectProblem(api.do("POST", "/v1/admin/webhooks", `{"url":"ftp://example.com","events":["job.deleted"],"secret":"short"}`, admin), http.StatusUnprocessableEntity, codeValidationFailed)
+	api.expectProblem(api.do("GET", "/v1/admin/webhooks", "", applicant), http.StatusForbidden, codeForbidden)
+
+	hooks := []webhookResponse{}
+	api.expect(api.do("GET", "/v1/admin/webhooks", "", admin), http.StatusOK, &hooks)
+	if len(hooks) != 1 || hooks[0].Secret != "" {
+		t.Errorf("webhooks = %+v", hooks)
+	}
+
+	// Only the subscribed events are queued.
+	api.expect(api.do("POST", "/v1/admin/job", `{"title":"Backend","description":"d","company_name":"c"}`, admin), http.StatusCreated, nil)
+	api.expect(api.do("GET", "/v1/jobs/apply?job_id=1", "", applicant), http.StatusOK, nil)
+	api.expect(api.do("POST", "/v1/admin/job/1/close", "", admin), http.StatusOK, nil)
+
+	deliveries := []deliveryResponse{}
+	api.expect(api.do("GET", fmt.Sprintf("/v1/admin/webhooks/%d/deliveries", hook.ID), "", admin), http.StatusOK, &deliveries)
+	if len(deliveries) != 2 || deliveries[0].EventType != eventJobClosed || deliveries[1].EventType != eventJobCreated {
+		t.Fatalf("deliveries = %+v", deliveries)
+	}
+	sent := event{}
+	if err := json.Unmarshal(deliveries[1].Payload, &sent); err != nil || sent.ID != deliveries[1].EventID || sent.Type != eventJobCreated {
+		t.Errorf("payload = %s, err = %v", deliveries[1].Payload, err)
+	}
+
+	dead := []deliveryResponse{}
+	api.expect(api.do("GET", "/v1/admin/webhooks/dead-letters", "", admin), http.StatusOK, &dead)
+	if len(dead) != 0 {
+		t.Errorf("dead letters = %+v", dead)
+	}
+	// Only dead deliveries can be retried.
+	api.expectProblem(api.do("POST", fmt.Sprintf("/v1/admin/webhooks/deliveries/%d/retry", deliveries[0].ID), "", admin), http.StatusNotFound, codeDeliveryNotFound)
+
+	api.expect(api.do("DELETE", fmt.Sprintf("/v1/admin/webhooks/%d", hook.ID), "", admin), http.StatusNoContent, nil)
+	api.expectProblem(api.do("DELETE", fmt.Sprintf("/v1/admin/webhooks/%d", hook.ID), "", admin), http.StatusNotFound, codeWebhookNotFound)
+}
+
+func TestEventsAreWrittenToTheOutbox(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.signup("admin@example.com", "admin")
+	applicant := api.signup("applicant@example.com", "applicant")
+
+	api.expect(api.do("POST", "/v1/admin/job", `{"title":"Backend","description":"d","company_name":"c"}`, admin), http.StatusCreated, nil)
+	api.expect(api.do("GET", "/v1/jobs/apply?job_id=1", "", applicant), http.StatusOK, nil)
+	api.expect(api.do("POST", "/v1/admin/job/1/close", "", admin), http.StatusOK, nil)
+	// A failed request leaves no event behind.
+	api.expectProblem(api.do("GET", "/v1/jobs/apply?job_id=1", "", applicant), http.StatusConflict, codeJobClosed)
+
+	types := []string{}
+	for _, e := range api.store.Outbox() {
+		sent := event{}
+		if err := json.Unmarshal(e.Payload, &sent); err != nil || sent.ID != e.EventID || sent.Type != e.EventType {
+			t.Errorf("payload = %s, err = %v", e.Payload, err)
+		}
+		types = append(types, e.EventType)
+	}
+	if got := strings.Join(types, ","); got != "job.created,application.created,job.closed" {
+		t.Errorf("outbox = %s", got)
+	}
+}
+
+// eventStream reads server-sent events from a response.
+type eventStream struct {
+	t *testing.T
+	r *bufio.Reader
+}
+
+func (a *testAPI) openStream(path, token string) *eventStream {
+	a.t.Helper()
+	res := a.do("GET", path, "", token)
+	a.expect(res, http.StatusOK, nil)
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		a.t.Fatalf("Content-Type = %q", ct)
+	}
+	s := &eventStream{t: a.t, r: bufio.NewReader(res.Body)}
+	// The retry field comes first, once the stream is subscribed.
+	if retry := s.next(); retry["retry"] != "5000" {
+		a.t.Fatalf("first message = %v", retry)
+	}
+	return s
+}
+
+// next returns the fields of the next message, skipping comments.
+func (s *eventStream) next() map[string]string {
+	s.t.Helper()
+	msg := make(chan map[string]string, 1)
+	go func() {
+		fields := map[string]string{}
+		for {
+			line, err := s.r.ReadString('\n')
+			if err != nil {
+				msg <- nil
+				return
+			}
+			line = strings.TrimSuffix(line, "\n")
+			if line == "" && len(fields) > 0 {
+				msg <- fields
+				return
+			}
+			if k, v, ok := strings.Cut(line, ": "); ok && k != "" {
+				fields[k] = v
+			}
+		}
+	}()
+	select {
+	case m := <-msg:
+		if m == nil {
+			s.t.Fatal("stream ended")
+		}
+		return m
+	case <-time.After(5 * time.Second):
+		s.t.Fatal("no event within 5s")
+	}
+	return nil
+}
+
+// application returns the type of an event and its data.
+func (s *eventStream) application() (string, applicationEvent) {
+	s.t.Helper()
+	m := s.next()
+	data := applicationEvent{}
+	if err := decodeEventData([]byte(m["data"]), &data); err != nil {
+		s.t.Fatalf("event %v: %s", m, err)
+	}
+	return m["event"], data
+}
+
+func TestEventStreams(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.signup("admin@example.com", "admin")
+	otherAdmin := api.signup("other-admin@example.com", "admin")
+	applicant := api.signup("applicant@example.com", "applicant")
+	otherApplicant := api.signup("other-applicant@example.com", "applicant")
+	api.expect(api.do("POST", "/v1/admin/job", `{"title":"Backend","description":"d","company_name":"c"}`, admin), http.StatusCreated, nil)
+	api.expect(api.do("POST", "/v1/admin/job", `{"title":"Frontend","description":"d","company_name":"c"}`, otherAdmin), http.StatusCreated, nil)
+
+	adminStream := api.openStream("/v1/admin/events", admin)
+	otherAdminStream := api.openStream("/v1/admin/events", otherAdmin)
+	applicantStream := api.openStream("/v1/me/events", applicant)
+	api.expectProblem(api.do("GET", "/v1/admin/events", "", applicant), http.StatusForbidden, codeForbidden)
+	api.expectProblem(api.do("GET", "/v1/me/events", "", admin), http.StatusForbidden, codeForbidden)
+
+	api.expect(api.do("GET", "/v1/jobs/apply?job_id=1", "", applicant), http.StatusOK, nil)
+	api.expect(api.do("GET", "/v1/jobs/apply?job_id=2", "", otherApplicant), http.StatusOK, nil)
+	api.expect(api.do("PATCH", "/v1/admin/application/1", `{"status":"reviewing"}`, admin), http.StatusOK, nil)
+
+	// Each stream only sees the applications it concerns.
+	if typ, data := adminStream.application(); typ != eventApplicationCreated || data.JobID != 1 {
+		t.Errorf("admin got %s %+v", typ, data)
+	}
+	if typ, data := adminStream.application(); typ != eventApplicationStatusChanged || data.Status != database.ApplicationStatusReviewing {
+		t.Errorf("admin got %s %+v", typ, data)
+	}
+	if typ, data := otherAdminStream.application(); typ != eventApplicationCreated || data.JobID != 2 {
+		t.Errorf("other admin got %s %+v", typ, data)
+	}
+	if typ, data := applicantStream.application(); typ != eventApplicationCreated || data.JobID != 1 {
+		t.Errorf("applicant got %s %+v", typ, data)
+	}
+	if typ, data := applicantStream.application(); typ != eventApplicationStatusChanged || data.ID != 1 {
+		t.Errorf("applicant got %s %+v", typ, data)
+	}
+}
+
+func TestUploadResume(t *testing.T) {
+	var gotKey string
+	parserStatus := http.StatusOK
+	parser := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("apiKey")
+		if parserStatus != http.StatusOK {
+			w.WriteHeader(parserStatus)
+			return
+		}
+		fmt.Fprint(w, `{"name":"Ann","email":"ann@example.com","phone":"555","skills":["Go","SQL"],"education":[{"name":"MIT"}]}`)
+	}))
+	t.Cleanup(parser.Close)
+
+	api := newTestAPI(t, func(cfg *apiConfig) {
+		cfg.resumeParser = config.ResumeParser{URL: parser.URL, APIKey: "parser-key", Timeout: 5 * time.Second}
+	})
+	admin := api.signup("admin@example.com", "admin")
+	applicant := api.signup("ann@example.com", "applicant")
+
+	resume := filepath.Join(t.TempDir(), "resume.pdf")
+	os.WriteFile(resume, []byte("%PDF-1.4"), 0o600)
+	body := fmt.Sprintf(`{"file_address":%q}`, resume)
+
+	res := uploadResumeResponse{}
+	api.expect(api.do("POST", "/v1/uploadResume", body, applicant), http.StatusOK, &res)
+	if res.Skills != "Go,SQL" || res.Education != "MIT" || gotKey != "parser-key" {
+		t.Errorf("response = %+v, api key %q", res, gotKey)
+	}
+
+	profile := applicantResponse{}
+	api.expect(api.do("GET", fmt.Sprintf("/v1/admin/applicant/%d", api.userID("ann@example.com")), "", admin), http.StatusOK, &profile)
+	if profile.Resume != resume || profile.Phone != "555" {
+		t.Errorf("profile = %+v", profile)
+	}
+
+	api.expectProblem(api.do("POST", "/v1/uploadResume", `{"file_address":"/nope/resume.pdf"}`, applicant), http.StatusUnprocessableEntity, codeValidationFailed)
+	api.expectProblem(api.do("POST", "/v1/uploadResume", `{"file_address":"resume.txt"}`, applicant), http.StatusUnprocessableEntity, codeValidationFailed)
+	api.expectProblem(api.do("POST", "/v1/uploadResume", body, admin), http.StatusForbidden, codeForbidden)
+
+	parserStatus = http.StatusInternalServerError
+	api.expectProblem(api.do("POST", "/v1/uploadResume", body, applicant), http.StatusBadGateway, codeUpstreamFailed)
+}
+
+// newOIDCTestServer serves the API with single sign-on against a mock
+// provider that logs everyone in as email. Admins are provisioned for
+// corp.example.com.
+func newOIDCTestServer(t *testing.T, email string) (*oidctest.Provider, *httptest.Server, *memstore.Store) {
+	t.Helper()
+	provider, idp, err := oidctest.NewServer("jobs-api", email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(idp.Close)
+
+	// The redirect URL must be known before the routes are built.
+	store := memstore.New()
+	cfg := &apiConfig{db: store, secret: "test-secret", httpClient: idp.Client()}
+	srv := httptest.NewUnstartedServer(nil)
+	cfg.oidc = oidc.NewClient(oidc.Config{
+		Issuer:      provider.Issuer,
+		ClientID:    "jobs-api",
+		RedirectURL: "http://" + srv.Listener.Addr().String() + "/v1/login/oidc/callback",
+	}, cfg.httpClient)
+	cfg.oidcAdminDomains = []string{"corp.example.com"}
+	cfg.oidcCookiePath = oidcCookiePath("http://" + srv.Listener.Addr().String() + "/v1/login/oidc/callback")
+	srv.Config.Handler = cfg.routes(newLogger(io.Discard, "error"))
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return provider, srv, store
+}
+
+// oidcLogin follows the single sign-on redirects like a browser would.
+func oidcLogin(t *testing.T, srv *httptest.Server) *http.Response {
+	t.Helper()
+	jar, _ := cookiejar.New(nil)
+	browser := &http.Client{Jar: jar}
+	res, err := browser.Get(srv.URL + "/v1/login/oidc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { res.Body.Close() })
+	return res
+}
+
+func TestOIDCLogin(t *testing.T) {
+	_, srv, store := newOIDCTestServer(t, "ops@corp.example.com")
+
+	res := oidcLogin(t, srv)
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		t.Fatalf("status = %d: %s", res.StatusCode, body)
+	}
+	login := loginResponse{}
+	json.NewDecoder(res.Body).Decode(&login)
+	if login.AccessToken == "" || login.UserType != database.UserTypeAdmin {
+		t.Errorf("login = %+v", login)
+	}
+	if _, err := store.GetUser(context.Background(), "ops@corp.example.com"); err != nil {
+		t.Errorf("admin was not provisioned: %s", err)
+	}
+
+	// A callback without the session cookie set by /login/oidc is refused.
+	res = doRequest(t, "GET", srv.URL+"/v1/login/oidc/callback?code=x&state=y", "", nil)
+	if p := decodeProblem(t, res); p.Code != codeLoginSession {
+		t.Errorf("code = %q, want %q", p.Code, codeLoginSession)
+	}
+}
+
+func TestOIDCCallbackStateMismatch(t *testing.T) {
+	_, srv, _ := newOIDCTestServer(t, "ops@corp.example.com")
+
+	// Stop at the provider, keeping the session cookie, and come back
+	// with a state of someone else's login.
+	jar, _ := cookiejar.New(nil)
+	browser := &http.Client{Jar: jar, CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	res, err := browser.Get(srv.URL + "/v1/login/oidc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("status = %d", res.StatusCode)
+	}
+	res, err = browser.Get(srv.URL + "/v1/login/oidc/callback?code=x&state=forged")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d", res.StatusCode)
+	}
+	if p := decodeProblem(t, res); p.Code != codeLoginSession {
+		t.Errorf("code = %q, want %q", p.Code, codeLoginSession)
+	}
+}
+
+func TestOIDCRequiresVerifiedEmail(t *testing.T) {
+	for _, verified := range []interface{}{nil, false} {
+		provider, srv, store := newOIDCTestServer(t, "ops@corp.example.com")
+		// A nil override drops the claim from the ID token.
+		provider.Claims = map[string]interface{}{"email_verified": verified}
+
+		if p := decodeProblem(t, oidcLogin(t, srv)); p.Code != codeForbidden {
+			t.Errorf("email_verified %v: code = %q, want %q", verified, p.Code, codeForbidden)
+		}
+		if _, err := store.GetUser(context.Background(), "ops@corp.example.com"); err == nil {
+			t.Errorf("email_verified %v: admin was provisioned", verified)
+		}
+	}
+}
+
+func TestOIDCLinksOnlyAdminDomains(t *testing.T) {
+	for _, tt := range []struct {
+		email string
+		code  errorCode
+	}{
+		{"ops@corp.example.com", ""},
+		{"ops@gmail.example.com", codeEmailTaken},
+	} {
+		_, srv, store := newOIDCTestServer(t, tt.email)
+		admin, err := store.CreateUser(context.Background(), database.CreateUserParams{
+			Name:         "Ops",
+			Email:        tt.email,
+			UserType:     database.UserTypeAdmin,
+			PasswordHash: "x",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res := oidcLogin(t, srv)
+		if tt.code != "" {
+			if p := decodeProblem(t, res); p.Code != tt.code {
+				t.Errorf("%s: code = %q, want %q", tt.email, p.Code, tt.code)
+			}
+			continue
+		}
+		login := loginResponse{}
+		json.NewDecoder(res.Body).Decode(&login)
+		userID, err := auth.ValidateJWT(login.AccessToken, "test-secret")
+		if res.StatusCode != http.StatusOK || err != nil || userID != int(admin.ID) {
+			t.Errorf("%s: status = %d, login = %+v, err = %v", tt.email, res.StatusCode, login, err)
+		}
+	}
+}
+
+func pgTime(t time.Time) pgtype.Timestamp {
+	return pgtype.Timestamp{Time: t, Valid: true}
+}
+
+func TestLegacyPathsAreDeprecatedAliases(t *testing.T) {
+	api := newTestAPI(t)
+
+	res := api.do("POST", "/v1/signup", `{"name":"Ann","email":"ann@example.com","password":"secret123"}`, "")
+	api.expect(res, http.StatusCreated, nil)
+	if res.Header.Get("Deprecation") != "" {
+		t.Errorf("/v1 route is marked deprecated")
+	}
+
+	// The old path reaches the same handler, errors included.
+	res = api.do("POST", "/signup", `{"name":"Ann","email":"ann@example.com","password":"secret123"}`, "")
+	api.expectProblem(res, http.StatusConflict, codeEmailTaken)
+	if got := res.Header.Get("Deprecation"); got != "@1792368000" {
+		t.Errorf("Deprecation = %q", got)
+	}
+	if got := res.Header.Get("Sunset"); got != "Mon, 19 Apr 2027 00:00:00 GMT" {
+		t.Errorf("Sunset = %q", got)
+	}
+
+	token := api.signup("applicant@example.com", "applicant")
+	res = api.do("GET", "/admin/job/7", "", token)
+	api.expectProblem(res, http.StatusForbidden, codeForbidden)
+	if got := res.Header.Get("Link"); got != `</v1/admin/job/7>; rel="successor-version"` {
+		t.Errorf("Link = %q", got)
+	}
+
+	// Routes added after /v1 have no unversioned alias.
+	api.expect(api.do("GET", "/admin/webhooks", "", token), http.StatusNotFound, nil)
+
+	// Routes outside the API are not versioned.
+	api.expect(api.do("GET", "/v1/healthz", "", ""), http.StatusNotFound, nil)
+}
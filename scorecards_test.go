@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+)
+
+const scorecardBody = `{"criteria":[{"name":"Go","description":"Idiomatic code","scale":5},{"name":"Design","scale":3}]}`
+
+func TestScorecardTemplates(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.signup("admin@example.com", "admin")
+	api.expect(api.do("POST", "/v1/admin/job", `{"title":"Backend","description":"d","company_name":"Acme"}`, admin), http.StatusCreated, nil)
+
+	api.expectProblem(api.do("GET", "/v1/admin/job/1/scorecard", "", admin), http.StatusNotFound, codeScorecardNotFound)
+	for _, body := range []string{
+		`{"criteria":[]}`,
+		`{"criteria":[{"name":" ","scale":5}]}`,
+		`{"criteria":[{"name":"Go","scale":1}]}`,
+		`{"criteria":[{"name":"Go","scale":5},{"name":"go","scale":3}]}`,
+	} {
+		api.expectProblem(api.do("PUT", "/v1/admin/job/1/scorecard", body, admin), http.StatusUnprocessableEntity, codeValidationFailed)
+	}
+	api.expectProblem(api.do("PUT", "/v1/admin/job/9/scorecard", scorecardBody, admin), http.StatusNotFound, codeJobNotFound)
+
+	set := scorecardResponse{}
+	api.expect(api.do("PUT", "/v1/admin/job/1/scorecard", scorecardBody, admin), http.StatusOK, &set)
+	got := scorecardResponse{}
+	api.expect(api.do("GET", "/v1/admin/job/1/scorecard", "", admin), http.StatusOK, &got)
+	if len(got.Criteria) != 2 || got.Criteria[0] != (scorecardCriterion{Name: "Go", Description: "Idiomatic code", Scale: 5}) ||
+		got.UpdatedBy != api.userID("admin@example.com") || !got.UpdatedAt.Equal(set.UpdatedAt) {
+		t.Errorf("scorecard = %+v", got)
+	}
+}
+
+func TestScorecardFeedback(t *testing.T) {
+	api := newTestAPI(t)
+	poster := api.signup("poster@example.com", "admin")
+	interviewer := api.signup("interviewer@example.com", "admin")
+	reviewer := api.signup("reviewer@example.com", "admin")
+	applicant := api.signup("applicant@example.com", "applicant")
+	api.expect(api.do("POST", "/v1/admin/job", `{"title":"Backend","description":"d","company_name":"Acme"}`, poster), http.StatusCreated, nil)
+	api.expect(api.do("GET", "/v1/jobs/apply?job_id=1", "", applicant), http.StatusOK, nil)
+	start := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Minute)
+	api.expect(api.do("POST", "/v1/admin/application/1/interviews",
+		interviewBody(start, 45, api.userID("poster@example.com"), api.userID("interviewer@example.com")), poster), http.StatusCreated, nil)
+
+	api.expectProblem(api.do("PUT", "/v1/admin/application/1/feedback", `{"ratings":{"Go":4}}`, poster), http.StatusNotFound, codeScorecardNotFound)
+	api.expect(api.do("PUT", "/v1/admin/job/1/scorecard", scorecardBody, poster), http.StatusOK, nil)
+
+	api.expectProblem(api.do("PUT", "/v1/admin/application/9/feedback", `{"ratings":{}}`, poster), http.StatusNotFound, codeApplicationNotFound)
+	for _, body := range []string{
+		`{"ratings":{"Go":6}}`,
+		`{"ratings":{"Rust":3}}`,
+		`{"ratings":{"Go":4},"recommendation":"yes","submit":true}`,
+		`{"ratings":{"Go":4,"Design":2},"submit":true}`,
+		`{"ratings":{"Go":4},"recommendation":"maybe"}`,
+	} {
+		api.expectProblem(api.do("PUT", "/v1/admin/application/1/feedback", body, poster), http.StatusUnprocessableEntity, codeValidationFailed)
+	}
+
+	// The poster submits; the other interviewer has not, so cannot see it.
+	api.expect(api.do("PUT", "/v1/admin/application/1/feedback", `{"ratings":{"Go":4,"Design":2},"recommendation":"yes","notes":"Solid","submit":true}`, poster), http.StatusOK, nil)
+	view := applicationFeedbackResponse{}
+	api.expect(api.do("GET", "/v1/admin/application/1/feedback", "", interviewer), http.StatusOK, &view)
+	if !view.Summary.Hidden || view.Summary.Submitted != 1 || len(view.Feedback) != 0 || len(view.Summary.Criteria) != 0 || view.Mine != nil {
+		t.Errorf("interviewer before feedback = %+v", view)
+	}
+
+	draft := feedbackResponse{}
+	api.expect(api.do("PUT", "/v1/admin/application/1/feedback", `{"ratings":{"Go":2},"notes":"Unsure"}`, interviewer), http.StatusOK, &draft)
+	if draft.SubmittedAt != nil || draft.InterviewerName != "Test User" || len(draft.Ratings) != 1 {
+		t.Errorf("draft = %+v", draft)
+	}
+	api.expect(api.do("GET", "/v1/admin/application/1/feedback", "", interviewer), http.StatusOK, &view)
+	if !view.Summary.Hidden || view.Mine == nil || view.Mine.Notes != "Unsure" || len(view.Feedback) != 0 {
+		t.Errorf("interviewer with a draft = %+v", view)
+	}
+
+	api.expect(api.do("PUT", "/v1/admin/application/1/feedback", `{"ratings":{"Go":3,"Design":3},"recommendation":"strong_yes","submit":true}`, interviewer), http.StatusOK, nil)
+	api.expectProblem(api.do("PUT", "/v1/admin/application/1/feedback", `{"ratings":{"Go":1}}`, interviewer), http.StatusConflict, codeFeedbackSubmitted)
+
+	view = applicationFeedbackResponse{}
+	api.expect(api.do("GET", "/v1/admin/application/1/feedback", "", interviewer), http.StatusOK, &view)
+	if view.Summary.Hidden || len(view.Feedback) != 1 || view.Feedback[0].Notes != "Solid" || view.Mine == nil || view.Mine.SubmittedAt == nil {
+		t.Errorf("interviewer after feedback = %+v", view)
+	}
+	want := []criterionSummary{{Criterion: "Go", Scale: 5, Average: 3.5, Ratings: 2}, {Criterion: "Design", Scale: 3, Average: 2.5, Ratings: 2}}
+	if fmt.Sprint(view.Summary.Criteria) != fmt.Sprint(want) ||
+		view.Summary.Recommendations[string(database.RecommendationYes)] != 1 || view.Summary.Recommendations[string(database.RecommendationStrongYes)] != 1 {
+		t.Errorf("summary = %+v", view.Summary)
+	}
+
+	// An admin with no part in the application sees it all, and so does
+	// the applicant's profile.
+	view = applicationFeedbackResponse{}
+	api.expect(api.do("GET", "/v1/admin/application/1/feedback", "", reviewer), http.StatusOK, &view)
+	if view.Summary.Hidden || len(view.Feedback) != 2 || view.Mine != nil {
+		t.Errorf("reviewer = %+v", view)
+	}
+	// Reading it all does not let them rate the applicant.
+	api.expectProblem(api.do("PUT", "/v1/admin/application/1/feedback", `{"ratings":{"Go":5,"Design":3},"recommendation":"strong_yes","submit":true}`, reviewer), http.StatusForbidden, codeForbidden)
+	profile := applicantResponse{}
+	api.expect(api.do("GET", fmt.Sprintf("/v1/admin/applicant/%d", api.userID("applicant@example.com")), "", reviewer), http.StatusOK, &profile)
+	if len(profile.Scorecards) != 1 || profile.Scorecards[0].ApplicationID != 1 || profile.Scorecards[0].Submitted != 2 ||
+		len(profile.Scorecards[0].Criteria) != 2 {
+		t.Errorf("applicant scorecards = %+v", profile.Scorecards)
+	}
+}
+
+func TestFeedbackNotification(t *testing.T) {
+	api, _ := newMailTestAPI(t)
+	poster := api.signup("poster@example.com", "admin")
+	interviewer := api.signup("interviewer@example.com", "admin")
+	applicant := api.signup("applicant@example.com", "applicant")
+	api.expect(api.do("POST", "/v1/admin/job", `{"title":"Backend","description":"d","company_name":"Acme"}`, poster), http.StatusCreated, nil)
+	api.expect(api.do("GET", "/v1/jobs/apply?job_id=1", "", applicant), http.StatusOK, nil)
+	api.expect(api.do("PUT", "/v1/admin/job/1/scorecard", scorecardBody, poster), http.StatusOK, nil)
+	start := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Minute)
+	api.expect(api.do("POST", "/v1/admin/application/1/interviews", interviewBody(start, 45, api.userID("interviewer@example.com")), poster), http.StatusCreated, nil)
+
+	api.expect(api.do("PUT", "/v1/admin/application/1/feedback", `{"ratings":{"Go":4,"Design":2},"recommendation":"no","submit":true}`, interviewer), http.StatusOK, nil)
+	api.dispatch()
+	inbox := notificationsResponse{}
+	api.expect(api.do("GET", "/v1/me/notifications", "", poster), http.StatusOK, &inbox)
+	if len(inbox.Notifications) == 0 || inbox.Notifications[0].Kind != notificationFeedbackSubmitted ||
+		!strings.Contains(inbox.Notifications[0].Body, "applied to Backend") || strings.Contains(string(inbox.Notifications[0].Data), "recommendation") {
+		t.Errorf("poster inbox = %+v", inbox)
+	}
+}
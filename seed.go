@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Vikuuu/synlabs-assignment/internal/auth"
+	"github.com/Vikuuu/synlabs-assignment/internal/config"
+	"github.com/Vikuuu/synlabs-assignment/internal/database"
+)
+
+var (
+	seedFirstNames = []string{
+		"Aarav", "Priya", "Rohan", "Ananya", "Vikram", "Meera", "Arjun", "Isha",
+		"Karan", "Neha", "Sanjay", "Divya", "Rahul", "Pooja", "Aditya", "Kavya",
+		"Emma", "Liam", "Olivia", "Noah", "Sofia", "Lucas", "Mia", "Ethan",
+	}
+	seedLastNames = []string{
+		"Sharma", "Patel", "Iyer", "Reddy", "Gupta", "Nair", "Mehta", "Kapoor",
+		"Singh", "Das", "Joshi", "Rao", "Smith", "Garcia", "Müller", "Rossi",
+	}
+	seedCities = []string{
+		"Bengaluru, KA", "Pune, MH", "Hyderabad, TG", "Chennai, TN", "Mumbai, MH",
+		"New Delhi, DL", "Kolkata, WB", "Berlin, DE", "Lisbon, PT", "Austin, TX",
+	}
+	seedHeadlines = []string{
+		"Backend engineer focused on Go and Postgres",
+		"Full-stack developer who enjoys shipping product",
+		"Data analyst turning SQL into decisions",
+		"Frontend engineer, React and accessibility",
+		"DevOps engineer automating everything",
+		"New graduate looking for a first role in software",
+		"Mobile developer with five years on Android",
+		"QA engineer passionate about test automation",
+	}
+	seedSkills = []string{
+		"Go", "Python", "PostgreSQL", "Docker", "Kubernetes", "React", "TypeScript",
+		"AWS", "Terraform", "Java", "Kotlin", "SQL", "Linux", "gRPC", "Redis",
+	}
+	seedSchools = []string{
+		"IIT Bombay", "NIT Trichy", "BITS Pilani", "Anna University",
+		"University of Pune", "TU Munich", "University of Texas",
+	}
+	seedCompanies = []string{
+		"Synlabs", "Northwind Traders", "Acme Analytics", "Globex", "Initech",
+		"Umbrella Health", "Hooli", "Stark Logistics",
+	}
+	seedTitles = []string{
+		"Senior Backend Engineer", "Frontend Developer", "Site Reliability Engineer",
+		"Data Engineer", "Engineering Manager", "QA Automation Engineer",
+		"Product Designer", "Android Developer", "Platform Engineer",
+	}
+)
+
+// runSeed fills an empty development database with fake admins,
+// applicants, jobs and applications. Every seeded user shares one
+// password so they can log in.
+func runSeed(ctx context.Context, cfg config.Config, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	fs.SetOutput(out)
+	admins := fs.Int("admins", 2, "number of admins")
+	applicants := fs.Int("applicants", 25, "number of applicants")
+	jobs := fs.Int("jobs", 10, "number of jobs")
+	applications := fs.Int("applications", 40, "number of applications")
+	password := fs.String("password", "seedpass1", "password of every seeded user")
+	seed := fs.Uint64("seed", 1, "random seed, for reproducible data")
+	force := fs.Bool("force", false, "seed even if the database already has users")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *admins < 1 && *jobs > 0 {
+		return errors.New("jobs need at least one admin to post them")
+	}
+	if *applicants < 1 && *applications > 0 {
+		return errors.New("applications need at least one applicant")
+	}
+
+	api, closeDB, err := openAdmin(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	existing, err := api.db.CountUsers(ctx)
+	if err != nil {
+		return err
+	}
+	if existing > 0 && !*force {
+		return fmt.Errorf("database already has %d users, pass -force to seed anyway", existing)
+	}
+
+	hash, err := auth.HashPassword(*password)
+	if err != nil {
+		return err
+	}
+	rng := rand.New(rand.NewPCG(*seed, uint64(existing)))
+	pick := func(list []string) string { return list[rng.IntN(len(list))] }
+	// The suffix keeps emails unique when seeding more than once.
+	suffix := time.Now().Unix() % 100000
+
+	newUser := func(i int, userType database.UserType) (database.CreateUserRow, error) {
+		first, last := pick(seedFirstNames), pick(seedLastNames)
+		headline := pick(seedHeadlines)
+		if userType == database.UserTypeAdmin {
+			headline = "Recruiter at " + pick(seedCompanies)
+		}
+		return api.createUser(ctx, database.CreateUserParams{
+			Name:            first + " " + last,
+			Email:           fmt.Sprintf("%s.%s.%d.%d@example.com", strings.ToLower(first), strings.ToLower(last), suffix, i),
+			Address:         fmt.Sprintf("%d %s Road, %s", 1+rng.IntN(200), pick(seedLastNames), pick(seedCities)),
+			UserType:        userType,
+			PasswordHash:    hash,
+			ProfileHeadline: headline,
+		})
+	}
+
+	adminIDs := []int32{}
+	for i := range *admins {
+		u, err := newUser(i, database.UserTypeAdmin)
+		if err != nil {
+			return err
+		}
+		adminIDs = append(adminIDs, u.ID)
+	}
+
+	applicantIDs := []int32{}
+	for i := range *applicants {
+		u, err := newUser(*admins+i, database.UserTypeApplicant)
+		if err != nil {
+			return err
+		}
+		applicantIDs = append(applicantIDs, u.ID)
+
+		skills := []string{}
+		for range 3 + rng.IntN(4) {
+			skills = append(skills, pick(seedSkills))
+		}
+		_, err = api.db.UpdateProfile(ctx, database.UpdateProfileParams{
+			Name:              pgtype.Text{String: u.Name, Valid: true},
+			Email:             pgtype.Text{String: u.Email, Valid: true},
+			Phone:             pgtype.Text{String: fmt.Sprintf("+91 9%09d", rng.IntN(1_000_000_000)), Valid: true},
+			Skills:            pgtype.Text{String: strings.Join(skills, ","), Valid: true},
+			Education:         pgtype.Text{String: pick(seedSchools), Valid: true},
+			ResumeFileAddress: pgtype.Text{String: fmt.Sprintf("/data/resumes/%d.pdf", u.ID), Valid: true},
+			Applicant:         u.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("filling profile: %w", err)
+		}
+	}
+
+	jobIDs := []int32{}
+	for range *jobs {
+		company := pick(seedCompanies)
+		title := pick(seedTitles)
+		j, err := api.db.CreateJob(ctx, database.CreateJobParams{
+			Title:       title,
+			Description: fmt.Sprintf("%s is hiring a %s to work with %s and %s.", company, title, pick(seedSkills), pick(seedSkills)),
+			PostedOn:    time.Now().Add(-time.Duration(rng.IntN(30*24)) * time.Hour),
+			CompanyName: company,
+			PostedBy:    adminIDs[rng.IntN(len(adminIDs))],
+		})
+		if err != nil {
+			return fmt.Errorf("creating job: %w", err)
+		}
+		jobIDs = append(jobIDs, j.ID)
+	}
+
+	applied := map[[2]int32]bool{}
+	created := 0
+	for range *applications {
+		if len(jobIDs) == 0 || len(applied) == len(jobIDs)*len(applicantIDs) {
+			break
+		}
+		key := [2]int32{applicantIDs[rng.IntN(len(applicantIDs))], jobIDs[rng.IntN(len(jobIDs))]}
+		if applied[key] {
+			continue
+		}
+		applied[key] = true
+		_, err := api.db.ApplyJob(ctx, database.ApplyJobParams{
+			ApplicantID: pgtype.Int4{Int32: key[0], Valid: true},
+			JobID:       pgtype.Int4{Int32: key[1], Valid: true},
+			AppliedAt:   time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("applying to job: %w", err)
+		}
+		if err := api.db.UpdateTotalApplications(ctx, key[1]); err != nil {
+			return fmt.Errorf("increasing the count of total applicants: %w", err)
+		}
+		created++
+	}
+
+	fmt.Fprintf(out, "seeded %d admins, %d applicants, %d jobs, %d applications (password %q)\n",
+		len(adminIDs), len(applicantIDs), len(jobIDs), created, *password)
+	return nil
+}
@@ -0,0 +1,19 @@
+-- name: ExportUsers :many
+SELECT id, name, email, address, user_type, profile_headline
+FROM users
+ORDER BY id;
+
+-- name: ExportProfiles :many
+SELECT applicant, resume_file_address, skills, education, name, email, phone
+FROM profile
+ORDER BY applicant;
+
+-- name: ExportJobs :many
+SELECT id, title, description, posted_on, total_applications, company_name, posted_by, closed_at
+FROM job
+ORDER BY id;
+
+-- name: ExportApplications :many
+SELECT applicant_id, job_id, id, status, applied_at
+FROM apply_jobs
+ORDER BY job_id, applicant_id;
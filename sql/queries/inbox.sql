@@ -0,0 +1,27 @@
+-- name: CreateNotification :exec
+INSERT INTO notifications (user_id, kind, title, body, data, event_id, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (user_id, event_id) DO NOTHING;
+
+-- name: ListNotifications :many
+-- Newest first. A before of 0 starts from the newest.
+SELECT id, kind, title, body, data, created_at, read_at
+FROM notifications
+WHERE user_id = @user_id AND (@before::bigint = 0 OR id < @before)
+ORDER BY id DESC
+LIMIT @max_notifications;
+
+-- name: CountUnreadNotifications :one
+SELECT COUNT(*)
+FROM notifications
+WHERE user_id = $1 AND read_at IS NULL;
+
+-- name: MarkNotificationsRead :execrows
+UPDATE notifications
+SET read_at = @read_at
+WHERE user_id = @user_id AND id = ANY(@ids::bigint[]) AND read_at IS NULL;
+
+-- name: MarkAllNotificationsRead :execrows
+UPDATE notifications
+SET read_at = @read_at
+WHERE user_id = @user_id AND read_at IS NULL;
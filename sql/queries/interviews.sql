@@ -0,0 +1,74 @@
+-- name: CreateInterview :one
+INSERT INTO interviews (application_id, starts_at, ends_at, location, video_url, created_by, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+RETURNING *;
+
+-- name: AddInterviewer :exec
+INSERT INTO interview_interviewers (interview_id, user_id)
+VALUES ($1, $2);
+
+-- name: RemoveInterviewers :exec
+DELETE FROM interview_interviewers
+WHERE interview_id = $1;
+
+-- name: ListInterviewers :many
+SELECT u.id, u.name, u.email
+FROM interview_interviewers ii
+JOIN users u ON u.id = ii.user_id
+WHERE ii.interview_id = $1
+ORDER BY u.id;
+
+-- name: GetInterview :one
+SELECT sqlc.embed(i), a.applicant_id, a.job_id
+FROM interviews i
+JOIN apply_jobs a ON a.id = i.application_id
+WHERE i.id = $1;
+
+-- name: ListInterviewsForApplication :many
+SELECT *
+FROM interviews
+WHERE application_id = $1
+ORDER BY starts_at, id;
+
+-- name: ListInterviewsForApplicant :many
+SELECT sqlc.embed(i), a.job_id
+FROM interviews i
+JOIN apply_jobs a ON a.id = i.application_id
+WHERE a.applicant_id = $1
+ORDER BY i.starts_at, i.id;
+
+-- name: FindInterviewConflicts :many
+-- Interviews that are not cancelled, overlap the slot and share one of
+-- the interviewers, other than the interview being moved.
+SELECT i.id, ii.user_id, i.starts_at, i.ends_at
+FROM interviews i
+JOIN interview_interviewers ii ON ii.interview_id = i.id
+WHERE ii.user_id = ANY(@interviewer_ids::int[])
+  AND i.status <> 'cancelled'
+  AND i.id <> @exclude_id
+  AND i.starts_at < @ends_at
+  AND i.ends_at > @starts_at
+ORDER BY ii.user_id, i.starts_at;
+
+-- name: RescheduleInterview :one
+-- Moves an interview, which the applicant then has to confirm again.
+UPDATE interviews
+SET starts_at = $2,
+    ends_at = $3,
+    location = $4,
+    video_url = $5,
+    status = 'scheduled',
+    reschedule_note = '',
+    sequence = sequence + 1,
+    updated_at = $6
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateInterviewStatus :one
+UPDATE interviews
+SET status = $2,
+    reschedule_note = $3,
+    sequence = sequence + 1,
+    updated_at = $4
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,61 @@
+-- name: GetNotificationRecipient :one
+SELECT u.id, u.name, u.email, u.user_type,
+       COALESCE(p.application_updates, TRUE)::boolean AS application_updates,
+       COALESCE(p.applicant_digest, TRUE)::boolean AS applicant_digest
+FROM users u
+LEFT JOIN notification_preferences p ON p.user_id = u.id
+WHERE u.id = $1;
+
+-- name: SetNotificationPreferences :exec
+INSERT INTO notification_preferences (user_id, application_updates, applicant_digest)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id) DO UPDATE
+SET application_updates = EXCLUDED.application_updates,
+    applicant_digest = EXCLUDED.applicant_digest;
+
+-- name: ListDigestRecipients :many
+-- Admins who want the digest and have not had one since due_before.
+SELECT u.id, u.name, u.email, p.digest_sent_at
+FROM users u
+LEFT JOIN notification_preferences p ON p.user_id = u.id
+WHERE u.user_type = 'admin'
+  AND COALESCE(p.applicant_digest, TRUE)
+  AND (p.digest_sent_at IS NULL OR p.digest_sent_at <= @due_before)
+ORDER BY u.id;
+
+-- name: ClaimDigest :execrows
+-- Marks the digest of a user as sent at digest_sent_at unless another
+-- process did since due_before.
+INSERT INTO notification_preferences (user_id, digest_sent_at)
+VALUES (@user_id, @digest_sent_at)
+ON CONFLICT (user_id) DO UPDATE
+SET digest_sent_at = EXCLUDED.digest_sent_at
+WHERE notification_preferences.digest_sent_at IS NULL
+   OR notification_preferences.digest_sent_at <= @due_before;
+
+-- name: SetDigestSentAt :exec
+INSERT INTO notification_preferences (user_id, digest_sent_at)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET digest_sent_at = EXCLUDED.digest_sent_at;
+
+-- name: ListApplicationsForPoster :many
+SELECT a.id, j.title AS job_title, u.name AS applicant_name,
+       u.email AS applicant_email, a.applied_at
+FROM apply_jobs a
+JOIN job j ON j.id = a.job_id
+JOIN users u ON u.id = a.applicant_id
+WHERE j.posted_by = @posted_by
+  AND a.applied_at > @since AND a.applied_at <= @until
+ORDER BY a.applied_at, a.id;
+
+-- name: EmailSent :one
+SELECT EXISTS (
+    SELECT 1 FROM sent_emails
+    WHERE user_id = $1 AND kind = $2 AND event_id = $3
+);
+
+-- name: RecordSentEmail :exec
+INSERT INTO sent_emails (user_id, kind, event_id, sent_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,39 @@
+-- name: CreateOutboxEvent :exec
+INSERT INTO outbox (event_id, event_type, payload, created_at, next_attempt_at)
+VALUES ($1, $2, $3, $4, $4);
+
+-- name: ClaimOutboxEvents :many
+-- Leases unpublished events until lease_until so concurrent dispatchers
+-- skip them.
+UPDATE outbox
+SET next_attempt_at = @lease_until
+WHERE id IN (
+    SELECT o.id FROM outbox o
+    WHERE o.published_at IS NULL AND o.next_attempt_at <= @now
+    ORDER BY o.id
+    LIMIT @max_events
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, event_id, event_type, payload, attempts, created_at;
+
+-- name: MarkOutboxPublished :exec
+UPDATE outbox
+SET published_at = $2,
+    attempts = attempts + 1,
+    last_error = NULL
+WHERE id = $1;
+
+-- name: RecordOutboxFailure :exec
+UPDATE outbox
+SET attempts = attempts + 1,
+    next_attempt_at = $2,
+    last_error = $3
+WHERE id = $1;
+
+-- name: DeletePublishedOutboxEvents :execrows
+DELETE FROM outbox
+WHERE published_at < $1;
+
+-- name: NotifyEvent :exec
+-- Delivered to listeners when the transaction commits, or not at all.
+SELECT pg_notify(@channel::text, @payload::text);
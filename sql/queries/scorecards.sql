@@ -0,0 +1,54 @@
+-- name: GetScorecardTemplate :one
+SELECT *
+FROM scorecard_templates
+WHERE job_id = $1;
+
+-- name: SetScorecardTemplate :one
+INSERT INTO scorecard_templates (job_id, criteria, updated_by, updated_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (job_id) DO UPDATE
+SET criteria = EXCLUDED.criteria,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = EXCLUDED.updated_at
+RETURNING *;
+
+-- name: SaveFeedback :one
+-- Creates or replaces the feedback of an interviewer, unless it was
+-- already submitted.
+INSERT INTO scorecard_feedback (application_id, interviewer_id, ratings, recommendation, notes, submitted_at, created_at, updated_at)
+VALUES (@application_id, @interviewer_id, @ratings, @recommendation, @notes, @submitted_at, @now, @now)
+ON CONFLICT (application_id, interviewer_id) DO UPDATE
+SET ratings = EXCLUDED.ratings,
+    recommendation = EXCLUDED.recommendation,
+    notes = EXCLUDED.notes,
+    submitted_at = EXCLUDED.submitted_at,
+    updated_at = EXCLUDED.updated_at
+WHERE scorecard_feedback.submitted_at IS NULL
+RETURNING *;
+
+-- name: ListFeedbackForApplication :many
+SELECT sqlc.embed(f), u.name AS interviewer_name
+FROM scorecard_feedback f
+JOIN users u ON u.id = f.interviewer_id
+WHERE f.application_id = $1
+ORDER BY f.submitted_at NULLS LAST, f.id;
+
+-- name: ListFeedbackForApplicant :many
+SELECT sqlc.embed(f), u.name AS interviewer_name
+FROM scorecard_feedback f
+JOIN users u ON u.id = f.interviewer_id
+JOIN apply_jobs a ON a.id = f.application_id
+WHERE a.applicant_id = $1
+ORDER BY f.application_id, f.submitted_at NULLS LAST, f.id;
+
+-- name: IsInterviewer :one
+-- Whether a user interviews for an application, in an interview that
+-- was not cancelled.
+SELECT EXISTS (
+    SELECT 1
+    FROM interviews i
+    JOIN interview_interviewers ii ON ii.interview_id = i.id
+    WHERE i.application_id = @application_id
+      AND ii.user_id = @user_id
+      AND i.status <> 'cancelled'
+)::boolean;
@@ -5,7 +5,21 @@ RETURNING id, name, email, user_type;
 
 -- name: GetUser :one
 SELECT id, password_hash, user_type FROM users
-WHERE email = $1;
+WHERE lower(email) = lower(@email);
+
+-- name: SetUserType :one
+UPDATE users
+SET user_type = @user_type
+WHERE lower(email) = lower(@email)
+RETURNING id, user_type;
+
+-- name: UpdatePasswordHash :execrows
+UPDATE users
+SET password_hash = @password_hash
+WHERE lower(email) = lower(@email);
+
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users;
 
 -- name: GetUserFromID :one
 SELECT user_type FROM users
@@ -16,9 +30,13 @@ INSERT INTO profile (applicant)
 VALUES ($1)
 RETURNING applicant;
 
+-- name: HasProfile :one
+SELECT EXISTS (SELECT 1 FROM profile WHERE applicant = $1);
+
 -- name: AddProfileIDInUser :exec
 UPDATE users
-SET profile_id = $1;
+SET profile_id = $1
+WHERE id = $2;
 
 -- name: UpdateProfile :one
 UPDATE profile
@@ -26,16 +44,30 @@ SET name = $1, email = $2, phone=$3, skills = $4, education = $5, resume_file_ad
 WHERE applicant = $7
 RETURNING name, email, phone, skills, education;
 
+-- name: GetResumeFileAddress :one
+SELECT resume_file_address FROM profile
+WHERE applicant = $1;
+
 -- name: CreateJob :one
 INSERT INTO job (title, description, posted_on, company_name, posted_by)
 VALUES ($1, $2, $3, $4, $5)
 RETURNING id, title, description, posted_on, company_name, posted_by;
 
 -- name: GetJob :one
-SELECT title, description, posted_on, company_name, posted_by, total_applications
+SELECT title, description, posted_on, company_name, posted_by, total_applications, closed_at
 FROM job
 WHERE id = $1;
 
+-- name: ListJobs :many
+SELECT id, title, company_name, posted_on, total_applications, closed_at
+FROM job
+ORDER BY id;
+
+-- name: CloseJob :execrows
+UPDATE job
+SET closed_at = $2
+WHERE id = $1 AND closed_at IS NULL;
+
 -- name: GetApplicants :many
 SELECT name, email, address, profile_headline
 FROM users
@@ -49,11 +81,30 @@ WHERE u.id = $1;
 
 -- name: GetJobsApplicant :many
 SELECT title, description, posted_on, total_applications, company_name, posted_by
-FROM job;
+FROM job
+WHERE closed_at IS NULL;
+
+-- name: ApplyJob :one
+INSERT INTO apply_jobs (applicant_id, job_id, applied_at)
+VALUES ($1, $2, $3)
+RETURNING id;
+
+-- name: GetApplication :one
+SELECT id, applicant_id, job_id, status, applied_at
+FROM apply_jobs
+WHERE id = $1;
+
+-- name: UpdateApplicationStatus :execrows
+UPDATE apply_jobs
+SET status = $2
+WHERE id = $1;
 
--- name: ApplyJob :exec
-INSERT INTO apply_jobs (applicant_id, job_id)
-VALUES ($1, $2);
+-- name: ListApplicationsForApplicant :many
+SELECT a.id, a.job_id, j.title, a.status, a.applied_at
+FROM apply_jobs a
+JOIN job j ON j.id = a.job_id
+WHERE a.applicant_id = $1
+ORDER BY a.id;
 
 -- name: UpdateTotalApplications :exec
 UPDATE job
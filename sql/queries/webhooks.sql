@@ -0,0 +1,69 @@
+-- name: CreateWebhook :one
+INSERT INTO webhook_subscriptions (url, secret, events, created_by, created_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, url, events, created_by, created_at;
+
+-- name: ListWebhooks :many
+SELECT id, url, events, created_by, created_at
+FROM webhook_subscriptions
+ORDER BY id;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: ListWebhooksForEvent :many
+SELECT id
+FROM webhook_subscriptions
+WHERE @event_type::text = ANY(events)
+ORDER BY id;
+
+-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, next_attempt_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $5);
+
+-- name: ClaimWebhookDeliveries :many
+-- Leases due deliveries until lease_until so concurrent dispatchers skip
+-- them, and returns what is needed to send them.
+UPDATE webhook_deliveries d
+SET next_attempt_at = @lease_until
+FROM webhook_subscriptions s
+WHERE s.id = d.subscription_id AND d.id IN (
+    SELECT w.id FROM webhook_deliveries w
+    WHERE w.status = 'pending' AND w.next_attempt_at <= @now
+    ORDER BY w.next_attempt_at
+    LIMIT @max_deliveries
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING d.id, d.event_id, d.event_type, d.payload, d.attempts, s.url, s.secret;
+
+-- name: RecordWebhookAttempt :exec
+UPDATE webhook_deliveries
+SET status = $2,
+    attempts = attempts + 1,
+    next_attempt_at = $3,
+    last_attempt_at = $4,
+    last_status_code = $5,
+    last_error = $6
+WHERE id = $1;
+
+-- name: ListWebhookDeliveries :many
+SELECT id, subscription_id, event_id, event_type, payload, status, attempts,
+       next_attempt_at, last_attempt_at, last_status_code, last_error, created_at
+FROM webhook_deliveries
+WHERE subscription_id = $1
+ORDER BY id DESC
+LIMIT $2;
+
+-- name: ListDeadWebhookDeliveries :many
+SELECT id, subscription_id, event_id, event_type, payload, status, attempts,
+       next_attempt_at, last_attempt_at, last_status_code, last_error, created_at
+FROM webhook_deliveries
+WHERE status = 'dead'
+ORDER BY id DESC
+LIMIT $1;
+
+-- name: RetryWebhookDelivery :execrows
+UPDATE webhook_deliveries
+SET status = 'pending', attempts = 0, next_attempt_at = $2
+WHERE id = $1 AND status = 'dead';
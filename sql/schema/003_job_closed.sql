@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE job ADD COLUMN closed_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE job DROP COLUMN closed_at;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TYPE application_status AS ENUM (
+    'applied', 'reviewing', 'interviewing', 'offered', 'hired', 'rejected'
+);
+
+ALTER TABLE apply_jobs
+    ADD COLUMN id SERIAL PRIMARY KEY,
+    ADD COLUMN status application_status NOT NULL DEFAULT 'applied',
+    ADD COLUMN applied_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+-- +goose Down
+ALTER TABLE apply_jobs
+    DROP COLUMN applied_at,
+    DROP COLUMN status,
+    DROP COLUMN id;
+DROP TYPE application_status;
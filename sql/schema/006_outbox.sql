@@ -0,0 +1,21 @@
+-- +goose Up
+-- Domain events, written in the same transaction as the change they
+-- describe and published to the configured sinks afterwards. Rows that
+-- are not yet published are retried once next_attempt_at has passed.
+CREATE TABLE outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id TEXT NOT NULL UNIQUE,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    published_at TIMESTAMP,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    last_error TEXT
+);
+
+CREATE INDEX outbox_unpublished ON outbox (next_attempt_at)
+    WHERE published_at IS NULL;
+
+-- +goose Down
+DROP TABLE outbox;
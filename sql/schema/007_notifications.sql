@@ -0,0 +1,21 @@
+-- +goose Up
+-- Users without a row get every email.
+CREATE TABLE notification_preferences (
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    application_updates BOOLEAN NOT NULL DEFAULT TRUE,
+    applicant_digest BOOLEAN NOT NULL DEFAULT TRUE,
+    digest_sent_at TIMESTAMP
+);
+
+-- Emails sent for an event, so a republished event is not mailed twice.
+CREATE TABLE sent_emails (
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    event_id TEXT NOT NULL,
+    sent_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, kind, event_id)
+);
+
+-- +goose Down
+DROP TABLE sent_emails;
+DROP TABLE notification_preferences;
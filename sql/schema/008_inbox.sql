@@ -0,0 +1,21 @@
+-- +goose Up
+-- The in-app inbox. event_id is the outbox event a notification was made
+-- from, so a republished event does not notify twice.
+CREATE TABLE notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    data JSONB NOT NULL,
+    event_id TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    read_at TIMESTAMP,
+    UNIQUE (user_id, event_id)
+);
+
+CREATE INDEX notifications_unread ON notifications (user_id)
+    WHERE read_at IS NULL;
+
+-- +goose Down
+DROP TABLE notifications;
@@ -0,0 +1,36 @@
+-- +goose Up
+CREATE TYPE interview_status AS ENUM (
+    'scheduled', 'confirmed', 'reschedule_requested', 'cancelled'
+);
+
+-- sequence counts the changes to an interview, which calendar clients
+-- need to update an invite they already have.
+CREATE TABLE interviews (
+    id SERIAL PRIMARY KEY,
+    application_id INT NOT NULL REFERENCES apply_jobs(id) ON DELETE CASCADE,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    location TEXT NOT NULL,
+    video_url TEXT NOT NULL,
+    status interview_status NOT NULL DEFAULT 'scheduled',
+    reschedule_note TEXT NOT NULL DEFAULT '',
+    sequence INT NOT NULL DEFAULT 0,
+    created_by INT NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX interviews_application ON interviews (application_id);
+
+CREATE TABLE interview_interviewers (
+    interview_id INT NOT NULL REFERENCES interviews(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (interview_id, user_id)
+);
+
+CREATE INDEX interview_interviewers_user ON interview_interviewers (user_id);
+
+-- +goose Down
+DROP TABLE interview_interviewers;
+DROP TABLE interviews;
+DROP TYPE interview_status;
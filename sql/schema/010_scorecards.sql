@@ -0,0 +1,33 @@
+-- +goose Up
+-- criteria is the list of what interviewers rate a job's candidates on,
+-- each with a name and a scale of 1 to its scale.
+CREATE TABLE scorecard_templates (
+    job_id INT PRIMARY KEY REFERENCES job(id) ON DELETE CASCADE,
+    criteria JSONB NOT NULL,
+    updated_by INT NOT NULL REFERENCES users(id),
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TYPE recommendation AS ENUM ('strong_no', 'no', 'yes', 'strong_yes');
+
+-- One scorecard per interviewer and application. ratings keeps the name
+-- and scale of each criterion rated, so it outlives changes to the
+-- template. Feedback is a draft until submitted_at is set, and cannot
+-- change after.
+CREATE TABLE scorecard_feedback (
+    id SERIAL PRIMARY KEY,
+    application_id INT NOT NULL REFERENCES apply_jobs(id) ON DELETE CASCADE,
+    interviewer_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ratings JSONB NOT NULL,
+    recommendation recommendation,
+    notes TEXT NOT NULL,
+    submitted_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    UNIQUE (application_id, interviewer_id)
+);
+
+-- +goose Down
+DROP TABLE scorecard_feedback;
+DROP TYPE recommendation;
+DROP TABLE scorecard_templates;
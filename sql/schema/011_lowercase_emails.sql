@@ -0,0 +1,22 @@
+-- +goose Up
+-- Emails are looked up lowercased, so store them that way and keep case
+-- variants of one address from being registered twice. Accounts whose
+-- emails differ only in case have to be merged by hand first.
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM users GROUP BY lower(email) HAVING count(*) > 1) THEN
+        RAISE EXCEPTION 'users have emails that differ only in case, merge them before migrating';
+    END IF;
+END
+$$;
+-- +goose StatementEnd
+
+UPDATE users SET email = lower(email) WHERE email <> lower(email);
+
+ALTER TABLE users DROP CONSTRAINT users_email_key;
+CREATE UNIQUE INDEX users_email_lower_key ON users (lower(email));
+
+-- +goose Down
+DROP INDEX users_email_lower_key;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
@@ -0,0 +1,17 @@
+-- +goose Up
+-- AddProfileIDInUser used to update every user, so each signup pointed
+-- every account, admins included, at the newest profile. A profile is
+-- keyed by its applicant, so point each user back at their own and clear
+-- it for users without one.
+UPDATE users u
+SET profile_id = p.applicant
+FROM profile p
+WHERE p.applicant = u.id AND u.profile_id IS DISTINCT FROM p.applicant;
+
+UPDATE users u
+SET profile_id = NULL
+WHERE u.profile_id IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM profile p WHERE p.applicant = u.id);
+
+-- +goose Down
+-- The damaged values are not worth restoring.
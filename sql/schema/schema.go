@@ -0,0 +1,43 @@
+// Package schema embeds the goose migrations so the binary can check and
+// apply them without the source tree.
+package schema
+
+import (
+	"embed"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+// LatestVersion returns the highest migration version embedded in FS.
+func LatestVersion() (int64, error) {
+	return LatestVersionIn(FS)
+}
+
+// LatestVersionIn returns the highest migration version in fsys, or 0
+// when it holds no migrations.
+func LatestVersionIn(fsys fs.FS) (int64, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int64
+	for _, e := range entries {
+		prefix, _, ok := strings.Cut(e.Name(), "_")
+		if !ok || !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+		v, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		if v > latest {
+			latest = v
+		}
+	}
+	return latest, nil
+}
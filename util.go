@@ -2,26 +2,61 @@ package main
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 )
 
-type errResponse struct {
-	Error string `json:"error"`
+const problemTypePrefix = "urn:synlabs:problem:"
+
+// problem is an RFC 7807 problem details document extended with a stable
+// error code, the request ID and per-field validation errors.
+type problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      errorCode    `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []fieldError `json:"errors,omitempty"`
 }
 
-func respondWithError(w http.ResponseWriter, msg string, code int) {
-	er := errResponse{
-		Error: msg,
+func respondWithProblem(w http.ResponseWriter, r *http.Request, e *apiError) {
+	p := problem{
+		Type:     problemTypePrefix + string(e.Code),
+		Title:    errorCodes[e.Code].title,
+		Status:   e.Status(),
+		Detail:   e.Msg,
+		Instance: r.URL.Path,
+		Code:     e.Code,
+		Errors:   e.Fields,
+	}
+	if st := logStateFrom(r.Context()); st != nil {
+		p.RequestID = st.requestID
 	}
-	errResp, err := json.Marshal(er)
+
+	resp, err := json.Marshal(p)
 	if err != nil {
-		log.Fatalf("error encoding JSON: %s", err)
+		slog.Error("error encoding JSON", "error", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	w.Write(resp)
+}
+
+// respondWithJSON encodes payload before touching w, so an encoding
+// failure can still be reported as an error response.
+func respondWithJSON(w http.ResponseWriter, payload interface{}, code int) error {
+	resp, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
-	w.Write(errResp)
+	w.Write(resp)
+	return nil
 }
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/mail"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const maxBodyBytes = 1 << 20
+
+// payload is a request body that can check itself. validate may also
+// normalize fields in place (trimming, lowercasing emails) before the
+// handler sees them.
+type payload interface {
+	validate(v *validator)
+}
+
+// decodeJSON decodes the request body into dst and validates it. Unknown
+// fields, type mismatches and rule violations are all collected and
+// returned together as one validation error.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst payload) error {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		maxErr := &http.MaxBytesError{}
+		if errors.As(err, &maxErr) {
+			return newAPIError(codeMalformedRequest, "Request body is too large", err)
+		}
+		return newAPIError(codeMalformedRequest, "Request body could not be read", err)
+	}
+
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return newAPIError(codeMalformedRequest, "Request body is not a JSON object", err)
+	}
+
+	v := &validator{}
+	known := jsonFields(dst)
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !known[key] {
+			v.add(key, "unknown_field", "Unknown field")
+		}
+	}
+
+	if err := json.Unmarshal(body, dst); err != nil {
+		typeErr := &json.UnmarshalTypeError{}
+		if !errors.As(err, &typeErr) {
+			return newAPIError(codeMalformedRequest, "Request body is not valid JSON", err)
+		}
+		v.add(typeErr.Field, "invalid_type", "Must be a "+typeErr.Type.Kind().String())
+	}
+
+	dst.validate(v)
+	return v.err()
+}
+
+// jsonFields returns the JSON names of the exported fields of the struct
+// v points to.
+func jsonFields(v interface{}) map[string]bool {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	fields := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = true
+	}
+	return fields
+}
+
+// validator collects field errors, keeping only the first one reported
+// for each field.
+type validator struct {
+	errs []fieldError
+}
+
+func (v *validator) add(field, code, msg string) {
+	for _, e := range v.errs {
+		if e.Field == field {
+			return
+		}
+	}
+	v.errs = append(v.errs, fieldError{Field: field, Code: code, Message: msg})
+}
+
+func (v *validator) check(ok bool, field, code, msg string) {
+	if !ok {
+		v.add(field, code, msg)
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return newValidationError(v.errs...)
+}
+
+func (v *validator) required(field, value string) {
+	v.check(value != "", field, "required", "Is required")
+}
+
+func (v *validator) maxLen(field, value string, n int) {
+	v.check(len([]rune(value)) <= n, field, "too_long", "Must be at most "+strconv.Itoa(n)+" characters")
+}
+
+func (v *validator) oneOf(field, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.add(field, "invalid_choice", "Must be one of: "+strings.Join(allowed, ", "))
+}
+
+func (v *validator) email(field, value string) {
+	if value == "" {
+		return
+	}
+	addr, err := mail.ParseAddress(value)
+	ok := err == nil && addr.Address == value && addr.Name == ""
+	if ok {
+		_, domain, _ := strings.Cut(value, "@")
+		ok = strings.Contains(domain, ".") && !strings.HasSuffix(domain, ".")
+	}
+	v.check(ok, field, "invalid_format", "Must be a valid email address")
+}
+
+// password enforces the password policy: 8 to 72 bytes (bcrypt ignores
+// anything longer), with at least one letter and one digit.
+func (v *validator) password(field, value string) {
+	if value == "" {
+		return
+	}
+	if len(value) < 8 {
+		v.add(field, "weak_password", "Must be at least 8 characters")
+		return
+	}
+	if len(value) > 72 {
+		v.add(field, "too_long", "Must be at most 72 bytes")
+		return
+	}
+
+	var letter, digit bool
+	for _, c := range value {
+		switch {
+		case unicode.IsLetter(c):
+			letter = true
+		case unicode.IsDigit(c):
+			digit = true
+		}
+	}
+	v.check(letter && digit, field, "weak_password", "Must contain at least one letter and one digit")
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
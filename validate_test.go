@@ -0,0 +1,165 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func decodeTestPayload(t *testing.T, body string, dst payload) *apiError {
+	t.Helper()
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	err := decodeJSON(httptest.NewRecorder(), r, dst)
+	if err == nil {
+		return nil
+	}
+	apiErr, ok := err.(*apiError)
+	if !ok {
+		t.Fatalf("decodeJSON returned %T, want *apiError", err)
+	}
+	return apiErr
+}
+
+func fieldCodes(e *apiError) map[string]string {
+	codes := map[string]string{}
+	for _, f := range e.Fields {
+		codes[f.Field] = f.Code
+	}
+	return codes
+}
+
+func TestSignupValidationReportsAllFields(t *testing.T) {
+	p := signupPayload{}
+	apiErr := decodeTestPayload(t, `{
+		"name": "  ",
+		"email": "not-an-email",
+		"password": "short",
+		"user_type": "root",
+		"nickname": "x",
+		"role": "admin"
+	}`, &p)
+	if apiErr == nil {
+		t.Fatal("expected validation error")
+	}
+	if apiErr.Status() != http.StatusUnprocessableEntity || apiErr.Code != codeValidationFailed {
+		t.Fatalf("error = %+v", apiErr)
+	}
+
+	want := map[string]string{
+		"name":      "required",
+		"email":     "invalid_format",
+		"password":  "weak_password",
+		"user_type": "invalid_choice",
+		"nickname":  "unknown_field",
+		"role":      "unknown_field",
+	}
+	got := fieldCodes(apiErr)
+	for field, code := range want {
+		if got[field] != code {
+			t.Errorf("field %s: code = %q, want %q", field, got[field], code)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d field errors, want %d: %+v", len(got), len(want), apiErr.Fields)
+	}
+}
+
+func TestSignupNormalizesEmail(t *testing.T) {
+	p := signupPayload{}
+	apiErr := decodeTestPayload(t, `{
+		"name": " Ada ",
+		"email": "  Ada.Lovelace@Example.COM ",
+		"password": "analytical1"
+	}`, &p)
+	if apiErr != nil {
+		t.Fatalf("unexpected error: %+v", apiErr.Fields)
+	}
+	if p.Email != "ada.lovelace@example.com" || p.Name != "Ada" {
+		t.Fatalf("payload not normalized: %+v", p)
+	}
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	tests := []struct {
+		password string
+		code     string
+	}{
+		{"", "required"},
+		{"abc123", "weak_password"},
+		{"abcdefghij", "weak_password"},
+		{"1234567890", "weak_password"},
+		{strings.Repeat("a1", 40), "too_long"},
+		{"a@example.com", "weak_password"},
+		{"correct horse 9", ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.password, func(t *testing.T) {
+			p := signupPayload{}
+			body := `{"name":"A","email":"a@example.com","password":"` + tc.password + `"}`
+			apiErr := decodeTestPayload(t, body, &p)
+
+			got := ""
+			if apiErr != nil {
+				got = fieldCodes(apiErr)["password"]
+			}
+			if got != tc.code {
+				t.Fatalf("password code = %q, want %q", got, tc.code)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONRejectsBadBodies(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		code  errorCode
+		field string
+	}{
+		{"not JSON", `{"title":`, codeMalformedRequest, ""},
+		{"array body", `[]`, codeMalformedRequest, ""},
+		{"wrong type", `{"title": 5, "description": "d", "company_name": "c"}`, codeValidationFailed, "title"},
+		{"missing fields", `{}`, codeValidationFailed, "company_name"},
+		{"too large", `{"title":"` + strings.Repeat("a", maxBodyBytes) + `"}`, codeMalformedRequest, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := addJobPayload{}
+			apiErr := decodeTestPayload(t, tc.body, &p)
+			if apiErr == nil || apiErr.Code != tc.code {
+				t.Fatalf("error = %+v, want code %q", apiErr, tc.code)
+			}
+			if tc.field != "" {
+				if _, ok := fieldCodes(apiErr)[tc.field]; !ok {
+					t.Fatalf("no error for field %s: %+v", tc.field, apiErr.Fields)
+				}
+			}
+		})
+	}
+}
+
+func TestUploadResumeValidation(t *testing.T) {
+	tests := []struct {
+		file string
+		ok   bool
+	}{
+		{"/tmp/cv.pdf", true},
+		{"/tmp/CV.DOCX", true},
+		{"/tmp/cv.txt", false},
+		{"/tmp/notapdf", false},
+		{"", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.file, func(t *testing.T) {
+			p := uploadResumePayload{}
+			apiErr := decodeTestPayload(t, `{"file_address":"`+tc.file+`"}`, &p)
+			if (apiErr == nil) != tc.ok {
+				t.Fatalf("ok = %v, want %v (%+v)", apiErr == nil, tc.ok, apiErr)
+			}
+		})
+	}
+}